@@ -0,0 +1,65 @@
+import { Prop, Schema, SchemaFactory } from '@nestjs/mongoose';
+import { Document } from 'mongoose';
+
+export type AirQualityLogDocument = AirQualityLog & Document;
+
+// Índice calculado pelo worker: valor, categoria, poluente dominante e o
+// subíndice de cada poluente
+export interface AirQualityIndex {
+  standard: string; // "epa" ou "conama"
+  index: number;
+  category: string;
+  dominantPollutant: string;
+  subIndexes: {
+    pollutant: string;
+    concentration: number;
+    unit: string;
+    index: number;
+    category: string;
+  }[];
+}
+
+@Schema({ timestamps: true, collection: 'airqualitylogs' })
+export class AirQualityLog {
+  @Prop({ required: true })
+  location: string;
+
+  @Prop({ default: Date.now })
+  timestamp: Date; // Instante da medição
+
+  @Prop()
+  latitude: number;
+
+  @Prop()
+  longitude: number;
+
+  @Prop({ default: 'go-worker' })
+  source: string;
+
+  // Concentrações em µg/m³
+  @Prop()
+  pm25: number;
+
+  @Prop()
+  pm10: number;
+
+  @Prop()
+  ozone: number;
+
+  @Prop()
+  nitrogenDioxide: number;
+
+  @Prop()
+  sulphurDioxide: number;
+
+  @Prop()
+  carbonMonoxide: number;
+
+  @Prop({ type: Object })
+  usAqi: AirQualityIndex; // AQI da EPA (EUA)
+
+  @Prop({ type: Object })
+  iqar: AirQualityIndex; // IQAr da Resolução CONAMA 491/2018
+}
+
+export const AirQualityLogSchema = SchemaFactory.createForClass(AirQualityLog);
@@ -0,0 +1,56 @@
+import { IsString, IsNumber, IsOptional, IsDateString, IsObject } from 'class-validator';
+
+export class CreateAirQualityLogDto {
+  @IsOptional()
+  @IsDateString()
+  timestamp?: string;
+
+  @IsString()
+  location: string;
+
+  @IsOptional()
+  @IsNumber()
+  latitude?: number;
+
+  @IsOptional()
+  @IsNumber()
+  longitude?: number;
+
+  @IsOptional()
+  @IsString()
+  source?: string;
+
+  // Concentrações em µg/m³; poluentes não medidos são omitidos
+  @IsOptional()
+  @IsNumber()
+  pm25?: number;
+
+  @IsOptional()
+  @IsNumber()
+  pm10?: number;
+
+  @IsOptional()
+  @IsNumber()
+  ozone?: number;
+
+  @IsOptional()
+  @IsNumber()
+  nitrogenDioxide?: number;
+
+  @IsOptional()
+  @IsNumber()
+  sulphurDioxide?: number;
+
+  @IsOptional()
+  @IsNumber()
+  carbonMonoxide?: number;
+
+  // Índices calculados pelo worker (EPA e CONAMA 491/2018)
+  @IsOptional()
+  @IsObject()
+  usAqi?: Record<string, unknown>;
+
+  @IsOptional()
+  @IsObject()
+  iqar?: Record<string, unknown>;
+}
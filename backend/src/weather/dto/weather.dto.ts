@@ -1,7 +1,12 @@
-import { IsString, IsNumber, IsOptional, IsDateString } from 'class-validator';
+import { IsString, IsNumber, IsOptional, IsDateString, IsArray, IsIn, IsObject } from 'class-validator';
 import { Transform } from 'class-transformer';
 
 export class CreateWeatherLogDto {
+  // Instante da observação (UTC); ausente, o schema registra o horário da ingestão
+  @IsOptional()
+  @IsDateString()
+  timestamp?: string;
+
   @IsString()
   location: string;
 
@@ -11,13 +16,18 @@ export class CreateWeatherLogDto {
   @IsNumber()
   humidity: number;
 
+  @IsOptional()
   @IsNumber()
-  pressure: number;
+  pressure?: number;
 
   @IsOptional()
   @IsString()
   description?: string;
 
+  @IsOptional()
+  @IsString()
+  icon?: string;
+
   @IsOptional()
   @IsNumber()
   windSpeed?: number;
@@ -37,6 +47,108 @@ export class CreateWeatherLogDto {
   @IsOptional()
   @IsString()
   source?: string;
+
+  @IsOptional()
+  @IsArray()
+  @IsString({ each: true })
+  regions?: string[];
+
+  // Regiões de regions com as propriedades GeoJSON configuradas no worker
+  // ({ name: "Planta 3", properties: { id: "PL-03" } })
+  @IsOptional()
+  @IsArray()
+  @IsObject({ each: true })
+  regionDetails?: { name: string; properties?: Record<string, unknown> }[];
+
+  @IsOptional()
+  @IsNumber()
+  dewPoint?: number;
+
+  @IsOptional()
+  @IsNumber()
+  heatIndex?: number;
+
+  @IsOptional()
+  @IsNumber()
+  windChill?: number;
+
+  @IsOptional()
+  @IsNumber()
+  apparentTemperature?: number;
+
+  @IsOptional()
+  @IsNumber()
+  absoluteHumidity?: number;
+
+  @IsOptional()
+  @IsNumber()
+  windDirectionDegrees?: number;
+
+  @IsOptional()
+  @IsNumber()
+  windGust?: number;
+
+  @IsOptional()
+  @IsNumber()
+  precipitation?: number;
+
+  @IsOptional()
+  @IsNumber()
+  cloudCover?: number;
+
+  @IsOptional()
+  @IsNumber()
+  stationPressure?: number;
+
+  @IsOptional()
+  @IsNumber()
+  elevation?: number;
+
+  @IsOptional()
+  @IsString()
+  elevationSource?: string;
+
+  // Regras de qualidade do worker marcadas (flag) ou aplicadas (":clamped")
+  // e testes temporais reprovados ("qc:step:temperature")
+  @IsOptional()
+  @IsArray()
+  @IsString({ each: true })
+  qualityFlags?: string[];
+
+  // Fração dos testes temporais aprovados (0 a 1)
+  @IsOptional()
+  @IsNumber()
+  qualityScore?: number;
+
+  // Observação ou previsão; previsões chegam por POST /weather/forecasts
+  @IsOptional()
+  @IsIn(['observation', 'forecast'])
+  kind?: string;
+
+  // Emissão da previsão e resolução da série (hourly, daily)
+  @IsOptional()
+  @IsDateString()
+  issuedAt?: string;
+
+  @IsOptional()
+  @IsIn(['hourly', 'daily'])
+  period?: string;
+
+  // Extremos previstos para o dia
+  @IsOptional()
+  @IsNumber()
+  temperatureMax?: number;
+
+  @IsOptional()
+  @IsNumber()
+  temperatureMin?: number;
+
+  // Defeitos do payload corrigidos pela normalização tolerante do worker
+  // ("decimal-comma:current.temperature")
+  @IsOptional()
+  @IsArray()
+  @IsString({ each: true })
+  repairs?: string[];
 }
 
 export class WeatherQueryDto {
@@ -69,6 +181,12 @@ export class WeatherInsightDto {
   minTemperature: number;
   averageHumidity: number;
   averagePressure: number;
+  // Média vetorial do vento; ausente quando nenhum registro tem direção
+  averageWind?: {
+    speed: number;
+    vectorSpeed: number;
+    directionDegrees?: number;
+  };
   totalRecords: number;
   dateRange: {
     start: Date;
@@ -14,12 +14,15 @@ export class WeatherLog {
   @Prop({ required: true })
   humidity: number;
 
-  @Prop({ required: true })
-  pressure: number;
+  @Prop()
+  pressure: number; // Ausente quando o provedor não informa
 
   @Prop()
   description: string;
 
+  @Prop()
+  icon: string; // Ex: "clear-day", "rain", "thunderstorm-hail"
+
   @Prop()
   windSpeed: number;
 
@@ -37,6 +40,77 @@ export class WeatherLog {
 
   @Prop()
   source: string; // Ex: "worker-go", "manual", etc.
+
+  @Prop({ type: [String], default: undefined })
+  regions: string[]; // Regiões definidas no worker (fazendas, plantas, bairros)
+
+  @Prop({ type: [Object], default: undefined })
+  regionDetails: { name: string; properties?: Record<string, unknown> }[]; // Regiões com as propriedades repassadas pelo worker
+
+  // Métricas de conforto calculadas pelo worker (ausentes fora da faixa de validade)
+  @Prop()
+  dewPoint: number;
+
+  @Prop()
+  heatIndex: number;
+
+  @Prop()
+  windChill: number;
+
+  @Prop()
+  apparentTemperature: number;
+
+  @Prop()
+  absoluteHumidity: number;
+
+  @Prop()
+  windDirectionDegrees: number;
+
+  @Prop()
+  windGust: number;
+
+  @Prop()
+  precipitation: number;
+
+  @Prop()
+  cloudCover: number;
+
+  @Prop()
+  stationPressure: number;
+
+  @Prop()
+  elevation: number;
+
+  @Prop()
+  elevationSource: string;
+
+  @Prop({ type: [String], default: undefined })
+  qualityFlags: string[]; // Regras de qualidade do worker (ex.: "umidade-saturada:clamped")
+
+  @Prop()
+  qualityScore: number; // Fração dos testes temporais aprovados (0 a 1)
+
+  @Prop()
+  kind: string; // "observation" ou "forecast"
+
+  @Prop()
+  issuedAt: Date; // Emissão da previsão
+
+  @Prop()
+  period: string; // Resolução da previsão: "hourly" ou "daily"
+
+  @Prop()
+  temperatureMax: number;
+
+  @Prop()
+  temperatureMin: number;
+
+  @Prop({ type: [String], default: undefined })
+  repairs: string[]; // Correções da normalização do worker (ex.: "humidity-fraction:current.humidity")
 }
 
 export const WeatherLogSchema = SchemaFactory.createForClass(WeatherLog);
+
+// Previsões usam o mesmo schema em uma coleção própria, para que nunca se
+// misturem às observações nas consultas, exportações e insights
+export const WEATHER_FORECAST = 'WeatherForecast';
@@ -2,6 +2,7 @@ import {Body, Controller, Get, Post, Query, Res, UseGuards,} from '@nestjs/commo
 import {Response} from 'express';
 import {WeatherService} from './weather.service';
 import {CreateWeatherLogDto, WeatherQueryDto} from './dto/weather.dto';
+import {CreateAirQualityLogDto} from './dto/air-quality.dto';
 import {JwtAuthGuard} from '../auth/jwt-auth.guard';
 
 @Controller('weather')
@@ -13,6 +14,16 @@ export class WeatherController {
     return this.weatherService.create(createWeatherLogDto);
   }
 
+  @Post('forecasts')
+  createForecast(@Body() createWeatherLogDto: CreateWeatherLogDto) {
+    return this.weatherService.createForecast(createWeatherLogDto);
+  }
+
+  @Post('air-quality')
+  createAirQuality(@Body() createAirQualityLogDto: CreateAirQualityLogDto) {
+    return this.weatherService.createAirQuality(createAirQualityLogDto);
+  }
+
   @Get('logs')
   @UseGuards(JwtAuthGuard)
   findAll(@Query() query: WeatherQueryDto) {
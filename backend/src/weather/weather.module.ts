@@ -2,11 +2,16 @@ import { Module } from '@nestjs/common';
 import { MongooseModule } from '@nestjs/mongoose';
 import { WeatherService } from './weather.service';
 import { WeatherController } from './weather.controller';
-import { WeatherLog, WeatherLogSchema } from './weather-log.schema';
+import { WEATHER_FORECAST, WeatherLog, WeatherLogSchema } from './weather-log.schema';
+import { AirQualityLog, AirQualityLogSchema } from './air-quality-log.schema';
 
 @Module({
   imports: [
-    MongooseModule.forFeature([{ name: WeatherLog.name, schema: WeatherLogSchema }])
+    MongooseModule.forFeature([
+      { name: WeatherLog.name, schema: WeatherLogSchema },
+      { name: WEATHER_FORECAST, schema: WeatherLogSchema, collection: 'weatherforecasts' },
+      { name: AirQualityLog.name, schema: AirQualityLogSchema },
+    ])
   ],
   controllers: [WeatherController],
   providers: [WeatherService],
@@ -2,13 +2,17 @@ import { Injectable } from '@nestjs/common';
 import { InjectModel } from '@nestjs/mongoose';
 import { Model } from 'mongoose';
 import * as XLSX from 'xlsx';
-import { WeatherLog, WeatherLogDocument } from './weather-log.schema';
+import { WEATHER_FORECAST, WeatherLog, WeatherLogDocument } from './weather-log.schema';
 import { CreateWeatherLogDto, WeatherQueryDto, WeatherInsightDto } from './dto/weather.dto';
+import { AirQualityLog, AirQualityLogDocument } from './air-quality-log.schema';
+import { CreateAirQualityLogDto } from './dto/air-quality.dto';
 
 @Injectable()
 export class WeatherService {
   constructor(
     @InjectModel(WeatherLog.name) private weatherLogModel: Model<WeatherLogDocument>,
+    @InjectModel(WEATHER_FORECAST) private weatherForecastModel: Model<WeatherLogDocument>,
+    @InjectModel(AirQualityLog.name) private airQualityLogModel: Model<AirQualityLogDocument>,
   ) {}
 
   async create(createWeatherLogDto: CreateWeatherLogDto): Promise<WeatherLog> {
@@ -16,6 +20,19 @@ export class WeatherService {
     return createdLog.save();
   }
 
+  // Uma nova emissão substitui a previsão anterior para o mesmo local e horário
+  async createForecast(createWeatherLogDto: CreateWeatherLogDto): Promise<WeatherLog> {
+    const { location, timestamp, period } = createWeatherLogDto;
+    return this.weatherForecastModel
+      .findOneAndUpdate({ location, timestamp, period }, createWeatherLogDto, { upsert: true, new: true })
+      .exec();
+  }
+
+  async createAirQuality(createAirQualityLogDto: CreateAirQualityLogDto): Promise<AirQualityLog> {
+    const createdLog = new this.airQualityLogModel(createAirQualityLogDto);
+    return createdLog.save();
+  }
+
   async findAll(query: WeatherQueryDto): Promise<{
     data: WeatherLog[];
     total: number;
@@ -119,13 +136,20 @@ export class WeatherService {
 
     const temperatures = logs.map(log => log.temperature);
     const humidities = logs.map(log => log.humidity);
-    const pressures = logs.map(log => log.pressure);
+    // Registros sem pressão (não informada pelo provedor) ficam fora da média
+    const pressures = logs
+      .map(log => log.pressure)
+      .filter(pressure => typeof pressure === 'number');
 
     const avgTemp = temperatures.reduce((a, b) => a + b, 0) / temperatures.length;
     const maxTemp = Math.max(...temperatures);
     const minTemp = Math.min(...temperatures);
     const avgHumidity = humidities.reduce((a, b) => a + b, 0) / humidities.length;
-    const avgPressure = pressures.reduce((a, b) => a + b, 0) / pressures.length;
+    const avgPressure = pressures.length
+      ? pressures.reduce((a, b) => a + b, 0) / pressures.length
+      : 0;
+
+    const averageWind = this.averageWind(logs);
 
     // Análise de tendência de temperatura (últimos 10 registros vs. 10 anteriores)
     let temperatureTrend: 'rising' | 'falling' | 'stable' = 'stable';
@@ -172,6 +196,7 @@ export class WeatherService {
       minTemperature: minTemp,
       averageHumidity: Math.round(avgHumidity * 100) / 100,
       averagePressure: Math.round(avgPressure * 100) / 100,
+      averageWind,
       totalRecords: logs.length,
       dateRange: {
         start: logs[logs.length - 1].timestamp,
@@ -181,4 +206,38 @@ export class WeatherService {
       recommendations,
     };
   }
+
+  // Média vetorial do vento: cada registro é decomposto nas componentes u/v.
+  // A média aritmética de graus erra perto do norte (350° e 10° dariam 180°).
+  private averageWind(logs: WeatherLog[]): WeatherInsightDto['averageWind'] {
+    const samples = logs.filter(
+      log => typeof log.windSpeed === 'number' && typeof log.windDirectionDegrees === 'number',
+    );
+    if (samples.length === 0) {
+      return undefined;
+    }
+
+    let u = 0;
+    let v = 0;
+    let speed = 0;
+    for (const log of samples) {
+      const rad = (log.windDirectionDegrees * Math.PI) / 180;
+      // A direção é de onde o vento vem; o vetor aponta para onde ele vai
+      u += -log.windSpeed * Math.sin(rad);
+      v += -log.windSpeed * Math.cos(rad);
+      speed += log.windSpeed;
+    }
+    u /= samples.length;
+    v /= samples.length;
+
+    const vectorSpeed = Math.hypot(u, v);
+    const round = (value: number) => Math.round(value * 10) / 10;
+    return {
+      speed: round(speed / samples.length),
+      vectorSpeed: round(vectorSpeed),
+      // Ventos que se anulam não têm direção predominante
+      directionDegrees:
+        vectorSpeed < 0.1 ? undefined : round(((Math.atan2(-u, -v) * 180) / Math.PI + 360) % 360),
+    };
+  }
 }
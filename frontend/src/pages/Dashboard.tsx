@@ -211,7 +211,7 @@ const Dashboard: React.FC = () => {
               <div>
                 <p className="text-sm font-medium text-gray-600">Vento</p>
                 <p className="text-3xl font-bold text-gray-900">
-                  {currentWeather.windSpeed || 0} km/h
+                  {currentWeather.windSpeed || 0} km/h {currentWeather.windDirection}
                 </p>
               </div>
               <Wind className="h-12 w-12 text-gray-500" />
@@ -223,7 +223,7 @@ const Dashboard: React.FC = () => {
               <div>
                 <p className="text-sm font-medium text-gray-600">Pressão</p>
                 <p className="text-3xl font-bold text-gray-900">
-                  {currentWeather.pressure} hPa
+                  {currentWeather.pressure != null ? `${currentWeather.pressure} hPa` : '—'}
                 </p>
                 <p className="text-xs text-gray-500 flex items-center mt-1">
                   <MapPin className="h-3 w-3 mr-1" />
@@ -3,7 +3,7 @@ export interface WeatherData {
   location: string;
   temperature: number;
   humidity: number;
-  pressure: number;
+  pressure?: number;
   description?: string;
   windSpeed?: number;
   windDirection?: string;
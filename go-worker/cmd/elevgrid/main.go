@@ -0,0 +1,75 @@
+// Comando elevgrid gera a grade de altitude lida pelo worker em
+// ELEVATION_GRID_FILE a partir de um modelo digital de elevação em ESRI ASCII
+// Grid. Exemplo, com o ETOPO 2022 de 60" (NOAA NCEI) convertido
+// pelo GDAL:
+//
+//	gdal_translate -of AAIGrid -projwin -74 6 -28 -34 etopo_2022_60s_surface.tif etopo.asc
+//	go run ./cmd/elevgrid -in etopo.asc -source "ETOPO 2022 v1, 60 arc-segundos, superfície (NOAA NCEI)"
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go-worker/internal/geo"
+)
+
+func main() {
+	in := flag.String("in", "", "modelo de elevação em ESRI ASCII Grid (obrigatório)")
+	out := flag.String("out", "elevacao.csv", "arquivo CSV gerado")
+	source := flag.String("source", "", "descrição do modelo de elevação, registrada no cabeçalho (obrigatória)")
+	cellSize := flag.Float64("cell", 0.5, "tamanho da célula, em graus (o worker usa 0,5)")
+	flag.Parse()
+
+	if *in == "" || *source == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*in, *out, *source, *cellSize); err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+}
+
+func run(in, out, source string, cellSize float64) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cells, err := geo.AggregateDEM(bufio.NewReaderSize(f, 1<<20), geo.BrazilBounds, cellSize)
+	if err != nil {
+		return err
+	}
+	if len(cells) == 0 {
+		return fmt.Errorf("%s: nenhuma célula com terra dentro do Brasil", in)
+	}
+
+	dst, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(dst)
+	b := geo.BrazilBounds
+	fmt.Fprintf(w, "# Grade de altitude de %s° do Brasil (lat %g a %g, lon %g a %g), gerada por cmd/elevgrid a partir de: %s.\n",
+		strconv.FormatFloat(cellSize, 'f', -1, 64), b.MinLat, b.MaxLat, b.MinLon, b.MaxLon, source)
+	fmt.Fprintln(w, "# Valor = média dos pixels de terra da célula (m); células só de oceano ficam de fora. Em relevo acidentado a média pode diferir da estação em centenas de metros.")
+	fmt.Fprintln(w, "# Regenerar: go run ./cmd/elevgrid -in <modelo.asc> -source \"<modelo>\" (ver README, Pressão ao nível do mar).")
+	fmt.Fprintln(w, "latitude,longitude,altitude")
+	for _, c := range cells {
+		fmt.Fprintf(w, "%g,%g,%g\n", c.Latitude, c.Longitude, c.Elevation)
+	}
+	if err := w.Flush(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	log.Printf("[INFO] %s: %d células gravadas", out, len(cells))
+	return nil
+}
@@ -0,0 +1,86 @@
+// Comando gazetteer gera a lista de municípios embutida no worker
+// (internal/geo/data/municipios.csv) a partir da lista completa do IBGE com a
+// posição da sede. Exemplo, com o municipios.csv do repositório
+// kelvins/municipios-brasileiros:
+//
+//	curl -LO https://raw.githubusercontent.com/kelvins/municipios-brasileiros/main/csv/municipios.csv
+//	go run ./cmd/gazetteer -in municipios.csv -source "IBGE, via kelvins/municipios-brasileiros"
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+
+	"go-worker/internal/geo"
+)
+
+func main() {
+	in := flag.String("in", "", "lista de municípios do IBGE em CSV (obrigatório)")
+	out := flag.String("out", "internal/geo/data/municipios.csv", "arquivo CSV gerado")
+	source := flag.String("source", "", "descrição da lista, registrada no cabeçalho (obrigatória)")
+	flag.Parse()
+
+	if *in == "" || *source == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*in, *out, *source); err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+}
+
+func run(in, out, source string) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	places, err := geo.ParseIBGEMunicipios(f)
+	if err != nil {
+		return err
+	}
+	if len(places) == 0 {
+		return fmt.Errorf("%s: nenhum município", in)
+	}
+	median := geo.SeatSpacingKm(places, 0.5)
+	radius := math.Round(median / 2)
+
+	dst, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(dst)
+	fmt.Fprintf(w, "# %d municípios brasileiros com a posição da sede (WGS84), gerada por cmd/gazetteer a partir de: %s.\n", len(places), source)
+	fmt.Fprintf(w, "# Distância mediana entre sedes vizinhas: %.1f km; GEO_MAX_DISTANCE_KM sugerido (metade dela): %g km.\n", median, radius)
+	fmt.Fprintln(w, "# Regenerar: go run ./cmd/gazetteer -in <municipios.csv> -source \"<lista>\" (ver README, Geocodificação Reversa).")
+	rows := csv.NewWriter(w)
+	rows.Write([]string{"nome", "uf", "latitude", "longitude", "populacao"})
+	for _, p := range places {
+		population := ""
+		if p.Population > 0 {
+			population = strconv.Itoa(p.Population)
+		}
+		rows.Write([]string{p.Name, p.UF, strconv.FormatFloat(p.Latitude, 'f', -1, 64), strconv.FormatFloat(p.Longitude, 'f', -1, 64), population})
+	}
+	rows.Flush()
+	if err := rows.Error(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	log.Printf("[INFO] %s: %d municípios gravados; raio sugerido %g km", out, len(places), radius)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go-worker/internal/config"
+)
+
+// configFlags são as flags comuns aos subcomandos; quando informadas,
+// sobrescrevem as variáveis de ambiente e o arquivo de configuração
+type configFlags struct {
+	file        string
+	rabbitmqURL string
+	queue       string
+	apiURL      string
+	logLevel    string
+	concurrency int
+	maxRetry    int
+	retryDelay  time.Duration
+}
+
+func (f *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "config", os.Getenv("CONFIG_FILE"), "arquivo de configuração KEY=VALUE (CONFIG_FILE)")
+	fs.StringVar(&f.rabbitmqURL, "rabbitmq-url", "", "URL AMQP completa (sobrescreve RABBITMQ_HOST/PORT/USER/PASSWORD)")
+	fs.StringVar(&f.queue, "queue", "", "nome da fila (RABBITMQ_QUEUE)")
+	fs.StringVar(&f.apiURL, "api-url", "", "URL completa do endpoint da API (BACKEND_API_URL + BACKEND_API_ENDPOINT)")
+	fs.StringVar(&f.logLevel, "log-level", "", "nível de log: debug, info, warn, error (LOG_LEVEL)")
+	fs.IntVar(&f.concurrency, "concurrency", 0, "mensagens processadas em paralelo (WORKER_CONCURRENCY)")
+	fs.IntVar(&f.maxRetry, "max-retry", 0, "tentativas de envio para a API (MAX_RETRY_ATTEMPTS)")
+	fs.DurationVar(&f.retryDelay, "retry-delay", 0, "atraso base do backoff (RETRY_DELAY)")
+}
+
+// apply sobrescreve na configuração apenas as flags informadas
+func (f *configFlags) apply(fs *flag.FlagSet) func(*config.Config) {
+	set := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	return func(cfg *config.Config) {
+		if set["rabbitmq-url"] {
+			cfg.RabbitMQURL = f.rabbitmqURL
+		}
+		if set["queue"] {
+			cfg.QueueName = f.queue
+		}
+		if set["api-url"] {
+			cfg.NestJSAPIURL = f.apiURL
+		}
+		if set["log-level"] {
+			cfg.LogLevel = f.logLevel
+		}
+		if set["concurrency"] {
+			cfg.WorkerConcurrency = f.concurrency
+		}
+		if set["max-retry"] {
+			cfg.MaxRetryAttempts = f.maxRetry
+		}
+		if set["retry-delay"] {
+			cfg.RetryDelay = f.retryDelay
+		}
+	}
+}
+
+// load carrega a configuração, aplica as flags e valida o resultado
+func (f *configFlags) load(fs *flag.FlagSet) (*config.Config, func(*config.Config), error) {
+	cfg, err := config.LoadFile(f.file)
+	if err != nil {
+		return nil, nil, err
+	}
+	override := f.apply(fs)
+	override(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, override, nil
+}
+
+// parseFlags interpreta as flags do subcomando, retornando o código de saída
+// quando a execução deve terminar
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "argumentos inesperados: %v\n", fs.Args())
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-worker/internal/loadgen"
+	"go-worker/internal/messaging"
+)
+
+// loadgenCommand publica tráfego sintético de N estações virtuais para testes de carga
+func loadgenCommand(args []string) int {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	var cf configFlags
+	cf.register(fs)
+	stations := fs.Int("stations", 10, "número de estações virtuais")
+	rate := fs.Float64("rate", 10, "mensagens por segundo")
+	duration := fs.Duration("duration", time.Minute, "duração da execução (0 = até CTRL+C)")
+	invalid := fs.Float64("invalid-fraction", 0, "fração de mensagens com valores fora das faixas")
+	malformed := fs.Float64("malformed-fraction", 0, "fração de mensagens com JSON quebrado")
+	out := fs.String("out", "amqp", "destino: amqp ou ndjson")
+	file := fs.String("file", "-", "arquivo de saída para --out ndjson (\"-\" para stdout)")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "semente do gerador (reprodutibilidade)")
+	timeScale := fs.Float64("time-scale", 1, "aceleração do relógio simulado (3600 = 1h por segundo)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, _, err := cf.load(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida: %v\n", err)
+		return exitConfig
+	}
+
+	gen, err := loadgen.NewGenerator(loadgen.Options{
+		Stations:          *stations,
+		InvalidFraction:   *invalid,
+		MalformedFraction: *malformed,
+		Seed:              *seed,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var output loadgen.Output
+	var publisher *messaging.RabbitMQPublisher
+	switch *out {
+	case "amqp":
+		publisher, err = messaging.NewRabbitMQPublisher(cfg.RabbitMQURL, cfg.QueueName)
+		if err != nil {
+			log.Printf("[ERROR] %v", err)
+			return exitFailure
+		}
+		defer publisher.Close()
+		if err := publisher.EnableConfirms(); err != nil {
+			log.Printf("[ERROR] %v", err)
+			return exitFailure
+		}
+		output = amqpOutput{publisher}
+	case "ndjson":
+		w, closeOutput, err := openOutput(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		defer closeOutput()
+		output = ndjsonOutput{w}
+	default:
+		fmt.Fprintf(os.Stderr, "destino desconhecido: %q (use amqp ou ndjson)\n", *out)
+		return exitUsage
+	}
+
+	log.Printf("[INFO] Gerando carga: %d estação(ões), %.1f msg/s, duração %v, destino %s",
+		*stations, *rate, *duration, *out)
+
+	report, err := loadgen.Run(ctx, gen, output, loadgen.RunOptions{
+		Rate:      *rate,
+		Duration:  *duration,
+		TimeScale: *timeScale,
+	})
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+		return exitFailure
+	}
+
+	if publisher != nil {
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		acked, nacked, err := publisher.WaitConfirms(waitCtx)
+		waitCancel()
+		if err != nil {
+			log.Printf("[WARN] %v", err)
+		}
+		report.Confirms = &loadgen.ConfirmStats{Acked: acked, Nacked: nacked}
+		if report.Published > 0 {
+			report.Confirms.Rate = float64(acked) / float64(report.Published)
+		}
+	}
+
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	if report.Failed > 0 || (report.Confirms != nil && report.Confirms.Acked < report.Published) {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// amqpOutput publica na fila com publisher confirms
+type amqpOutput struct {
+	publisher *messaging.RabbitMQPublisher
+}
+
+func (o amqpOutput) Publish(ctx context.Context, body []byte) error {
+	return o.publisher.Publish(ctx, body, "application/json")
+}
+
+// ndjsonOutput escreve uma mensagem por linha
+type ndjsonOutput struct {
+	w *bufio.Writer
+}
+
+func (o ndjsonOutput) Publish(_ context.Context, body []byte) error {
+	if _, err := o.w.Write(body); err != nil {
+		return err
+	}
+	return o.w.WriteByte('\n')
+}
+
+// openOutput abre o arquivo informado ou stdout para "-"
+func openOutput(path string) (*bufio.Writer, func(), error) {
+	if path == "-" || path == "" {
+		w := bufio.NewWriter(os.Stdout)
+		return w, func() { w.Flush() }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao criar saída: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	return w, func() {
+		w.Flush()
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Códigos de saída, estáveis para uso em scripts
+const (
+	exitOK          = 0 // sucesso
+	exitFailure     = 1 // erro em tempo de execução (conexão, envio, ...)
+	exitUsage       = 2 // subcomando ou flags inválidos
+	exitConfig      = 3 // configuração inválida
+	exitInvalidData = 4 // uma ou mais mensagens rejeitadas (process)
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{"run", "Consome a fila RabbitMQ e envia os dados para a API (padrão)", runCommand},
+	{"validate-config", "Carrega e valida a configuração efetiva", validateConfigCommand},
+	{"process", "Executa o pipeline sobre mensagens NDJSON de um arquivo ou stdin", processCommand},
+	{"publish", "Publica mensagens de teste na fila", publishCommand},
+	{"loadgen", "Gera carga sintética de estações virtuais (AMQP ou NDJSON)", loadgenCommand},
+	{"version", "Mostra as informações de build", versionCommand},
+}
+
+func main() {
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+func dispatch(args []string) int {
+	// Sem subcomando mantém o comportamento anterior: consumir a fila
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help") {
+		return runCommand(args)
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "--help":
+		usage()
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "subcomando desconhecido: %q\n\n", name)
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Uso: worker <subcomando> [flags]\n\nSubcomandos:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nUse \"worker <subcomando> -h\" para ver as flags de cada subcomando.\n")
+	fmt.Fprintf(os.Stderr, "\nCódigos de saída: 0 sucesso, 1 erro de execução, 2 uso inválido, 3 configuração inválida, 4 mensagens rejeitadas\n")
+}
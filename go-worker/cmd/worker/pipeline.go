@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+
+	"go-worker/internal/anomaly"
+	"go-worker/internal/config"
+	"go-worker/internal/geo"
+	"go-worker/internal/models"
+	"go-worker/internal/processor"
+	"go-worker/internal/qc"
+	"go-worker/internal/rules"
+)
+
+// enrichment guarda os enriquecimentos que dependem de arquivos de dados
+// (gazetteer, regiões, altitudes, regras de qualidade) para que possam ser
+// trocados na recarga de configuração
+type enrichment struct {
+	proc    *processor.Processor
+	regions *processor.RegionEnricher
+	// qc é o controle de qualidade temporal; nil com QC_WINDOW=0
+	qc *qc.Checker
+	// anomalies é a detecção de anomalias; nil com ANOMALY_THRESHOLD=0
+	anomalies *anomaly.Detector
+}
+
+// newProcessor monta o pipeline com os enriquecimentos configurados. É usado
+// tanto pelo consumer (run) quanto pelo modo offline (process), para que as
+// duas execuções produzam o mesmo resultado.
+func newProcessor(cfg *config.Config, sender processor.Sender) (*processor.Processor, *enrichment, error) {
+	proc := processor.NewProcessor(sender)
+	enr := &enrichment{proc: proc, regions: processor.NewRegionEnricher(nil)}
+	commit, err := enr.prepare(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	commit()
+
+	models.SetTimestampPolicy(timestampPolicy(cfg))
+	if err := proc.SetLanguage(cfg.DescriptionLanguage); err != nil {
+		return nil, nil, err
+	}
+	proc.AddEnricher(enr.regions)
+
+	if cfg.QCWindow > 0 {
+		enr.qc = qc.NewChecker(qcConfig(cfg))
+		proc.SetQualityControl(enr.qc)
+	}
+	if cfg.AnomalyThreshold > 0 {
+		enr.anomalies = anomaly.NewDetector(anomalyConfig(cfg))
+		proc.SetAnomalyDetector(enr.anomalies)
+	}
+	return proc, enr, nil
+}
+
+// prepare carrega os arquivos de dados da configuração; commit os coloca em uso
+func (e *enrichment) prepare(cfg *config.Config) (func(), error) {
+	resolver, err := newResolver(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var regions *geo.RegionIndex
+	if cfg.RegionsFile != "" {
+		if regions, err = geo.LoadRegions(cfg.RegionsFile); err != nil {
+			return nil, err
+		}
+	}
+	elevations := &geo.ElevationResolver{MatchKm: geo.DefaultStationMatchKm}
+	if cfg.StationElevationsFile != "" {
+		if elevations.Stations, err = geo.LoadStationElevations(cfg.StationElevationsFile); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ElevationGridFile != "" {
+		if elevations.Grid, err = geo.LoadElevationGrid(cfg.ElevationGridFile); err != nil {
+			return nil, err
+		}
+	}
+	// As sobreposições por região usam o mesmo índice de REGIONS_FILE
+	var ruleSet *rules.RuleSet
+	if cfg.RulesFile != "" {
+		if ruleSet, err = rules.Load(cfg.RulesFile, regions); err != nil {
+			return nil, err
+		}
+	}
+	// Os bindings só podem apontar para tipos registrados
+	if err := e.proc.CheckBindings(cfg.MessageTypeBindings); err != nil {
+		return nil, fmt.Errorf("MESSAGE_TYPE_BINDINGS: %w", err)
+	}
+	return func() {
+		geo.SetDefaultResolver(resolver)
+		geo.SetDefaultElevations(elevations)
+		e.regions.SetIndex(regions)
+		e.regions.SetProperties(cfg.RegionProperties)
+		e.proc.SetRules(ruleSet)
+		e.proc.SetBindings(cfg.MessageTypeBindings)
+		e.proc.SetStrictSchema(cfg.SchemaStrict)
+		e.proc.SetLenientNormalization(cfg.LenientNormalization)
+	}, nil
+}
+
+// timestampPolicy monta a política de horários da configuração
+func timestampPolicy(cfg *config.Config) models.TimestampPolicy {
+	policy := models.DefaultTimestampPolicy
+	policy.MaxAge = cfg.MaxObservationAge
+	return policy
+}
+
+// qcConfig ajusta a janela de persistência do controle de qualidade; as
+// observações precisam cobrir ao menos 5/6 dela
+func qcConfig(cfg *config.Config) qc.Config {
+	c := qc.DefaultConfig()
+	c.PersistenceWindow = cfg.QCWindow
+	c.PersistenceMinSpan = cfg.QCWindow * 5 / 6
+	return c
+}
+
+// anomalyConfig aplica o limiar e o aquecimento configurados
+func anomalyConfig(cfg *config.Config) anomaly.Config {
+	c := anomaly.DefaultConfig()
+	c.Threshold = cfg.AnomalyThreshold
+	c.WarmUp = cfg.AnomalyWarmUp
+	return c
+}
+
+// newResolver monta o geocodificador reverso a partir do gazetteer configurado
+func newResolver(cfg *config.Config) (*geo.Resolver, error) {
+	gazetteer := geo.Embedded()
+	if cfg.GazetteerFile != "" {
+		var err error
+		if gazetteer, err = geo.LoadFile(cfg.GazetteerFile); err != nil {
+			return nil, err
+		}
+	}
+	return &geo.Resolver{Gazetteer: gazetteer, MaxDistanceKm: cfg.GeoMaxDistanceKm}, nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"go-worker/internal/client"
+	"go-worker/internal/logging"
+	"go-worker/internal/models"
+	"go-worker/internal/processor"
+)
+
+// lineResult é a linha do relatório gerado por "process --report"
+type lineResult struct {
+	Line   int                `json:"line"`
+	Status string             `json:"status"`
+	Type   string             `json:"type,omitempty"`
+	Stage  string             `json:"stage,omitempty"`
+	Error  string             `json:"error,omitempty"`
+	Log    *models.WeatherLog `json:"log,omitempty"`
+	// Logs lista todos os registros de uma linha com previsões; Log é o primeiro
+	Logs []models.WeatherLog `json:"logs,omitempty"`
+	// AirQuality é o resultado das linhas de qualidade do ar
+	AirQuality *models.AirQualityLog `json:"airQuality,omitempty"`
+	// Records são os registros dos demais tipos de mensagem
+	Records []any `json:"records,omitempty"`
+
+	Violations []models.Violation `json:"violations,omitempty"`
+}
+
+// Status das linhas no relatório
+const (
+	statusOK         = "ok"
+	statusInvalid    = "invalid"
+	statusSent       = "sent"
+	statusSendFailed = "send_failed"
+)
+
+// processCommand executa o pipeline sobre mensagens NDJSON (uma por linha)
+// sem broker, imprimindo cada WeatherLog resultante ou um relatório por linha
+func processCommand(args []string) int {
+	fs := flag.NewFlagSet("process", flag.ContinueOnError)
+	var cf configFlags
+	cf.register(fs)
+	in := fs.String("in", "-", "arquivo NDJSON de entrada (\"-\" para stdin)")
+	report := fs.Bool("report", false, "imprime um relatório JSON por linha em vez dos WeatherLogs")
+	send := fs.Bool("send", false, "envia também os logs válidos para a API")
+	explain := fs.Bool("explain", false, "imprime o trace de cada decisão do pipeline (implica não enviar)")
+	contentType := fs.String("content-type", "", "content type das linhas (ex.: text/plain para METAR); vazio detecta pelo payload")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, _, err := cf.load(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida: %v\n", err)
+		return exitConfig
+	}
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitConfig
+	}
+
+	r, closeInput, err := openInput(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	defer closeInput()
+
+	// O envio é feito aqui, e não pelo processador, para registrar o resultado por linha
+	proc, _, err := newProcessor(cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "erro ao criar processador: %v\n", err)
+		return exitConfig
+	}
+
+	if *explain {
+		return explainLines(proc, r, cfg.BackendContract, *contentType)
+	}
+
+	// Sem --send não há destinos e nada é enviado
+	var sinks map[string]processor.Sink
+	if *send {
+		contract, err := client.LookupContract(cfg.BackendContract)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitConfig
+		}
+		apiClient := client.NewAPIClient(cfg.NestJSAPIURL, cfg.MaxRetryAttempts, cfg.RetryDelay)
+		apiClient.SetForecastURL(cfg.ForecastAPIURL)
+		apiClient.SetAirQualityURL(cfg.AirQualityAPIURL)
+		apiClient.SetContract(contract)
+		sinks = processor.DefaultSinks(apiClient)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	var total, invalid, sendFailed int
+	lineNum := 0
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		total++
+
+		result := lineResult{Line: lineNum, Status: statusOK}
+		msg := processor.Message{Body: line, ContentType: *contentType}
+		dispatched, err := proc.Dispatch(msg)
+		result.Type = dispatched.Type
+		if err != nil {
+			invalid++
+			result.Status = statusInvalid
+			result.Error = err.Error()
+			var stageErr *processor.StageError
+			if errors.As(err, &stageErr) {
+				result.Stage = stageErr.Stage
+			}
+			var validationErr *models.ValidationError
+			if errors.As(err, &validationErr) {
+				result.Violations = validationErr.Violations
+			}
+			log.Printf("[ERROR] linha %d: %v", lineNum, err)
+		} else {
+			setRecords(&result, dispatched.Records)
+			if sinks == nil {
+				dispatched.CommitAll()
+			} else if !sendLine(sinks, dispatched, &result) {
+				sendFailed++
+			}
+		}
+
+		var writeErr error
+		if *report {
+			writeErr = out.Encode(result)
+		} else {
+			for i := 0; i < len(dispatched.Records) && writeErr == nil; i++ {
+				writeErr = out.Encode(dispatched.Records[i])
+			}
+		}
+		if writeErr != nil {
+			fmt.Fprintf(os.Stderr, "erro ao escrever saída: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "erro ao ler entrada: %v\n", err)
+		return exitFailure
+	}
+
+	log.Printf("[INFO] %d linha(s) processada(s): %d válida(s), %d inválida(s), %d falha(s) de envio",
+		total, total-invalid, invalid, sendFailed)
+
+	switch {
+	case sendFailed > 0:
+		return exitFailure
+	case invalid > 0:
+		return exitInvalidData
+	default:
+		return exitOK
+	}
+}
+
+// setRecords coloca os registros da linha no campo do relatório de cada tipo
+func setRecords(result *lineResult, records []any) {
+	var weatherLogs []models.WeatherLog
+	for _, record := range records {
+		switch r := record.(type) {
+		case models.WeatherLog:
+			weatherLogs = append(weatherLogs, r)
+		case models.AirQualityLog:
+			result.AirQuality = &r
+		default:
+			result.Records = append(result.Records, r)
+		}
+	}
+	if len(weatherLogs) > 0 {
+		result.Log = &weatherLogs[0]
+	}
+	if len(weatherLogs) > 1 {
+		result.Logs = weatherLogs
+	}
+}
+
+// sendLine envia os registros da linha ao destino da sua rota, parando na
+// primeira falha, e registra o estado das estações de cada registro enviado;
+// retorna false se algum envio falhar
+func sendLine(sinks map[string]processor.Sink, dispatched processor.Dispatched, result *lineResult) bool {
+	result.Status = statusSent
+	sink, ok := sinks[dispatched.Route]
+	if !ok {
+		result.Status = statusSendFailed
+		result.Stage = processor.StageSend
+		result.Error = fmt.Sprintf("%v: %s", processor.ErrNoSink, dispatched.Route)
+		log.Printf("[ERROR] linha %d: %s", result.Line, result.Error)
+		return false
+	}
+	for i, record := range dispatched.Records {
+		if err := sink.Send(record); err != nil {
+			result.Status = statusSendFailed
+			result.Stage = processor.StageSend
+			result.Error = err.Error()
+			log.Printf("[ERROR] linha %d: erro ao enviar para API: %v", result.Line, err)
+			return false
+		}
+		dispatched.Commit(i)
+	}
+	return true
+}
+
+// explainLines imprime o trace de cada linha da entrada
+func explainLines(proc *processor.Processor, r io.Reader, contractVersion, contentType string) int {
+	contract, err := client.LookupContract(contractVersion)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitConfig
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	rejected := 0
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		trace := proc.ExplainMessage(processor.Message{Body: line, ContentType: contentType}, contract)
+		if trace.Outcome == processor.OutcomeRejected {
+			rejected++
+		}
+		if err := out.Encode(trace); err != nil {
+			fmt.Fprintf(os.Stderr, "erro ao escrever saída: %v\n", err)
+			return exitFailure
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "erro ao ler entrada: %v\n", err)
+		return exitFailure
+	}
+
+	if rejected > 0 {
+		return exitInvalidData
+	}
+	return exitOK
+}
+
+// openInput abre o arquivo informado ou stdin para "-"
+func openInput(path string) (io.Reader, func(), error) {
+	if path == "-" || path == "" {
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao abrir entrada: %w", err)
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// newLineScanner cria um scanner de linhas que aceita mensagens de até 1 MiB
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return scanner
+}
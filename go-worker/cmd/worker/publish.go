@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go-worker/internal/messaging"
+	"go-worker/internal/models"
+)
+
+// publishCommand publica mensagens de teste na fila: as linhas de um arquivo
+// NDJSON ou, sem --in, uma mensagem de exemplo no formato do serviço Python
+func publishCommand(args []string) int {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	var cf configFlags
+	cf.register(fs)
+	in := fs.String("in", "", "arquivo NDJSON com as mensagens (\"-\" para stdin)")
+	count := fs.Int("count", 1, "quantidade de mensagens de exemplo (sem --in)")
+	interval := fs.Duration("interval", 0, "intervalo entre publicações")
+	lat := fs.Float64("lat", -23.5505, "latitude da mensagem de exemplo")
+	lon := fs.Float64("lon", -46.6333, "longitude da mensagem de exemplo")
+	contentType := fs.String("content-type", "application/json", "content type das mensagens (ex.: text/plain para METAR)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, _, err := cf.load(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida: %v\n", err)
+		return exitConfig
+	}
+
+	var bodies [][]byte
+	if *in != "" {
+		r, closeInput, err := openInput(*in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		defer closeInput()
+
+		scanner := newLineScanner(r)
+		for scanner.Scan() {
+			if line := scanner.Bytes(); len(line) > 0 {
+				bodies = append(bodies, append([]byte(nil), line...))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "erro ao ler entrada: %v\n", err)
+			return exitFailure
+		}
+	} else {
+		for i := 0; i < *count; i++ {
+			body, err := json.Marshal(sampleMessage(*lat, *lon, time.Now()))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitFailure
+			}
+			bodies = append(bodies, body)
+		}
+	}
+
+	publisher, err := messaging.NewRabbitMQPublisher(cfg.RabbitMQURL, cfg.QueueName)
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+		return exitFailure
+	}
+	defer publisher.Close()
+
+	ctx := context.Background()
+	for i, body := range bodies {
+		if i > 0 && *interval > 0 {
+			time.Sleep(*interval)
+		}
+		if err := publisher.Publish(ctx, body, *contentType); err != nil {
+			log.Printf("[ERROR] mensagem %d: %v", i+1, err)
+			return exitFailure
+		}
+	}
+
+	log.Printf("[INFO] %d mensagem(ns) publicada(s) na fila '%s'", len(bodies), cfg.QueueName)
+	return exitOK
+}
+
+// sampleMessage monta uma mensagem válida no formato do serviço Python
+func sampleMessage(lat, lon float64, now time.Time) models.WeatherMessage {
+	return models.WeatherMessage{
+		Timestamp: now.UTC().Format("2006-01-02T15:04:05.000000"),
+		Location: models.WeatherLocation{
+			Latitude:  lat,
+			Longitude: lon,
+			Timezone:  "America/Sao_Paulo",
+		},
+		Current: models.WeatherCurrent{
+			Temperature: 24.3,
+			Humidity:    68,
+			WindSpeed:   9.7,
+			WeatherCode: 2,
+			Time:        models.FormatLocalTime(now, "America/Sao_Paulo"),
+
+			Precipitation:   models.Float64(0),
+			SurfacePressure: models.Float64(925.4),
+			PressureMSL:     models.Float64(1016.2),
+			WindDirection:   models.Float64(120),
+			CloudCover:      models.Float64(40),
+		},
+	}
+}
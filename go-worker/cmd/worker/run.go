@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-worker/internal/admin"
+	"go-worker/internal/anomaly"
+	"go-worker/internal/client"
+	"go-worker/internal/config"
+	"go-worker/internal/logging"
+	"go-worker/internal/messaging"
+	"go-worker/internal/models"
+	"go-worker/internal/processor"
+)
+
+// runCommand consome a fila RabbitMQ até receber SIGINT/SIGTERM
+func runCommand(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	var cf configFlags
+	cf.register(fs)
+	dryRun := fs.Bool("dry-run", false, "processa sem enviar nem confirmar, imprimindo o trace de cada mensagem")
+	maxMessages := fs.Int("max-messages", 100, "mensagens inspecionadas no dry-run (ficam retidas até o fim)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	log.Println("[INFO] Iniciando Go Worker Service...")
+
+	// Carrega configurações
+	cfg, override, err := cf.load(fs)
+	if err != nil {
+		log.Printf("[FATAL] Configuração inválida: %v", err)
+		return exitConfig
+	}
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		log.Printf("[FATAL] %v", err)
+		return exitConfig
+	}
+	log.Printf("[INFO] Configurações carregadas: Queue=%s, API=%s, MaxRetry=%d",
+		cfg.QueueName, cfg.NestJSAPIURL, cfg.MaxRetryAttempts)
+
+	contract, err := client.LookupContract(cfg.BackendContract)
+	if err != nil {
+		log.Printf("[FATAL] %v", err)
+		return exitConfig
+	}
+
+	// Aguarda RabbitMQ estar disponível
+	log.Println("[INFO] Aguardando RabbitMQ estar disponível...")
+	if err := messaging.WaitForConnection(cfg.RabbitMQURL, 10, 5*time.Second); err != nil {
+		log.Printf("[FATAL] %v", err)
+		return exitFailure
+	}
+
+	// Cria cliente API
+	apiClient := client.NewAPIClient(cfg.NestJSAPIURL, cfg.MaxRetryAttempts, cfg.RetryDelay)
+	apiClient.SetForecastURL(cfg.ForecastAPIURL)
+	apiClient.SetAirQualityURL(cfg.AirQualityAPIURL)
+	apiClient.SetContract(contract)
+
+	// Cria processador
+	proc, enr, err := newProcessor(cfg, apiClient)
+	if err != nil {
+		log.Printf("[FATAL] Erro ao criar processador: %v", err)
+		return exitConfig
+	}
+
+	// Cria consumer RabbitMQ
+	consumer, err := messaging.NewRabbitMQConsumer(cfg.RabbitMQURL, cfg.QueueName)
+	if err != nil {
+		log.Printf("[FATAL] Erro ao criar consumer: %v", err)
+		return exitFailure
+	}
+	defer consumer.Close()
+
+	// O histórico do controle de qualidade e as linhas de base das anomalias
+	// sobrevivem a reinícios; o dry-run os lê, mas não os grava
+	if enr.qc != nil && cfg.QCStateFile != "" {
+		if err := enr.qc.Load(cfg.QCStateFile); err != nil {
+			log.Printf("[WARN] %v; iniciando sem histórico", err)
+		} else {
+			log.Printf("[INFO] Histórico do controle de qualidade carregado: %d estações", enr.qc.Len())
+		}
+	}
+	if enr.anomalies != nil && cfg.AnomalyStateFile != "" {
+		if err := enr.anomalies.Load(cfg.AnomalyStateFile); err != nil {
+			log.Printf("[WARN] %v; iniciando o aquecimento do zero", err)
+		} else {
+			log.Printf("[INFO] Linhas de base das anomalias carregadas: %d estações", enr.anomalies.Len())
+		}
+	}
+
+	if *dryRun {
+		return dryRunCommand(proc, consumer, contract, *maxMessages)
+	}
+
+	if err := consumer.SetConcurrency(cfg.WorkerConcurrency); err != nil {
+		log.Printf("[FATAL] %v", err)
+		return exitFailure
+	}
+	if cfg.DeadLetterQueue != "" {
+		if err := consumer.SetDeadLetterQueue(cfg.DeadLetterQueue); err != nil {
+			log.Printf("[FATAL] %v", err)
+			return exitFailure
+		}
+	}
+	if cfg.QuarantineQueue != "" {
+		if err := consumer.SetQuarantineQueue(cfg.QuarantineQueue); err != nil {
+			log.Printf("[FATAL] %v", err)
+			return exitFailure
+		}
+	}
+
+	// Eventos de anomalia vão para a fila própria, quando configurada
+	if enr.anomalies != nil && cfg.AnomalyQueue != "" {
+		publisher, err := messaging.NewRabbitMQPublisher(cfg.RabbitMQURL, cfg.AnomalyQueue)
+		if err != nil {
+			log.Printf("[FATAL] Erro ao criar publisher de anomalias: %v", err)
+			return exitFailure
+		}
+		defer publisher.Close()
+		proc.SetAnomalyPublisher(anomalyPublisher{publisher})
+	}
+
+	// Alertas vão para a fila própria, quando configurada; sem ela falham no
+	// envio e seguem para a DEAD_LETTER_QUEUE
+	if cfg.AlertQueue != "" {
+		publisher, err := messaging.NewRabbitMQPublisher(cfg.RabbitMQURL, cfg.AlertQueue)
+		if err != nil {
+			log.Printf("[FATAL] Erro ao criar publisher de alertas: %v", err)
+			return exitFailure
+		}
+		defer publisher.Close()
+		proc.SetSink(processor.RouteAlert, alertSink(publisher))
+	}
+
+	// Registra o que pode ser alterado sem reiniciar; as flags continuam valendo
+	reloader := config.NewReloader(cfg, override)
+	reloader.OnReload(func(old, updated *config.Config) (func(), error) {
+		if _, err := logging.ParseLevel(updated.LogLevel); err != nil {
+			return nil, err
+		}
+		contract, err := client.LookupContract(updated.BackendContract)
+		if err != nil {
+			return nil, err
+		}
+		commitEnrichment, err := enr.prepare(updated)
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			commitEnrichment()
+			models.SetTimestampPolicy(timestampPolicy(updated))
+			if err := proc.SetLanguage(updated.DescriptionLanguage); err != nil {
+				log.Printf("[ERROR] Erro ao alterar idioma: %v", err)
+			}
+			apiClient.SetContract(contract)
+			logging.SetLevel(updated.LogLevel)
+			apiClient.SetRetryPolicy(updated.MaxRetryAttempts, updated.RetryDelay)
+			apiClient.SetBaseURL(updated.NestJSAPIURL)
+			apiClient.SetForecastURL(updated.ForecastAPIURL)
+			apiClient.SetAirQualityURL(updated.AirQualityAPIURL)
+			if updated.WorkerConcurrency != old.WorkerConcurrency {
+				if err := consumer.SetConcurrency(updated.WorkerConcurrency); err != nil {
+					log.Printf("[ERROR] Erro ao alterar concorrência: %v", err)
+				}
+			}
+		}, nil
+	})
+
+	// Comandos só são aceitos na fila própria, quando configurada: reload
+	// equivale ao SIGHUP e log-level vale até a próxima recarga, que volta ao
+	// LOG_LEVEL configurado. Na RABBITMQ_QUEUE um comando vai para a quarentena.
+	var commands *messaging.RabbitMQConsumer
+	if cfg.CommandQueue != "" {
+		proc.SetSink(processor.RouteCommand, processor.SinkFunc(func(record any) error {
+			cmd, ok := record.(processor.Command)
+			if !ok {
+				return fmt.Errorf("rota %s: registro %T não suportado", processor.RouteCommand, record)
+			}
+			switch cmd.Name {
+			case processor.CommandReload:
+				return reloader.Reload()
+			case processor.CommandLogLevel:
+				return logging.SetLevel(cmd.Args["level"])
+			}
+			return fmt.Errorf("comando não suportado: %s", cmd.Name)
+		}))
+
+		commands, err = messaging.NewRabbitMQConsumer(cfg.RabbitMQURL, cfg.CommandQueue)
+		if err != nil {
+			log.Printf("[FATAL] Erro ao criar consumer de comandos: %v", err)
+			return exitFailure
+		}
+		defer commands.Close()
+		if cfg.DeadLetterQueue != "" {
+			if err := commands.SetDeadLetterQueue(cfg.DeadLetterQueue); err != nil {
+				log.Printf("[FATAL] %v", err)
+				return exitFailure
+			}
+		}
+	}
+
+	// Contexto para graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Captura sinais do sistema para graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+
+	// Goroutine para processar sinais
+	go func() {
+		sig := <-sigChan
+		log.Printf("[INFO] Sinal recebido: %v. Iniciando graceful shutdown...", sig)
+		cancel()
+	}()
+
+	// SIGHUP recarrega a configuração
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupChan:
+				log.Println("[INFO] SIGHUP recebido, recarregando configuração...")
+				if err := reloader.Reload(); err != nil {
+					log.Printf("[ERROR] Recarga falhou, mantendo configuração atual: %v", err)
+					continue
+				}
+				log.Println("[INFO] Configuração recarregada")
+			}
+		}
+	}()
+
+	// Recarrega também quando o arquivo de configuração muda
+	go reloader.Watch(ctx, cfg.ConfigWatchInterval)
+
+	// Servidor administrativo (explain por mensagem)
+	if cfg.AdminAddr != "" {
+		adminServer := admin.NewServer(cfg.AdminAddr, proc, cfg.BackendContract)
+		go func() {
+			if err := adminServer.Start(ctx); err != nil {
+				log.Printf("[ERROR] Servidor administrativo: %v", err)
+			}
+		}()
+	}
+
+	// Grava os históricos periodicamente e ao encerrar
+	if enr.qc != nil && cfg.QCStateFile != "" {
+		checker, path := enr.qc, cfg.QCStateFile
+		go saveState(ctx, "histórico do controle de qualidade", func(now time.Time) error {
+			checker.Prune(now)
+			return checker.Save(path)
+		})
+		defer func() {
+			if err := checker.Save(path); err != nil {
+				log.Printf("[ERROR] Erro ao gravar histórico do controle de qualidade: %v", err)
+			}
+		}()
+	}
+	if enr.anomalies != nil && cfg.AnomalyStateFile != "" {
+		detector, path := enr.anomalies, cfg.AnomalyStateFile
+		go saveState(ctx, "linhas de base das anomalias", func(time.Time) error {
+			return detector.Save(path)
+		})
+		defer func() {
+			if err := detector.Save(path); err != nil {
+				log.Printf("[ERROR] Erro ao gravar linhas de base das anomalias: %v", err)
+			}
+		}()
+	}
+
+	if commands != nil {
+		go func() {
+			err := commands.Consume(ctx, func(d messaging.Delivery) error {
+				return proc.ProcessCommand(processor.Message{Body: d.Body, Headers: d.Headers, Type: d.Type, ContentType: d.ContentType, RoutingKey: d.RoutingKey})
+			})
+			if err != nil {
+				log.Printf("[ERROR] Erro durante consumo de comandos: %v", err)
+			}
+		}()
+	}
+
+	// Inicia consumo de mensagens
+	log.Println("[INFO] Worker iniciado com sucesso!")
+	err = consumer.Consume(ctx, func(d messaging.Delivery) error {
+		return proc.ProcessMessage(processor.Message{Body: d.Body, Headers: d.Headers, Type: d.Type, ContentType: d.ContentType, RoutingKey: d.RoutingKey})
+	})
+	if err != nil {
+		log.Printf("[ERROR] Erro durante consumo: %v", err)
+		return exitFailure
+	}
+
+	log.Println("[INFO] Worker encerrado")
+	return exitOK
+}
+
+// stateSaveInterval é o intervalo entre gravações dos históricos em disco
+const stateSaveInterval = time.Minute
+
+// saveState chama save a cada stateSaveInterval até o contexto ser cancelado
+func saveState(ctx context.Context, what string, save func(now time.Time) error) {
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := save(now); err != nil {
+				log.Printf("[ERROR] Erro ao gravar %s: %v", what, err)
+			}
+		}
+	}
+}
+
+// anomalyPublisher publica os eventos de anomalia como JSON na fila configurada
+type anomalyPublisher struct {
+	publisher *messaging.RabbitMQPublisher
+}
+
+func (a anomalyPublisher) PublishAnomaly(e anomaly.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return a.publisher.Publish(ctx, body, "application/json")
+}
+
+// alertSink publica os alertas como JSON na fila configurada
+func alertSink(publisher *messaging.RabbitMQPublisher) processor.Sink {
+	return processor.SinkFunc(func(record any) error {
+		alertLog, ok := record.(models.AlertLog)
+		if !ok {
+			return fmt.Errorf("rota %s: registro %T não suportado", processor.RouteAlert, record)
+		}
+		body, err := json.Marshal(alertLog)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return publisher.Publish(ctx, body, "application/json")
+	})
+}
+
+// dryRunCommand inspeciona mensagens da fila sem enviá-las nem confirmá-las,
+// imprimindo em stdout o trace JSON de cada uma
+func dryRunCommand(proc *processor.Processor, consumer *messaging.RabbitMQConsumer, contract client.Contract, maxMessages int) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	out := json.NewEncoder(os.Stdout)
+	err := consumer.Inspect(ctx, maxMessages, func(d messaging.Delivery) error {
+		return out.Encode(proc.ExplainMessage(processor.Message{Body: d.Body, Headers: d.Headers, Type: d.Type, ContentType: d.ContentType, RoutingKey: d.RoutingKey}, contract))
+	})
+	if err != nil {
+		log.Printf("[ERROR] Erro durante dry-run: %v", err)
+		return exitFailure
+	}
+
+	log.Println("[INFO] Dry-run encerrado; mensagens devolvidas à fila")
+	return exitOK
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+
+	"go-worker/internal/client"
+)
+
+// validateConfigCommand carrega a configuração efetiva (arquivo, ambiente e
+// flags) e a valida sem conectar a nada
+func validateConfigCommand(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	var cf configFlags
+	cf.register(fs)
+	quiet := fs.Bool("quiet", false, "não imprime a configuração efetiva")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, _, err := cf.load(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida: %v\n", err)
+		return exitConfig
+	}
+	if _, err := client.LookupContract(cfg.BackendContract); err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida: %v\n", err)
+		return exitConfig
+	}
+	// Carrega os arquivos de dados (gazetteer, regiões, altitudes, regras)
+	if _, _, err := newProcessor(cfg, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida: %v\n", err)
+		return exitConfig
+	}
+
+	if !*quiet {
+		fmt.Printf("RabbitMQ:            %s\n", redactURL(cfg.RabbitMQURL))
+		fmt.Printf("Fila:                %s\n", cfg.QueueName)
+		fmt.Printf("Dead-letter:         %s\n", cfg.DeadLetterQueue)
+		fmt.Printf("Quarentena:          %s\n", cfg.QuarantineQueue)
+		fmt.Printf("Bindings de tipo:    %v\n", cfg.MessageTypeBindings)
+		fmt.Printf("Schema estrito:      %t\n", cfg.SchemaStrict)
+		fmt.Printf("Normalização:        %t\n", cfg.LenientNormalization)
+		fmt.Printf("API:                 %s\n", cfg.NestJSAPIURL)
+		fmt.Printf("API de previsões:    %s\n", cfg.ForecastAPIURL)
+		fmt.Printf("API qualidade do ar: %s\n", cfg.AirQualityAPIURL)
+		fmt.Printf("Concorrência:        %d\n", cfg.WorkerConcurrency)
+		fmt.Printf("Tentativas:          %d\n", cfg.MaxRetryAttempts)
+		fmt.Printf("Atraso de retry:     %v\n", cfg.RetryDelay)
+		fmt.Printf("Nível de log:        %s\n", cfg.LogLevel)
+		fmt.Printf("Contrato do backend: %s\n", cfg.BackendContract)
+		fmt.Printf("Admin:               %s\n", cfg.AdminAddr)
+		fmt.Printf("Regras:              %s\n", cfg.RulesFile)
+		fmt.Printf("Janela do QC:        %v\n", cfg.QCWindow)
+		fmt.Printf("Limiar de anomalia:  %v\n", cfg.AnomalyThreshold)
+		fmt.Printf("Arquivo:             %s\n", cfg.ConfigFile)
+		fmt.Printf("Intervalo de watch:  %v\n", cfg.ConfigWatchInterval)
+	}
+	fmt.Println("configuração válida")
+	return exitOK
+}
+
+// redactURL oculta a senha de URLs com credenciais
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
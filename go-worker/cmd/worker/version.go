@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"go-worker/internal/version"
+)
+
+// versionCommand mostra as informações de build injetadas via ldflags
+func versionCommand(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "saída em JSON")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	info := version.Get()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	fmt.Println(info.String())
+	return exitOK
+}
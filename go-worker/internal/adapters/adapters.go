@@ -0,0 +1,208 @@
+// Package adapters converte os payloads dos provedores de tempo para a
+// mensagem canônica (models.WeatherMessage). O adaptador é escolhido pela
+// propriedade AMQP type, pelo content type ou, na falta deles, pelas chaves
+// do payload.
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"go-worker/internal/models"
+)
+
+// Formas de escolha do adaptador, registradas no trace
+const (
+	SelectedByType        = "type"
+	SelectedByContentType = "content-type"
+	SelectedBySniffing    = "sniff"
+	SelectedByDefault     = "default"
+)
+
+// Adapter converte o payload de um provedor
+type Adapter interface {
+	// Name identifica o provedor na propriedade type, no content type
+	// (application/vnd.<name>+json) e no campo source do payload
+	Name() string
+	// Detect reconhece o payload pelas chaves de primeiro nível
+	Detect(fields map[string]json.RawMessage) bool
+	// Decode converte o payload; params são os parâmetros do content type
+	Decode(body []byte, params map[string]string) (models.WeatherMessage, error)
+}
+
+// MediaTyper é implementado por adaptadores escolhidos também por content
+// types genéricos (ex.: text/plain para METAR)
+type MediaTyper interface {
+	MediaTypes() []string
+}
+
+// Selection descreve qual adaptador decodificou a mensagem e por quê
+type Selection struct {
+	Adapter string `json:"adapter"`
+	By      string `json:"by"`
+}
+
+// Registry guarda os adaptadores, na ordem em que são testados pelo sniffing
+type Registry struct {
+	adapters []Adapter
+	fallback Adapter
+}
+
+// NewRegistry cria um registro; fallback decodifica payloads que nenhum
+// adaptador reconhece
+func NewRegistry(fallback Adapter, adapters ...Adapter) *Registry {
+	return &Registry{adapters: adapters, fallback: fallback}
+}
+
+// Default retorna o registro com todos os provedores suportados. A mensagem
+// canônica é testada primeiro e também é o fallback, para que payloads
+// incompletos cheguem à validação.
+func Default() *Registry {
+	return DefaultWith(Canonical{})
+}
+
+// DefaultWith retorna o registro padrão com as opções informadas para a
+// mensagem canônica (modo estrito e normalização tolerante)
+func DefaultWith(canonical Canonical) *Registry {
+	return NewRegistry(canonical, canonical, OpenMeteo{}, WeatherAPI{}, OpenWeatherMap{}, Station{}, Metar{})
+}
+
+// Names lista os adaptadores registrados
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.adapters))
+	for _, a := range r.adapters {
+		names = append(names, a.Name())
+	}
+	return names
+}
+
+func (r *Registry) lookup(name string) (Adapter, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, a := range r.adapters {
+		if a.Name() == name {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Registry) lookupMediaType(mediaType string) (Adapter, bool) {
+	if mediaType == "" {
+		return nil, false
+	}
+	for _, a := range r.adapters {
+		if mt, ok := a.(MediaTyper); ok {
+			for _, t := range mt.MediaTypes() {
+				if t == mediaType {
+					return a, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// Decode escolhe o adaptador e converte a mensagem. msgType e contentType são
+// as propriedades AMQP; valores que não nomeiam um adaptador são ignorados.
+func (r *Registry) Decode(body []byte, msgType, contentType string) (models.WeatherMessage, Selection, error) {
+	return r.DecodeWith(body, msgType, contentType, nil)
+}
+
+// DecodeWith é Decode com parâmetros vindos de fora do content type (ex.:
+// headers AMQP), que têm precedência sobre os do content type
+func (r *Registry) DecodeWith(body []byte, msgType, contentType string, extra map[string]string) (models.WeatherMessage, Selection, error) {
+	adapter, sel, params := r.choose(body, msgType, contentType)
+	for k, v := range extra {
+		if v == "" {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string, len(extra))
+		}
+		params[k] = v
+	}
+	msg, err := adapter.Decode(body, params)
+	if err != nil {
+		return models.WeatherMessage{}, sel, fmt.Errorf("%s: %w", adapter.Name(), err)
+	}
+	return msg, sel, nil
+}
+
+func (r *Registry) choose(body []byte, msgType, contentType string) (Adapter, Selection, map[string]string) {
+	var params map[string]string
+	var mediaType, vendor string
+	if contentType != "" {
+		if mt, p, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType, params = mt, p
+			vendor = vendorOf(mt)
+		}
+	}
+
+	if a, ok := r.lookup(msgType); ok {
+		return a, Selection{Adapter: a.Name(), By: SelectedByType}, params
+	}
+	if a, ok := r.lookup(vendor); ok {
+		return a, Selection{Adapter: a.Name(), By: SelectedByContentType}, params
+	}
+	if a, ok := r.lookupMediaType(mediaType); ok {
+		return a, Selection{Adapter: a.Name(), By: SelectedByContentType}, params
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) == nil {
+		for _, a := range r.adapters {
+			if a.Detect(fields) {
+				return a, Selection{Adapter: a.Name(), By: SelectedBySniffing}, params
+			}
+		}
+	}
+	return r.fallback, Selection{Adapter: r.fallback.Name(), By: SelectedByDefault}, params
+}
+
+// vendorOf extrai o provedor de application/vnd.<provedor>+json
+func vendorOf(mediaType string) string {
+	subtype, ok := strings.CutPrefix(mediaType, "application/vnd.")
+	if !ok {
+		return ""
+	}
+	vendor, _, _ := strings.Cut(subtype, "+")
+	return vendor
+}
+
+// hasObjectWith indica se fields[key] é um objeto com todas as chaves informadas
+func hasObjectWith(fields map[string]json.RawMessage, key string, keys ...string) bool {
+	raw, ok := fields[key]
+	if !ok {
+		return false
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil {
+		return false
+	}
+	for _, k := range keys {
+		if _, ok := obj[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// has indica se todas as chaves estão presentes
+func has(fields map[string]json.RawMessage, keys ...string) bool {
+	for _, k := range keys {
+		if _, ok := fields[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// required devolve um campo obrigatório do provedor ou erro se ausente
+func required(field string, v *float64) (float64, error) {
+	if v == nil {
+		return 0, fmt.Errorf("campo obrigatório ausente: %s", field)
+	}
+	return *v, nil
+}
@@ -0,0 +1,267 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+var update = flag.Bool("update", false, "regrava os arquivos .golden.json")
+
+// testRegistry é o registro padrão com o relógio do METAR fixo no dia das fixtures
+func testRegistry() *Registry {
+	registry := Default()
+	for i, a := range registry.adapters {
+		if _, ok := a.(Metar); ok {
+			registry.adapters[i] = Metar{Now: func() time.Time {
+				return time.Date(2025, 6, 15, 14, 40, 0, 0, time.UTC)
+			}}
+		}
+	}
+	return registry
+}
+
+func TestRegistry_Golden(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		ext         string
+		msgType     string
+		contentType string
+		wantAdapter string
+		wantBy      string
+	}{
+		{"canonical", ".json", "", "application/json", "canonical", SelectedBySniffing},
+		{"open-meteo", ".json", "", "", "open-meteo", SelectedBySniffing},
+		{"open-meteo-forecast", ".json", "", "", "open-meteo", SelectedBySniffing},
+		{"openweathermap", ".json", "", "", "openweathermap", SelectedBySniffing},
+		{"weatherapi", ".json", "weatherapi", "", "weatherapi", SelectedByType},
+		{"station", ".json", "", "application/vnd.station+json", "station", SelectedByContentType},
+		{"metar", ".txt", "", "text/plain; charset=utf-8", "metar", SelectedByContentType},
+	}
+
+	registry := testRegistry()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture+tt.ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg, sel, err := registry.Decode(body, tt.msgType, tt.contentType)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if sel.Adapter != tt.wantAdapter || sel.By != tt.wantBy {
+				t.Errorf("Selection = %+v, want %s by %s", sel, tt.wantAdapter, tt.wantBy)
+			}
+
+			got, _ := json.MarshalIndent(msg, "", "  ")
+			got = append(got, '\n')
+			golden := filepath.Join("testdata", tt.fixture+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (rode go test -update)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Decode() mismatch with %s:\n%s", golden, got)
+			}
+
+			// Previsões sem current: cada registro expandido deve ser válido
+			if msg.HasForecast() {
+				entries, err := msg.Expand()
+				if err != nil {
+					t.Fatalf("Expand() error = %v", err)
+				}
+				for i := range entries {
+					if _, err := entries[i].NormalizeUnits(); err != nil {
+						t.Fatalf("NormalizeUnits() entry %d error = %v", i, err)
+					}
+					if err := entries[i].Validate(); err != nil {
+						t.Errorf("Validate() entry %d error = %v", i, err)
+					}
+				}
+				return
+			}
+
+			// Todas as fixtures descrevem São Paulo por volta de 25 °C: depois da
+			// normalização de unidades a mensagem deve ser válida e coerente
+			if _, err := msg.NormalizeUnits(); err != nil {
+				t.Fatalf("NormalizeUnits() error = %v", err)
+			}
+			if err := msg.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if math.Abs(msg.Current.Temperature-25.3) > 0.5 {
+				t.Errorf("Temperature = %v °C, want about 25.3", msg.Current.Temperature)
+			}
+			if msg.Current.WindSpeed < 9 || msg.Current.WindSpeed > 12 {
+				t.Errorf("WindSpeed = %v km/h, want about 10.5", msg.Current.WindSpeed)
+			}
+			if observed, _ := msg.ObservedAt(); observed.Format("15:04") != "14:30" {
+				t.Errorf("ObservedAt() = %v, want 14:30 UTC", observed)
+			}
+			if tt.fixture != "canonical" && msg.Source != tt.wantAdapter {
+				t.Errorf("Source = %q, want %q", msg.Source, tt.wantAdapter)
+			}
+		})
+	}
+}
+
+func TestRegistry_Selection(t *testing.T) {
+	registry := Default()
+	openMeteo := []byte(`{"latitude":-23.5,"longitude":-46.6,"timezone":"GMT","current":{"time":"2025-06-15T14:30","temperature_2m":25,"relative_humidity_2m":60}}`)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		msgType     string
+		contentType string
+		wantAdapter string
+		wantBy      string
+	}{
+		{"unrelated type falls back to sniffing", openMeteo, "weather.current", "", "open-meteo", SelectedBySniffing},
+		{"type wins over content type", openMeteo, "open-meteo", "application/vnd.station+json", "open-meteo", SelectedByType},
+		{"type is case insensitive", openMeteo, "Open-Meteo", "", "open-meteo", SelectedByType},
+		{"unknown payload uses canonical", []byte(`{"location":{"latitude":-23.5}}`), "", "", "canonical", SelectedByDefault},
+		{"invalid JSON uses canonical", []byte(`{invalid`), "", "", "canonical", SelectedByDefault},
+		{"text/plain selects metar", []byte(`SBGR 151430Z 14006KT CAVOK 25/17 Q1016`), "", "text/plain", "metar", SelectedByContentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sel, _ := registry.Decode(tt.body, tt.msgType, tt.contentType)
+			if sel.Adapter != tt.wantAdapter || sel.By != tt.wantBy {
+				t.Errorf("Selection = %+v, want %s by %s", sel, tt.wantAdapter, tt.wantBy)
+			}
+		})
+	}
+}
+
+func TestOpenWeatherMap_Units(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "openweathermap.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metric := bytes.Replace(body, []byte(`"temp": 298.71`), []byte(`"temp": 25.56`), 1)
+
+	msg, _, err := Default().Decode(metric, "", "application/vnd.openweathermap+json; units=metric")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if msg.Units["temperature"] != "°C" {
+		t.Errorf("Units = %v, want temperature in °C", msg.Units)
+	}
+
+	_, _, err = Default().Decode(body, "openweathermap", "application/json; units=kelvin")
+	if err == nil || !strings.Contains(err.Error(), "openweathermap") {
+		t.Errorf("Decode(units=kelvin) error = %v, want openweathermap error", err)
+	}
+}
+
+func TestAdapters_MissingRequired(t *testing.T) {
+	tests := []struct {
+		adapter Adapter
+		body    string
+	}{
+		{OpenMeteo{}, `{"latitude":-23.5,"longitude":-46.6,"current":{"time":"2025-06-15T14:30","relative_humidity_2m":60}}`},
+		{OpenWeatherMap{}, `{"coord":{"lat":-23.5,"lon":-46.6},"main":{"temp":298},"dt":1749997800}`},
+		{WeatherAPI{}, `{"location":{"lat":-23.5,"lon":-46.6},"current":{"temp_c":25,"humidity":60}}`},
+		{Station{}, `{"station_id":"A701","latitude":-23.5,"longitude":-46.6,"observed_at":"2025-06-15T14:30:00Z","humidity":60}`},
+		{Metar{}, `METAR SBGR 151430Z 14006KT 9999 FEW020 Q1016=`},
+		{Metar{}, `METAR SBGR 151430Z NIL=`},
+		{Metar{}, `METAR ZZZZ 151430Z 14006KT 9999 FEW020 25/17 Q1016=`},
+	}
+	for _, tt := range tests {
+		if _, err := tt.adapter.Decode([]byte(tt.body), nil); err == nil {
+			t.Errorf("%s.Decode() error = nil, want missing field error", tt.adapter.Name())
+		}
+	}
+}
+
+func TestWeatherCodeMapping(t *testing.T) {
+	owm := map[int]int{200: 95, 300: 51, 302: 55, 501: 63, 522: 82, 601: 73, 741: 45, 721: 3, 800: 0, 804: 3, 999: 999}
+	for id, want := range owm {
+		if got := openWeatherMapToWMO(id); got != want {
+			t.Errorf("openWeatherMapToWMO(%d) = %d, want %d", id, got, want)
+		}
+	}
+	for code, wmo := range weatherAPIToWMO {
+		if !models.DescribeWeatherCode(wmo, models.DefaultLanguage, true).Known {
+			t.Errorf("weatherAPIToWMO[%d] = %d, not a known WMO code", code, wmo)
+		}
+	}
+}
+
+func TestIsAirQuality(t *testing.T) {
+	openMeteo, err := os.ReadFile(filepath.Join("testdata", "open-meteo-air-quality.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	weather, err := os.ReadFile(filepath.Join("testdata", "open-meteo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		body        []byte
+		msgType     string
+		contentType string
+		want        bool
+	}{
+		{"open-meteo air quality sniffed", openMeteo, "", "", true},
+		{"canonical sniffed", []byte(`{"location":{"latitude":-23.5},"current":{"pm2_5":12}}`), "", "", true},
+		{"type property", []byte(`{}`), "air_quality", "", true},
+		{"vendor content type", []byte(`{}`), "", "application/vnd.air-quality+json", true},
+		{"weather observation", weather, "", "", false},
+		{"weather with particulates", []byte(`{"current":{"temperature":25,"pm10":30}}`), "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAirQuality(tt.body, tt.msgType, tt.contentType); got != tt.want {
+				t.Errorf("IsAirQuality() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeAirQuality(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "open-meteo-air-quality.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, sel, err := DecodeAirQuality(body)
+	if err != nil {
+		t.Fatalf("DecodeAirQuality() error = %v", err)
+	}
+	if sel.Adapter != "open-meteo-air-quality" || msg.Source != "open-meteo" {
+		t.Errorf("Selection = %+v, Source = %q", sel, msg.Source)
+	}
+	if msg.Timestamp != "2025-06-15T14:00:00Z" || msg.Current.PM25 == nil || *msg.Current.PM25 != 24.1 {
+		t.Errorf("msg = %+v", msg)
+	}
+	if len(msg.Units) != 6 || msg.Units["ozone"] != "μg/m³" {
+		t.Errorf("Units = %v, want the six pollutants", msg.Units)
+	}
+	if _, err := msg.NormalizeUnits(); err != nil {
+		t.Fatalf("NormalizeUnits() error = %v", err)
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if _, _, err := DecodeAirQuality([]byte(`{"latitude":-23.5,"longitude":-46.6}`)); err == nil {
+		t.Error("DecodeAirQuality(without current) error = nil")
+	}
+}
@@ -0,0 +1,111 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"go-worker/internal/aqi"
+	"go-worker/internal/models"
+)
+
+// AirQualityType identifica as mensagens de qualidade do ar na propriedade
+// AMQP type e no content type application/vnd.air-quality+json
+const AirQualityType = "air-quality"
+
+// Adaptadores das mensagens de qualidade do ar, registrados na Selection
+const (
+	airQualityCanonical = "air-quality"
+	airQualityOpenMeteo = "open-meteo-air-quality"
+)
+
+// IsAirQuality indica se a mensagem é de qualidade do ar: pela propriedade
+// type, pelo content type ou, na falta deles, por current com pm2_5 ou pm10
+// e sem temperatura
+func IsAirQuality(body []byte, msgType, contentType string) bool {
+	if normalizeType(msgType) == AirQualityType {
+		return true
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil && normalizeType(vendorOf(mt)) == AirQualityType {
+		return true
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return false
+	}
+	if hasObjectWith(fields, "current", "temperature") || hasObjectWith(fields, "current", "temperature_2m") {
+		return false
+	}
+	return hasObjectWith(fields, "current", aqi.PM25) || hasObjectWith(fields, "current", aqi.PM10)
+}
+
+// normalizeType aceita air_quality e variações de caixa
+func normalizeType(t string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(t)), "_", "-")
+}
+
+// openMeteoAirQuality é a resposta de /v1/air-quality da Open-Meteo, cujas
+// variáveis de current já têm os nomes canônicos
+type openMeteoAirQuality struct {
+	Latitude     *float64                  `json:"latitude"`
+	Longitude    *float64                  `json:"longitude"`
+	Elevation    *float64                  `json:"elevation"`
+	Timezone     string                    `json:"timezone"`
+	CurrentUnits map[string]string         `json:"current_units"`
+	Current      *models.AirQualityCurrent `json:"current"`
+}
+
+// DecodeAirQuality converte a mensagem canônica de qualidade do ar (com
+// location) ou a resposta bruta da API de qualidade do ar da Open-Meteo
+func DecodeAirQuality(body []byte) (models.AirQualityMessage, Selection, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return models.AirQualityMessage{}, Selection{Adapter: airQualityCanonical, By: SelectedByDefault}, err
+	}
+
+	if _, ok := fields["location"]; ok || !has(fields, "latitude", "longitude") {
+		sel := Selection{Adapter: airQualityCanonical, By: SelectedByDefault}
+		var msg models.AirQualityMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return models.AirQualityMessage{}, sel, fmt.Errorf("%s: %w", sel.Adapter, err)
+		}
+		return msg, sel, nil
+	}
+
+	sel := Selection{Adapter: airQualityOpenMeteo, By: SelectedBySniffing}
+	var r openMeteoAirQuality
+	if err := json.Unmarshal(body, &r); err != nil {
+		return models.AirQualityMessage{}, sel, fmt.Errorf("%s: %w", sel.Adapter, err)
+	}
+	if r.Current == nil {
+		return models.AirQualityMessage{}, sel, fmt.Errorf("%s: campo obrigatório ausente: current", sel.Adapter)
+	}
+	observed, err := models.ParseLocalTime(r.Current.Time, r.Timezone)
+	if err != nil {
+		return models.AirQualityMessage{}, sel, fmt.Errorf("%s: current.time: %w", sel.Adapter, err)
+	}
+
+	msg := models.AirQualityMessage{
+		Timestamp: observed.Format(time.RFC3339),
+		Location: models.WeatherLocation{
+			Latitude:  *r.Latitude,
+			Longitude: *r.Longitude,
+			Timezone:  r.Timezone,
+			Elevation: r.Elevation,
+		},
+		Current: *r.Current,
+		Source:  "open-meteo",
+	}
+	// current_units traz também time e interval, que não são poluentes
+	for _, pollutant := range aqi.Pollutants {
+		if unit, ok := r.CurrentUnits[pollutant]; ok {
+			if msg.Units == nil {
+				msg.Units = make(map[string]string)
+			}
+			msg.Units[pollutant] = unit
+		}
+	}
+	return msg, sel, nil
+}
@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"encoding/json"
+
+	"go-worker/internal/models"
+	"go-worker/internal/normalize"
+	"go-worker/internal/schema"
+)
+
+// ParamSchemaVersion é o parâmetro com a versão do schema da mensagem
+// canônica, no content type (application/json; schema-version=2) ou
+// informado pelo header x-schema-version
+const ParamSchemaVersion = "schema-version"
+
+// Canonical decodifica a mensagem no formato publicado pelo coletor Python,
+// convertendo versões anteriores do schema para a atual
+type Canonical struct {
+	// Strict rejeita campos desconhecidos e campos obrigatórios ausentes
+	Strict bool
+	// Lenient converte os números enviados como texto, inclusive com vírgula
+	// decimal, registrando os reparos na mensagem
+	Lenient bool
+}
+
+// Name implementa Adapter
+func (Canonical) Name() string { return "canonical" }
+
+// Detect reconhece location junto de current.temperature ou, nas mensagens
+// só de previsão, de hourly.time ou daily.time
+func (Canonical) Detect(fields map[string]json.RawMessage) bool {
+	if !hasObjectWith(fields, "location", "latitude") {
+		return false
+	}
+	return hasObjectWith(fields, "current", "temperature") ||
+		hasObjectWith(fields, "hourly", "time") || hasObjectWith(fields, "daily", "time")
+}
+
+// Decode implementa Adapter. Fora do modo estrito, campos ausentes ficam com
+// o valor zero e são tratados pela validação, como antes dos adaptadores.
+func (c Canonical) Decode(body []byte, params map[string]string) (models.WeatherMessage, error) {
+	opts := schema.Options{Strict: c.Strict}
+	if c.Lenient {
+		opts.Repair = func(doc map[string]any) []string {
+			return normalize.Flags(normalize.Document(doc))
+		}
+	}
+	return schema.Decode(body, params[ParamSchemaVersion], opts)
+}
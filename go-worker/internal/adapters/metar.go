@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"go-worker/internal/metar"
+	"go-worker/internal/models"
+)
+
+// Metar decodifica boletins METAR e SPECI em texto. A posição e a altitude
+// vêm da tabela de aeródromos embutida; a umidade é calculada a partir do
+// ponto de orvalho e o tempo presente vira um código WMO aproximado.
+type Metar struct {
+	// Now resolve o mês e o ano do boletim, que só informa dia e hora; nil usa time.Now
+	Now func() time.Time
+}
+
+// Name implementa Adapter
+func (Metar) Name() string { return "metar" }
+
+// Detect implementa Adapter; boletins em texto nunca chegam ao sniffing de JSON
+func (Metar) Detect(map[string]json.RawMessage) bool { return false }
+
+// MediaTypes implementa MediaTyper
+func (Metar) MediaTypes() []string { return []string{"text/plain"} }
+
+// Decode implementa Adapter
+func (a Metar) Decode(body []byte, _ map[string]string) (models.WeatherMessage, error) {
+	r, err := metar.Parse(string(body))
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	station, ok := metar.LookupStation(r.Station)
+	if !ok {
+		return models.WeatherMessage{}, fmt.Errorf("aeródromo %s fora da tabela de estações", r.Station)
+	}
+	if r.Temp == nil || r.DewPoint == nil {
+		return models.WeatherMessage{}, fmt.Errorf("campo obrigatório ausente: temperatura ou ponto de orvalho")
+	}
+	humidity, ok := models.RelativeHumidity(*r.Temp, *r.DewPoint)
+	if !ok {
+		return models.WeatherMessage{}, fmt.Errorf("temperatura %v e ponto de orvalho %v inconsistentes", *r.Temp, *r.DewPoint)
+	}
+
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+	observed := r.Time(now()).Format(time.RFC3339)
+	msg := models.WeatherMessage{
+		Timestamp: observed,
+		Source:    a.Name(),
+		Location: models.WeatherLocation{
+			Latitude:  station.Latitude,
+			Longitude: station.Longitude,
+			Timezone:  "UTC",
+			Elevation: models.Float64(station.Elevation),
+		},
+		Current: models.WeatherCurrent{
+			Temperature: *r.Temp,
+			Humidity:    math.Round(humidity*10) / 10,
+			DewPoint:    r.DewPoint,
+			Time:        observed,
+			WeatherCode: r.WMOCode(),
+		},
+		Units: make(map[string]string),
+	}
+
+	if w := r.Wind; w != nil {
+		msg.Current.WindSpeed = w.Speed
+		msg.Current.WindDirection = w.Direction
+		msg.Current.WindGusts = w.Gust
+		msg.Units["wind_speed"] = w.Unit
+		msg.Units["wind_gusts"] = w.Unit
+	}
+	switch {
+	case r.CAVOK:
+		// CAVOK: visibilidade de 10 km ou mais
+		msg.Current.Visibility = models.Float64(10000)
+	case r.Visibility != nil:
+		msg.Current.Visibility = models.Float64(r.Visibility.Distance)
+		msg.Units["visibility"] = r.Visibility.Unit
+	}
+	// A pressão reduzida das observações é preferida ao QNH, que é o ajuste do
+	// altímetro e só coincide com ela na atmosfera padrão
+	switch {
+	case r.SeaLevel != nil:
+		msg.Current.PressureMSL = r.SeaLevel
+	case r.QNH != nil:
+		msg.Current.PressureMSL = r.QNH
+	case r.Altimeter != nil:
+		msg.Current.PressureMSL = r.Altimeter
+		msg.Units["pressure_msl"] = "inHg"
+	}
+	if cover, ok := r.CloudCover(); ok {
+		msg.Current.CloudCover = models.Float64(cover)
+	}
+	if r.Precip != nil {
+		msg.Current.Precipitation = r.Precip
+		msg.Units["precipitation"] = "inch"
+	}
+	return msg, nil
+}
@@ -0,0 +1,219 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// openMeteoFields liga as variáveis de current da Open-Meteo aos campos canônicos
+var openMeteoFields = map[string]string{
+	"temperature_2m":       "temperature",
+	"dew_point_2m":         "dew_point",
+	"relative_humidity_2m": "humidity",
+	"wind_speed_10m":       "wind_speed",
+	"wind_gusts_10m":       "wind_gusts",
+	"precipitation":        "precipitation",
+	"surface_pressure":     "surface_pressure",
+	"pressure_msl":         "pressure_msl",
+	"visibility":           "visibility",
+}
+
+// openMeteoHourlyFields liga as variáveis de hourly aos nomes canônicos
+var openMeteoHourlyFields = map[string]string{
+	"temperature_2m":       "temperature",
+	"dew_point_2m":         "dew_point",
+	"relative_humidity_2m": "humidity",
+	"weather_code":         "weather_code",
+	"wind_speed_10m":       "wind_speed",
+	"wind_direction_10m":   "wind_direction",
+	"wind_gusts_10m":       "wind_gusts",
+	"precipitation":        "precipitation",
+	"surface_pressure":     "surface_pressure",
+	"pressure_msl":         "pressure_msl",
+	"cloud_cover":          "cloud_cover",
+	"visibility":           "visibility",
+	"uv_index":             "uv_index",
+}
+
+// openMeteoDailyFields liga as variáveis de daily aos nomes canônicos; as
+// médias diárias ocupam os campos de current
+var openMeteoDailyFields = map[string]string{
+	"temperature_2m_max":          "temperature_max",
+	"temperature_2m_min":          "temperature_min",
+	"temperature_2m_mean":         "temperature",
+	"dew_point_2m_mean":           "dew_point",
+	"relative_humidity_2m_mean":   "humidity",
+	"weather_code":                "weather_code",
+	"wind_speed_10m_max":          "wind_speed",
+	"wind_direction_10m_dominant": "wind_direction",
+	"wind_gusts_10m_max":          "wind_gusts",
+	"precipitation_sum":           "precipitation",
+	"surface_pressure_mean":       "surface_pressure",
+	"pressure_msl_mean":           "pressure_msl",
+	"cloud_cover_mean":            "cloud_cover",
+	"uv_index_max":                "uv_index",
+}
+
+type openMeteoResponse struct {
+	Latitude     *float64          `json:"latitude"`
+	Longitude    *float64          `json:"longitude"`
+	Elevation    *float64          `json:"elevation"`
+	Timezone     string            `json:"timezone"`
+	CurrentUnits map[string]string `json:"current_units"`
+	Current      *struct {
+		Time          string   `json:"time"`
+		Temperature   *float64 `json:"temperature_2m"`
+		Humidity      *float64 `json:"relative_humidity_2m"`
+		DewPoint      *float64 `json:"dew_point_2m"`
+		WeatherCode   *int     `json:"weather_code"`
+		WindSpeed     *float64 `json:"wind_speed_10m"`
+		WindDirection *float64 `json:"wind_direction_10m"`
+		WindGusts     *float64 `json:"wind_gusts_10m"`
+		Precipitation *float64 `json:"precipitation"`
+		Surface       *float64 `json:"surface_pressure"`
+		MSL           *float64 `json:"pressure_msl"`
+		CloudCover    *float64 `json:"cloud_cover"`
+		Visibility    *float64 `json:"visibility"`
+		UVIndex       *float64 `json:"uv_index"`
+	} `json:"current"`
+	Hourly      map[string]json.RawMessage `json:"hourly"`
+	HourlyUnits map[string]string          `json:"hourly_units"`
+	Daily       map[string]json.RawMessage `json:"daily"`
+	DailyUnits  map[string]string          `json:"daily_units"`
+}
+
+// OpenMeteo decodifica a resposta bruta de /v1/forecast com os blocos
+// current, hourly e daily
+type OpenMeteo struct{}
+
+// Name implementa Adapter
+func (OpenMeteo) Name() string { return "open-meteo" }
+
+// Detect reconhece current.temperature_2m ou, nas respostas só de previsão,
+// hourly.time ou daily.time com latitude no primeiro nível
+func (OpenMeteo) Detect(fields map[string]json.RawMessage) bool {
+	if hasObjectWith(fields, "current", "temperature_2m") {
+		return true
+	}
+	return has(fields, "latitude", "longitude") &&
+		(hasObjectWith(fields, "hourly", "time") || hasObjectWith(fields, "daily", "time"))
+}
+
+// Decode implementa Adapter. current.time e os horários das séries são hora
+// local do fuso da resposta; current_units, hourly_units e daily_units viram
+// o bloco units da mensagem canônica. Sem current, a mensagem traz apenas as
+// previsões.
+func (a OpenMeteo) Decode(body []byte, _ map[string]string) (models.WeatherMessage, error) {
+	var r openMeteoResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	if r.Latitude == nil || r.Longitude == nil || (r.Current == nil && r.Hourly == nil && r.Daily == nil) {
+		return models.WeatherMessage{}, fmt.Errorf("campo obrigatório ausente: latitude, longitude ou current/hourly/daily")
+	}
+
+	msg := models.WeatherMessage{
+		Source: a.Name(),
+		Location: models.WeatherLocation{
+			Latitude:  *r.Latitude,
+			Longitude: *r.Longitude,
+			Timezone:  r.Timezone,
+			Elevation: r.Elevation,
+		},
+	}
+	if r.Current != nil {
+		if err := a.decodeCurrent(&r, &msg); err != nil {
+			return models.WeatherMessage{}, err
+		}
+	}
+
+	var err error
+	if msg.Hourly, err = openMeteoSeries("hourly", r.Hourly, openMeteoHourlyFields); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	if msg.Daily, err = openMeteoSeries("daily", r.Daily, openMeteoDailyFields); err != nil {
+		return models.WeatherMessage{}, err
+	}
+
+	for _, block := range []struct {
+		units  map[string]string
+		fields map[string]string
+	}{{r.CurrentUnits, openMeteoFields}, {r.HourlyUnits, openMeteoHourlyFields}, {r.DailyUnits, openMeteoDailyFields}} {
+		for apiField, unit := range block.units {
+			if field, ok := block.fields[apiField]; ok {
+				if msg.Units == nil {
+					msg.Units = make(map[string]string)
+				}
+				msg.Units[field] = unit
+			}
+		}
+	}
+	return msg, nil
+}
+
+// decodeCurrent preenche a observação do bloco current
+func (a OpenMeteo) decodeCurrent(r *openMeteoResponse, msg *models.WeatherMessage) error {
+	c := r.Current
+	temperature, err := required("current.temperature_2m", c.Temperature)
+	if err != nil {
+		return err
+	}
+	humidity, err := required("current.relative_humidity_2m", c.Humidity)
+	if err != nil {
+		return err
+	}
+	observed, err := models.ParseLocalTime(c.Time, r.Timezone)
+	if err != nil {
+		return fmt.Errorf("current.time: %w", err)
+	}
+
+	msg.Timestamp = observed.Format(time.RFC3339)
+	msg.Current = models.WeatherCurrent{
+		Temperature:     temperature,
+		Humidity:        humidity,
+		Time:            c.Time,
+		Precipitation:   c.Precipitation,
+		SurfacePressure: c.Surface,
+		PressureMSL:     c.MSL,
+		Visibility:      c.Visibility,
+		UVIndex:         c.UVIndex,
+		WindDirection:   c.WindDirection,
+		WindGusts:       c.WindGusts,
+		CloudCover:      c.CloudCover,
+		DewPoint:        c.DewPoint,
+	}
+	if c.WindSpeed != nil {
+		msg.Current.WindSpeed = *c.WindSpeed
+	}
+	if c.WeatherCode != nil {
+		msg.Current.WeatherCode = *c.WeatherCode
+	}
+	return nil
+}
+
+// openMeteoSeries converte um bloco hourly ou daily; variáveis sem nome
+// canônico (sunrise, daylight_duration, ...) são ignoradas
+func openMeteoSeries(block string, raw map[string]json.RawMessage, fields map[string]string) (*models.ForecastSeries, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	series := &models.ForecastSeries{Values: make(map[string][]*float64)}
+	if err := json.Unmarshal(raw["time"], &series.Time); err != nil {
+		return nil, fmt.Errorf("%s.time: %w", block, err)
+	}
+	for apiField, field := range fields {
+		value, ok := raw[apiField]
+		if !ok {
+			continue
+		}
+		var values []*float64
+		if err := json.Unmarshal(value, &values); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", block, apiField, err)
+		}
+		series.Values[field] = values
+	}
+	return series, nil
+}
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// Unidades da OpenWeatherMap por valor do parâmetro units da API
+var openWeatherMapUnits = map[string]map[string]string{
+	"standard": {"temperature": "K", "wind_speed": "m/s", "wind_gusts": "m/s"},
+	"metric":   {"temperature": "°C", "wind_speed": "m/s", "wind_gusts": "m/s"},
+	"imperial": {"temperature": "°F", "wind_speed": "mph", "wind_gusts": "mph"},
+}
+
+type openWeatherMapResponse struct {
+	Coord *struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	} `json:"coord"`
+	Weather []struct {
+		ID int `json:"id"`
+	} `json:"weather"`
+	Main *struct {
+		Temp      *float64 `json:"temp"`
+		Pressure  *float64 `json:"pressure"`
+		Humidity  *float64 `json:"humidity"`
+		SeaLevel  *float64 `json:"sea_level"`
+		GrndLevel *float64 `json:"grnd_level"`
+	} `json:"main"`
+	Visibility *float64 `json:"visibility"`
+	Wind       *struct {
+		Speed *float64 `json:"speed"`
+		Deg   *float64 `json:"deg"`
+		Gust  *float64 `json:"gust"`
+	} `json:"wind"`
+	Clouds *struct {
+		All *float64 `json:"all"`
+	} `json:"clouds"`
+	Rain map[string]float64 `json:"rain"`
+	Snow map[string]float64 `json:"snow"`
+	Dt   int64              `json:"dt"`
+}
+
+// OpenWeatherMap decodifica a resposta de /data/2.5/weather. A resposta não
+// informa as unidades: vale o parâmetro units do content type
+// (application/vnd.openweathermap+json; units=metric), padrão standard, o
+// mesmo da API.
+type OpenWeatherMap struct{}
+
+// Name implementa Adapter
+func (OpenWeatherMap) Name() string { return "openweathermap" }
+
+// Detect reconhece coord junto de main
+func (OpenWeatherMap) Detect(fields map[string]json.RawMessage) bool {
+	return has(fields, "coord", "main")
+}
+
+// Decode implementa Adapter
+func (a OpenWeatherMap) Decode(body []byte, params map[string]string) (models.WeatherMessage, error) {
+	system := "standard"
+	if u := params["units"]; u != "" {
+		system = u
+	}
+	units, ok := openWeatherMapUnits[system]
+	if !ok {
+		return models.WeatherMessage{}, fmt.Errorf("units %q desconhecido (use standard, metric ou imperial)", system)
+	}
+
+	var r openWeatherMapResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	if r.Coord == nil || r.Main == nil || r.Dt == 0 {
+		return models.WeatherMessage{}, fmt.Errorf("campo obrigatório ausente: coord, main ou dt")
+	}
+	temperature, err := required("main.temp", r.Main.Temp)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	humidity, err := required("main.humidity", r.Main.Humidity)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+
+	observed := time.Unix(r.Dt, 0).UTC().Format(time.RFC3339)
+	msg := models.WeatherMessage{
+		Timestamp: observed,
+		Source:    a.Name(),
+		Location:  models.WeatherLocation{Latitude: r.Coord.Lat, Longitude: r.Coord.Lon},
+		Current: models.WeatherCurrent{
+			Temperature:     temperature,
+			Humidity:        humidity,
+			Time:            observed,
+			SurfacePressure: r.Main.GrndLevel,
+			Visibility:      r.Visibility,
+		},
+		Units: make(map[string]string, len(units)),
+	}
+	for field, unit := range units {
+		msg.Units[field] = unit
+	}
+
+	// main.pressure é ao nível do mar; sea_level, quando presente, é o mesmo valor
+	msg.Current.PressureMSL = r.Main.SeaLevel
+	if msg.Current.PressureMSL == nil {
+		msg.Current.PressureMSL = r.Main.Pressure
+	}
+	if r.Wind != nil {
+		if r.Wind.Speed != nil {
+			msg.Current.WindSpeed = *r.Wind.Speed
+		}
+		msg.Current.WindDirection = r.Wind.Deg
+		msg.Current.WindGusts = r.Wind.Gust
+	}
+	if r.Clouds != nil {
+		msg.Current.CloudCover = r.Clouds.All
+	}
+	// rain e snow trazem o acumulado da última hora em mm
+	if rain, snow := r.Rain["1h"], r.Snow["1h"]; r.Rain != nil || r.Snow != nil {
+		msg.Current.Precipitation = models.Float64(rain + snow)
+	}
+	if len(r.Weather) > 0 {
+		msg.Current.WeatherCode = openWeatherMapToWMO(r.Weather[0].ID)
+	}
+	return msg, nil
+}
+
+// openWeatherMapToWMO converte os códigos de condição da OpenWeatherMap para a
+// tabela WMO. Fenômenos sem equivalente (fumaça, poeira, cinzas) viram
+// encoberto; códigos desconhecidos seguem adiante e são rejeitados pela
+// validação, que só aceita a faixa WMO.
+func openWeatherMapToWMO(id int) int {
+	switch {
+	case id >= 200 && id < 300:
+		return 95
+	case id == 300 || id == 310:
+		return 51
+	case id == 302 || id == 312 || id == 314:
+		return 55
+	case id >= 300 && id < 400:
+		return 53
+	case id == 500:
+		return 61
+	case id == 501:
+		return 63
+	case id >= 502 && id <= 504:
+		return 65
+	case id == 511:
+		return 66
+	case id == 520:
+		return 80
+	case id == 521:
+		return 81
+	case id == 522 || id == 531:
+		return 82
+	case id == 600:
+		return 71
+	case id == 601 || id == 615 || id == 616:
+		return 73
+	case id == 602:
+		return 75
+	case id >= 611 && id <= 613:
+		return 77
+	case id == 620:
+		return 85
+	case id == 621 || id == 622:
+		return 86
+	case id == 701 || id == 741:
+		return 45
+	case id >= 700 && id < 800:
+		return 3
+	case id == 800:
+		return 0
+	case id == 801:
+		return 1
+	case id == 802:
+		return 2
+	case id == 803 || id == 804:
+		return 3
+	default:
+		return id
+	}
+}
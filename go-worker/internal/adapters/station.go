@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// stationUnitFields renomeia as chaves de units que diferem da mensagem canônica
+var stationUnitFields = map[string]string{
+	"pressure":  "surface_pressure",
+	"wind_gust": "wind_gusts",
+}
+
+type stationReport struct {
+	StationID     string            `json:"station_id"`
+	Latitude      *float64          `json:"latitude"`
+	Longitude     *float64          `json:"longitude"`
+	Elevation     *float64          `json:"elevation"`
+	Timezone      string            `json:"timezone"`
+	ObservedAt    string            `json:"observed_at"`
+	Temperature   *float64          `json:"temperature"`
+	Humidity      *float64          `json:"humidity"`
+	DewPoint      *float64          `json:"dew_point"`
+	Pressure      *float64          `json:"pressure"`
+	PressureMSL   *float64          `json:"pressure_msl"`
+	WindSpeed     *float64          `json:"wind_speed"`
+	WindDirection *float64          `json:"wind_direction"`
+	WindGust      *float64          `json:"wind_gust"`
+	Precipitation *float64          `json:"precipitation"`
+	Visibility    *float64          `json:"visibility"`
+	CloudCover    *float64          `json:"cloud_cover"`
+	UVIndex       *float64          `json:"uv_index"`
+	WeatherCode   *int              `json:"weather_code"`
+	Units         map[string]string `json:"units"`
+}
+
+// Station decodifica o JSON plano de estações automáticas próprias:
+//
+//	{"station_id": "A701", "latitude": -23.50, "longitude": -46.62,
+//	 "observed_at": "2025-06-15T14:30:00Z", "temperature": 25.5, "humidity": 65,
+//	 "pressure": 925.1, "wind_speed": 3.2, "units": {"wind_speed": "m/s"}}
+//
+// pressure é a pressão na estação; observed_at sem offset está em timezone.
+// As chaves de units seguem os nomes deste formato.
+type Station struct{}
+
+// Name implementa Adapter
+func (Station) Name() string { return "station" }
+
+// Detect reconhece station_id junto de observed_at
+func (Station) Detect(fields map[string]json.RawMessage) bool {
+	return has(fields, "station_id", "observed_at")
+}
+
+// Decode implementa Adapter
+func (a Station) Decode(body []byte, _ map[string]string) (models.WeatherMessage, error) {
+	var r stationReport
+	if err := json.Unmarshal(body, &r); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	if r.Latitude == nil || r.Longitude == nil {
+		return models.WeatherMessage{}, fmt.Errorf("campo obrigatório ausente: latitude ou longitude")
+	}
+	temperature, err := required("temperature", r.Temperature)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	humidity, err := required("humidity", r.Humidity)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	observed, err := models.ParseLocalTime(r.ObservedAt, r.Timezone)
+	if err != nil {
+		return models.WeatherMessage{}, fmt.Errorf("observed_at: %w", err)
+	}
+
+	msg := models.WeatherMessage{
+		Timestamp: observed.Format(time.RFC3339),
+		Source:    a.Name(),
+		Location: models.WeatherLocation{
+			Latitude:  *r.Latitude,
+			Longitude: *r.Longitude,
+			Timezone:  r.Timezone,
+			Elevation: r.Elevation,
+		},
+		Current: models.WeatherCurrent{
+			Temperature:     temperature,
+			Humidity:        humidity,
+			Time:            r.ObservedAt,
+			Precipitation:   r.Precipitation,
+			SurfacePressure: r.Pressure,
+			PressureMSL:     r.PressureMSL,
+			Visibility:      r.Visibility,
+			UVIndex:         r.UVIndex,
+			WindDirection:   r.WindDirection,
+			WindGusts:       r.WindGust,
+			CloudCover:      r.CloudCover,
+			DewPoint:        r.DewPoint,
+		},
+	}
+	if r.WindSpeed != nil {
+		msg.Current.WindSpeed = *r.WindSpeed
+	}
+	if r.WeatherCode != nil {
+		msg.Current.WeatherCode = *r.WeatherCode
+	}
+	for field, unit := range r.Units {
+		if msg.Units == nil {
+			msg.Units = make(map[string]string, len(r.Units))
+		}
+		if canonical, ok := stationUnitFields[field]; ok {
+			field = canonical
+		}
+		msg.Units[field] = unit
+	}
+	return msg, nil
+}
@@ -0,0 +1,113 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+type weatherAPIResponse struct {
+	Location *struct {
+		Lat  float64 `json:"lat"`
+		Lon  float64 `json:"lon"`
+		TzID string  `json:"tz_id"`
+	} `json:"location"`
+	Current *struct {
+		LastUpdatedEpoch int64    `json:"last_updated_epoch"`
+		TempC            *float64 `json:"temp_c"`
+		Humidity         *float64 `json:"humidity"`
+		DewpointC        *float64 `json:"dewpoint_c"`
+		WindKph          *float64 `json:"wind_kph"`
+		WindDegree       *float64 `json:"wind_degree"`
+		GustKph          *float64 `json:"gust_kph"`
+		PressureMb       *float64 `json:"pressure_mb"`
+		PrecipMm         *float64 `json:"precip_mm"`
+		Cloud            *float64 `json:"cloud"`
+		VisKm            *float64 `json:"vis_km"`
+		UV               *float64 `json:"uv"`
+		Condition        *struct {
+			Code int `json:"code"`
+		} `json:"condition"`
+	} `json:"current"`
+}
+
+// WeatherAPI decodifica a resposta de /v1/current.json da WeatherAPI.com,
+// usando os campos métricos
+type WeatherAPI struct{}
+
+// Name implementa Adapter
+func (WeatherAPI) Name() string { return "weatherapi" }
+
+// Detect reconhece current.temp_c
+func (WeatherAPI) Detect(fields map[string]json.RawMessage) bool {
+	return hasObjectWith(fields, "current", "temp_c")
+}
+
+// Decode implementa Adapter
+func (a WeatherAPI) Decode(body []byte, _ map[string]string) (models.WeatherMessage, error) {
+	var r weatherAPIResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	if r.Location == nil || r.Current == nil || r.Current.LastUpdatedEpoch == 0 {
+		return models.WeatherMessage{}, fmt.Errorf("campo obrigatório ausente: location, current ou current.last_updated_epoch")
+	}
+	c := r.Current
+	temperature, err := required("current.temp_c", c.TempC)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	humidity, err := required("current.humidity", c.Humidity)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+
+	observed := time.Unix(c.LastUpdatedEpoch, 0).UTC().Format(time.RFC3339)
+	msg := models.WeatherMessage{
+		Timestamp: observed,
+		Source:    a.Name(),
+		Location: models.WeatherLocation{
+			Latitude:  r.Location.Lat,
+			Longitude: r.Location.Lon,
+			Timezone:  r.Location.TzID,
+		},
+		Current: models.WeatherCurrent{
+			Temperature:   temperature,
+			Humidity:      humidity,
+			Time:          observed,
+			Precipitation: c.PrecipMm,
+			PressureMSL:   c.PressureMb,
+			Visibility:    c.VisKm,
+			UVIndex:       c.UV,
+			WindDirection: c.WindDegree,
+			WindGusts:     c.GustKph,
+			CloudCover:    c.Cloud,
+			DewPoint:      c.DewpointC,
+		},
+		Units: map[string]string{"visibility": "km"},
+	}
+	if c.WindKph != nil {
+		msg.Current.WindSpeed = *c.WindKph
+	}
+	if c.Condition != nil {
+		// Códigos fora da tabela seguem adiante e são rejeitados pela validação
+		msg.Current.WeatherCode = c.Condition.Code
+		if code, ok := weatherAPIToWMO[c.Condition.Code]; ok {
+			msg.Current.WeatherCode = code
+		}
+	}
+	return msg, nil
+}
+
+// weatherAPIToWMO converte os códigos de condição da WeatherAPI.com para a
+// tabela WMO. Granizo miúdo e chuva com neve (sleet) viram chuva congelante.
+var weatherAPIToWMO = map[int]int{
+	1000: 0, 1003: 2, 1006: 3, 1009: 3, 1030: 45, 1063: 61, 1066: 71, 1069: 66,
+	1072: 56, 1087: 95, 1114: 73, 1117: 75, 1135: 45, 1147: 48, 1150: 51, 1153: 51,
+	1168: 56, 1171: 57, 1180: 61, 1183: 61, 1186: 63, 1189: 63, 1192: 65, 1195: 65,
+	1198: 66, 1201: 67, 1204: 66, 1207: 67, 1210: 71, 1213: 71, 1216: 73, 1219: 73,
+	1222: 75, 1225: 75, 1237: 77, 1240: 80, 1243: 81, 1246: 82, 1249: 85, 1252: 86,
+	1255: 85, 1258: 86, 1261: 77, 1264: 77, 1273: 95, 1276: 95, 1279: 95, 1282: 95,
+}
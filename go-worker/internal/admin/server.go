@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"go-worker/internal/client"
+	"go-worker/internal/normalize"
+	"go-worker/internal/processor"
+)
+
+// maxBodySize limita o tamanho das mensagens enviadas ao endpoint /explain
+const maxBodySize = 1 << 20
+
+// Server expõe endpoints administrativos do worker
+type Server struct {
+	httpServer      *http.Server
+	proc            *processor.Processor
+	defaultContract string
+}
+
+// NewServer cria o servidor administrativo no endereço informado
+func NewServer(addr string, proc *processor.Processor, defaultContract string) *Server {
+	s := &Server{
+		proc:            proc,
+		defaultContract: defaultContract,
+	}
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handler retorna as rotas administrativas:
+//
+//	GET  /healthz                 verificação de vida
+//	POST /explain?backend=v2      executa o pipeline em modo explain sobre o corpo
+//	GET  /debug/vars              métricas dos reparos da normalização (expvar)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/explain", s.handleExplain)
+	mux.HandleFunc("/debug/vars", handleVars)
+	return mux
+}
+
+// Start atende requisições até o contexto ser cancelado
+func (s *Server) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[WARN] Erro ao encerrar servidor administrativo: %v", err)
+		}
+	}()
+
+	log.Printf("[INFO] Servidor administrativo ouvindo em %s", s.httpServer.Addr)
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleVars publica só as métricas da normalização, no formato do expvar. O
+// expvar.Handler exporia também cmdline, com as credenciais passadas em
+// -rabbitmq-url, e memstats.
+func handleVars(w http.ResponseWriter, r *http.Request) {
+	vars := make(map[string]json.RawMessage)
+	for _, name := range normalize.Vars() {
+		if v := expvar.Get(name); v != nil {
+			vars[name] = json.RawMessage(v.String())
+		}
+	}
+	writeJSON(w, http.StatusOK, vars)
+}
+
+func (s *Server) handleExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "use POST com a mensagem no corpo")
+		return
+	}
+
+	version := r.URL.Query().Get("backend")
+	if version == "" {
+		version = s.defaultContract
+	}
+	contract, err := client.LookupContract(version)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	// X-Message-Type e X-Routing-Key fazem o papel da propriedade AMQP type e
+	// da routing key na escolha do tipo e do adaptador
+	msg := processor.Message{
+		Body:        body,
+		Type:        r.Header.Get("X-Message-Type"),
+		ContentType: r.Header.Get("Content-Type"),
+		RoutingKey:  r.Header.Get("X-Routing-Key"),
+	}
+	msg.Headers = make(map[string]string)
+	if lang := r.Header.Get("X-Language"); lang != "" {
+		msg.Headers[processor.HeaderLanguage] = lang
+	}
+	if version := r.Header.Get("X-Schema-Version"); version != "" {
+		msg.Headers[processor.HeaderSchemaVersion] = version
+	}
+	writeJSON(w, http.StatusOK, s.proc.ExplainMessage(msg, contract))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[WARN] Erro ao escrever resposta: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
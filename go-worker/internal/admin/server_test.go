@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-worker/internal/client"
+	"go-worker/internal/models"
+	"go-worker/internal/processor"
+)
+
+type failingSender struct {
+	t *testing.T
+}
+
+func (s failingSender) SendWeatherLog(models.WeatherLog) error {
+	s.t.Error("explain must not send")
+	return nil
+}
+
+func TestServer_Explain(t *testing.T) {
+	proc := processor.NewProcessor(failingSender{t})
+	handler := NewServer(":0", proc, client.DefaultContractVersion).Handler()
+
+	t.Run("accepted message", func(t *testing.T) {
+		body := `{"timestamp":"2025-06-15T14:30:00","location":{"latitude":-23.5505,"longitude":-46.6333},"current":{"temperature":25.5,"humidity":65,"weather_code":0}}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/explain?backend="+client.DefaultContractVersion, strings.NewReader(body)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		var trace processor.Trace
+		if err := json.Unmarshal(rec.Body.Bytes(), &trace); err != nil {
+			t.Fatalf("invalid trace: %v", err)
+		}
+		if trace.Outcome != processor.OutcomeAccepted {
+			t.Errorf("Outcome = %v, want accepted", trace.Outcome)
+		}
+		if trace.Contract == nil || !trace.Contract.Compatible {
+			t.Errorf("Contract = %+v, want compatible", trace.Contract)
+		}
+
+		rules := 0
+		for _, step := range trace.Steps {
+			if step.Stage == processor.StageValidate {
+				rules++
+			}
+		}
+		if rules != len((&models.WeatherMessage{}).CheckRules()) {
+			t.Errorf("validation steps = %d, want one per rule", rules)
+		}
+	})
+
+	t.Run("language header", func(t *testing.T) {
+		body := `{"timestamp":"2025-06-15T14:30:00","location":{"latitude":-23.5505,"longitude":-46.6333},"current":{"temperature":25.5,"humidity":65,"weather_code":61}}`
+		req := httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(body))
+		req.Header.Set("X-Language", "es")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var trace processor.Trace
+		if err := json.Unmarshal(rec.Body.Bytes(), &trace); err != nil {
+			t.Fatalf("invalid trace: %v", err)
+		}
+		if trace.Payload == nil || trace.Payload.Description != "Lluvia ligera" {
+			t.Errorf("Payload = %+v, want description in Spanish", trace.Payload)
+		}
+	})
+
+	t.Run("rejected message keeps every rule", func(t *testing.T) {
+		body := `{"timestamp":"","location":{"latitude":-123,"longitude":-46.6},"current":{"temperature":25.5,"humidity":165}}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(body)))
+
+		var trace processor.Trace
+		json.Unmarshal(rec.Body.Bytes(), &trace)
+		if trace.Outcome != processor.OutcomeRejected || trace.Stage != processor.StageValidate {
+			t.Errorf("Outcome = %v, Stage = %v, want rejected at validate", trace.Outcome, trace.Stage)
+		}
+		failed := 0
+		for _, step := range trace.Steps {
+			if step.Decision == "fail" {
+				failed++
+			}
+		}
+		if failed != 3 {
+			t.Errorf("failed rules = %d, want 3", failed)
+		}
+	})
+
+	t.Run("unknown backend version", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/explain?backend=v14", strings.NewReader("{}")))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/explain", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want 405", rec.Code)
+		}
+	})
+}
+
+func TestServer_Vars(t *testing.T) {
+	handler := NewServer(":0", processor.NewProcessor(failingSender{t}), client.DefaultContractVersion).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("resposta inválida: %v", err)
+	}
+	for _, name := range []string{"normalize_repairs", "normalize_repairs_by_source", "normalize_repaired_messages"} {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("vars sem %s", name)
+		}
+	}
+	for _, name := range []string{"cmdline", "memstats"} {
+		if _, ok := vars[name]; ok {
+			t.Errorf("vars expõe %s", name)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package anomaly aprende o comportamento normal de cada estação por hora do
+// dia e pontua cada leitura pelo z-score em relação a essa linha de base.
+// Média e variância são médias móveis exponenciais (EWMA); leituras acima do
+// limiar, passado o aquecimento, viram eventos de anomalia.
+package anomaly
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// Config define a sensibilidade do detector
+type Config struct {
+	// Alpha é o peso de cada leitura nova na EWMA (0 a 1)
+	Alpha float64
+	// Threshold é o |z-score| a partir do qual a leitura é anômala
+	Threshold float64
+	// WarmUp é o número de leituras de uma hora do dia antes de emitir
+	// eventos para ela; até lá a linha de base só aprende
+	WarmUp int
+	// Recent é quantas leituras recentes da estação acompanham o evento
+	Recent int
+	// MinStdDev é o desvio padrão mínimo por variável, para que uma hora
+	// muito estável não transforme qualquer variação em anomalia
+	MinStdDev map[string]float64
+}
+
+// DefaultConfig aprende com peso 0,1 (meia-vida de ~7 leituras por hora do
+// dia) e exige 14 leituras de cada hora antes de alertar
+func DefaultConfig() Config {
+	return Config{
+		Alpha:     0.1,
+		Threshold: 4,
+		WarmUp:    14,
+		Recent:    6,
+		MinStdDev: map[string]float64{
+			"temperature":      0.5,
+			"humidity":         2,
+			"surface_pressure": 0.5,
+			"pressure_msl":     0.5,
+			"wind_speed":       2,
+		},
+	}
+}
+
+// variables são as grandezas acompanhadas, nas unidades do worker
+var variables = []struct {
+	name  string
+	value func(w *models.WeatherMessage) *float64
+}{
+	{"temperature", func(w *models.WeatherMessage) *float64 { return &w.Current.Temperature }},
+	{"humidity", func(w *models.WeatherMessage) *float64 { return &w.Current.Humidity }},
+	{"surface_pressure", func(w *models.WeatherMessage) *float64 { return w.Current.SurfacePressure }},
+	{"pressure_msl", func(w *models.WeatherMessage) *float64 { return w.Current.PressureMSL }},
+	{"wind_speed", func(w *models.WeatherMessage) *float64 { return &w.Current.WindSpeed }},
+}
+
+// Baseline é a linha de base de uma variável numa hora do dia
+type Baseline struct {
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+	Count    int     `json:"count"`
+}
+
+// Reading é uma leitura recente da estação
+type Reading struct {
+	Time   time.Time          `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+// station guarda as linhas de base (24 horas por variável) e as leituras recentes
+type station struct {
+	Last      time.Time                `json:"last"`
+	Baselines map[string]*[24]Baseline `json:"baselines"`
+	Recent    []Reading                `json:"recent,omitempty"`
+}
+
+// Score é a pontuação de uma variável
+type Score struct {
+	Variable  string  `json:"variable"`
+	Hour      int     `json:"hour"`
+	Value     float64 `json:"value"`
+	Mean      float64 `json:"mean"`
+	StdDev    float64 `json:"stdDev"`
+	ZScore    float64 `json:"zScore"`
+	Samples   int     `json:"samples"`
+	WarmingUp bool    `json:"warmingUp,omitempty"`
+	Anomalous bool    `json:"anomalous"`
+}
+
+// Event descreve uma leitura anômala com o contexto para investigação
+type Event struct {
+	Station   string    `json:"station"`
+	Source    string    `json:"source"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Time      time.Time `json:"time"`
+	Score
+	// Expected é a faixa considerada normal: média ± Threshold desvios
+	Expected [2]float64 `json:"expected"`
+	// Recent são as leituras anteriores da variável, da mais antiga para a mais nova
+	Recent []RecentValue `json:"recent,omitempty"`
+}
+
+// RecentValue é uma leitura anterior de uma variável
+type RecentValue struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Result reúne as pontuações de uma leitura e os eventos emitidos
+type Result struct {
+	Scores []Score
+	Events []Event
+	// Flags são as variáveis anômalas, no formato anomaly:<variável>
+	Flags []string
+}
+
+// Detector mantém as linhas de base por estação. É seguro para uso concorrente.
+type Detector struct {
+	cfg Config
+
+	mu       sync.Mutex
+	stations map[string]*station
+}
+
+// NewDetector cria um detector sem histórico
+func NewDetector(cfg Config) *Detector {
+	return &Detector{cfg: cfg, stations: make(map[string]*station)}
+}
+
+// SolarHour é a hora solar local aproximada pela longitude, que acompanha o
+// ciclo diário melhor que o fuso e não depende de location.timezone
+func SolarHour(t time.Time, longitude float64) int {
+	offset := time.Duration(longitude / 15 * float64(time.Hour))
+	return t.UTC().Add(offset).Hour()
+}
+
+// Pending é uma leitura pontuada por Score, ainda fora da linha de base.
+// Commit a incorpora depois do envio do registro.
+type Pending struct {
+	station string
+	hour    int
+	reading Reading
+}
+
+// Score pontua a leitura na linha de base da estação sem alterá-la. Só
+// leituras posteriores à última registrada emitem eventos; reentregas e
+// leituras atrasadas são apenas pontuadas.
+func (d *Detector) Score(msg *models.WeatherMessage, observed time.Time) (Result, Pending) {
+	key := msg.StationKey()
+	hour := SolarHour(observed, msg.Location.Longitude)
+	current := Reading{Time: observed.UTC(), Values: make(map[string]float64, len(variables))}
+	for _, v := range variables {
+		if p := v.value(msg); p != nil {
+			current.Values[v.name] = *p
+		}
+	}
+	pending := Pending{station: key, hour: hour, reading: current}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.stations[key]
+	if st == nil {
+		st = &station{Baselines: make(map[string]*[24]Baseline)}
+	}
+	fresh := current.Time.After(st.Last)
+
+	var result Result
+	for _, v := range variables {
+		value, ok := current.Values[v.name]
+		if !ok {
+			continue
+		}
+		var baseline Baseline
+		if buckets := st.Baselines[v.name]; buckets != nil {
+			baseline = buckets[hour]
+		}
+		score := d.score(v.name, hour, value, baseline)
+		result.Scores = append(result.Scores, score)
+
+		if score.Anomalous {
+			result.Flags = append(result.Flags, "anomaly:"+v.name)
+			if fresh {
+				result.Events = append(result.Events, d.event(msg, key, current.Time, score, st.Recent))
+			}
+		}
+	}
+	return result, pending
+}
+
+// Commit incorpora à linha de base a leitura pontuada por Score e informa se
+// ela foi registrada. Leituras com horário igual ou anterior à última da
+// estação (reentregas, atrasadas, Commit repetido) não alteram a linha de base.
+func (d *Detector) Commit(p Pending) bool {
+	if p.station == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.stations[p.station]
+	if st == nil {
+		st = &station{Baselines: make(map[string]*[24]Baseline)}
+		d.stations[p.station] = st
+	}
+	if !p.reading.Time.After(st.Last) {
+		return false
+	}
+
+	for _, v := range variables {
+		value, ok := p.reading.Values[v.name]
+		if !ok {
+			continue
+		}
+		buckets := st.Baselines[v.name]
+		if buckets == nil {
+			buckets = &[24]Baseline{}
+			st.Baselines[v.name] = buckets
+		}
+		score := d.score(v.name, p.hour, value, buckets[p.hour])
+		buckets[p.hour] = d.update(buckets[p.hour], value, score.StdDev)
+	}
+
+	st.Last = p.reading.Time
+	st.Recent = append(st.Recent, p.reading)
+	if n := len(st.Recent) - d.cfg.Recent; n > 0 {
+		st.Recent = append([]Reading(nil), st.Recent[n:]...)
+	}
+	return true
+}
+
+// Observe é Score seguido de Commit, para quem não depende de um envio; os
+// eventos só acompanham leituras registradas
+func (d *Detector) Observe(msg *models.WeatherMessage, observed time.Time) Result {
+	result, pending := d.Score(msg, observed)
+	if !d.Commit(pending) {
+		result.Events = nil
+	}
+	return result
+}
+
+// score calcula o z-score da leitura na linha de base da hora
+func (d *Detector) score(name string, hour int, value float64, b Baseline) Score {
+	s := Score{Variable: name, Hour: hour, Value: value, Samples: b.Count, WarmingUp: b.Count < d.cfg.WarmUp}
+	if b.Count == 0 {
+		return s
+	}
+	s.Mean = round(b.Mean, 2)
+	std := math.Max(math.Sqrt(b.Variance), d.cfg.MinStdDev[name])
+	s.StdDev = round(std, 2)
+	if std > 0 {
+		s.ZScore = round((value-b.Mean)/std, 2)
+	}
+	s.Anomalous = !s.WarmingUp && math.Abs(s.ZScore) >= d.cfg.Threshold
+	return s
+}
+
+// update incorpora a leitura à EWMA. No início o peso é 1/n, o que equivale à
+// média simples; valores anômalos entram limitados a média ± Threshold
+// desvios, para que um pico isolado não desloque a linha de base.
+func (d *Detector) update(b Baseline, value, std float64) Baseline {
+	if b.Count == 0 {
+		return Baseline{Mean: value, Count: 1}
+	}
+	if limit := d.cfg.Threshold * std; b.Count >= d.cfg.WarmUp && limit > 0 {
+		value = math.Max(b.Mean-limit, math.Min(b.Mean+limit, value))
+	}
+	alpha := math.Max(d.cfg.Alpha, 1/float64(b.Count+1))
+	diff := value - b.Mean
+	increment := alpha * diff
+	b.Mean += increment
+	b.Variance = (1 - alpha) * (b.Variance + diff*increment)
+	b.Count++
+	return b
+}
+
+func (d *Detector) event(msg *models.WeatherMessage, key string, t time.Time, s Score, recent []Reading) Event {
+	e := Event{
+		Station:   key,
+		Source:    msg.Source,
+		Latitude:  msg.Location.Latitude,
+		Longitude: msg.Location.Longitude,
+		Time:      t,
+		Score:     s,
+		Expected: [2]float64{
+			round(s.Mean-d.cfg.Threshold*s.StdDev, 2),
+			round(s.Mean+d.cfg.Threshold*s.StdDev, 2),
+		},
+	}
+	if e.Source == "" {
+		e.Source = models.DefaultSource
+	}
+	for _, r := range recent {
+		if v, ok := r.Values[s.Variable]; ok {
+			e.Recent = append(e.Recent, RecentValue{Time: r.Time, Value: v})
+		}
+	}
+	return e
+}
+
+// Len retorna o número de estações com linha de base
+func (d *Detector) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.stations)
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,173 @@
+package anomaly
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// start é meia-noite solar em longitude zero, para que as horas do dia
+// coincidam com as horas UTC
+var start = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func observation(temperature, humidity float64) *models.WeatherMessage {
+	return &models.WeatherMessage{
+		Source:   "station",
+		Location: models.WeatherLocation{Latitude: 51.5, Longitude: 0},
+		Current:  models.WeatherCurrent{Temperature: temperature, Humidity: humidity, WindSpeed: 10},
+	}
+}
+
+// learn alimenta o detector com days dias de leituras horárias, com a
+// temperatura seguindo o ciclo diário e uma pequena oscilação entre dias
+func learn(d *Detector, days int) time.Time {
+	t := start
+	for day := 0; day < days; day++ {
+		for hour := 0; hour < 24; hour++ {
+			d.Observe(observation(diurnal(hour)+float64(day%3)-1, 70), t)
+			t = t.Add(time.Hour)
+		}
+	}
+	return t
+}
+
+// diurnal é a temperatura típica da hora: 15 °C de madrugada, 27 °C à tarde
+func diurnal(hour int) float64 {
+	if hour < 14 {
+		return 15 + float64(hour)*12/14
+	}
+	return 27 - float64(hour-14)*12/10
+}
+
+func TestSolarHour(t *testing.T) {
+	tests := []struct {
+		longitude float64
+		want      int
+	}{
+		{0, 12},
+		{-46.6, 8},
+		{-180, 0},
+		{139.7, 21},
+	}
+	for _, tt := range tests {
+		if got := SolarHour(start.Add(12*time.Hour), tt.longitude); got != tt.want {
+			t.Errorf("SolarHour(12:00Z, %v) = %d, want %d", tt.longitude, got, tt.want)
+		}
+	}
+}
+
+func TestDetector_Observe(t *testing.T) {
+	tests := []struct {
+		name        string
+		days        int
+		temperature float64
+		hour        int
+		wantFlags   []string
+	}{
+		{name: "warm afternoon is normal", days: 20, temperature: 27.5, hour: 14},
+		{name: "afternoon heat at dawn is anomalous", days: 20, temperature: 27, hour: 3, wantFlags: []string{"anomaly:temperature"}},
+		{name: "warm-up suppresses events", days: 5, temperature: 27, hour: 3},
+		{name: "cold snap", days: 20, temperature: 5, hour: 14, wantFlags: []string{"anomaly:temperature"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(DefaultConfig())
+			next := learn(d, tt.days)
+			result := d.Observe(observation(tt.temperature, 70), next.Add(time.Duration(tt.hour)*time.Hour))
+			if !reflect.DeepEqual(result.Flags, tt.wantFlags) {
+				t.Errorf("Flags = %v, want %v (scores %+v)", result.Flags, tt.wantFlags, result.Scores)
+			}
+			if len(result.Events) != len(tt.wantFlags) {
+				t.Errorf("Events = %d, want %d", len(result.Events), len(tt.wantFlags))
+			}
+		})
+	}
+}
+
+func TestDetector_Event(t *testing.T) {
+	d := NewDetector(DefaultConfig())
+	next := learn(d, 20)
+	d.Observe(observation(15.5, 70), next)
+	d.Observe(observation(16, 70), next.Add(time.Hour))
+	result := d.Observe(observation(40, 70), next.Add(2*time.Hour))
+	if len(result.Events) != 1 {
+		t.Fatalf("Events = %+v, want one", result.Events)
+	}
+
+	e := result.Events[0]
+	if e.Station != observation(0, 0).StationKey() || e.Variable != "temperature" || e.Hour != 2 || e.Value != 40 {
+		t.Errorf("Event = %+v", e)
+	}
+	if e.Expected[0] >= e.Mean || e.Expected[1] <= e.Mean || e.Expected[1] >= 40 {
+		t.Errorf("Expected = %v around mean %v", e.Expected, e.Mean)
+	}
+	if n := len(e.Recent); n != DefaultConfig().Recent || e.Recent[n-1].Value != 16 {
+		t.Errorf("Recent = %+v, want the last %d readings ending at 16", e.Recent, DefaultConfig().Recent)
+	}
+}
+
+func TestDetector_Baseline(t *testing.T) {
+	t.Run("spike does not shift the baseline", func(t *testing.T) {
+		d := NewDetector(DefaultConfig())
+		next := learn(d, 20)
+		d.Observe(observation(60, 70), next.Add(3*time.Hour))
+		result := d.Observe(observation(diurnal(3), 70), next.Add(27*time.Hour))
+		if len(result.Flags) != 0 {
+			t.Errorf("Flags after spike = %v, want none (scores %+v)", result.Flags, result.Scores)
+		}
+	})
+
+	t.Run("redelivery is scored without events or learning", func(t *testing.T) {
+		d := NewDetector(DefaultConfig())
+		next := learn(d, 20)
+		first := d.Observe(observation(40, 70), next)
+		again := d.Observe(observation(40, 70), next)
+		if len(first.Events) != 1 || len(again.Events) != 0 || !reflect.DeepEqual(first.Flags, again.Flags) {
+			t.Errorf("first = %+v, again = %+v", first, again)
+		}
+		if first.Scores[0].Samples != again.Scores[0].Samples-1 {
+			t.Errorf("Samples = %d then %d, want one more after the first observation", first.Scores[0].Samples, again.Scores[0].Samples)
+		}
+	})
+
+	t.Run("score records only on commit", func(t *testing.T) {
+		d := NewDetector(DefaultConfig())
+		_, pending := d.Score(observation(20, 70), start)
+		if d.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", d.Len())
+		}
+		if !d.Commit(pending) || d.Commit(pending) {
+			t.Error("Commit() should record the reading once")
+		}
+		if result, _ := d.Score(observation(20, 70), start.Add(24*time.Hour)); result.Scores[0].Samples != 1 {
+			t.Errorf("Samples = %d, want 1", result.Scores[0].Samples)
+		}
+	})
+}
+
+func TestDetector_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anomaly.json")
+
+	d := NewDetector(DefaultConfig())
+	next := learn(d, 20)
+	if err := d.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	restored := NewDetector(DefaultConfig())
+	if err := restored.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	result := restored.Observe(observation(27, 70), next.Add(3*time.Hour))
+	if !reflect.DeepEqual(result.Flags, []string{"anomaly:temperature"}) {
+		t.Errorf("Flags after Load = %v, want temperature", result.Flags)
+	}
+
+	if err := NewDetector(DefaultConfig()).Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Load(missing) error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,80 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stateVersion é a versão do formato do arquivo de estado
+const stateVersion = 1
+
+type state struct {
+	Version  int                 `json:"version"`
+	Stations map[string]*station `json:"stations"`
+}
+
+// Save grava as linhas de base em path, escrevendo ao lado e renomeando para
+// que uma falha no meio não corrompa o estado anterior
+func (d *Detector) Save(path string) error {
+	d.mu.Lock()
+	data, err := json.Marshal(state{Version: stateVersion, Stations: d.stations})
+	d.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+	return nil
+}
+
+// Load substitui as linhas de base pelas gravadas em path. Arquivo
+// inexistente não é erro: o detector começa aquecendo.
+func (d *Detector) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("estado das anomalias: %w", err)
+	}
+	if st.Version != stateVersion {
+		return fmt.Errorf("estado das anomalias: versão %d não suportada", st.Version)
+	}
+
+	stations := make(map[string]*station, len(st.Stations))
+	for key, s := range st.Stations {
+		if s == nil {
+			continue
+		}
+		if s.Baselines == nil {
+			s.Baselines = make(map[string]*[24]Baseline)
+		}
+		stations[key] = s
+	}
+	d.mu.Lock()
+	d.stations = stations
+	d.mu.Unlock()
+	return nil
+}
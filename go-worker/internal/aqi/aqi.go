@@ -0,0 +1,183 @@
+// Package aqi calcula índices de qualidade do ar a partir das concentrações
+// dos poluentes: o AQI da EPA (EUA, com as faixas de PM2.5 revisadas em 2024)
+// e o IQAr da Resolução CONAMA 491/2018 (Brasil). Cada índice traz o
+// subíndice de cada poluente e o poluente dominante, o de maior subíndice.
+//
+// As tabelas são aplicadas às concentrações recebidas, sem médias móveis:
+// com leituras horárias o resultado é um índice instantâneo, não o índice
+// regulatório de 8 ou 24 horas.
+package aqi
+
+import (
+	"math"
+
+	"go-worker/internal/units"
+)
+
+// Poluentes, com os nomes usados em current pela Open-Meteo e pela mensagem
+const (
+	PM25            = "pm2_5"
+	PM10            = "pm10"
+	Ozone           = "ozone"
+	NitrogenDioxide = "nitrogen_dioxide"
+	SulphurDioxide  = "sulphur_dioxide"
+	CarbonMonoxide  = "carbon_monoxide"
+)
+
+// Pollutants lista os poluentes na ordem de desempate do poluente dominante
+var Pollutants = []string{PM25, PM10, Ozone, NitrogenDioxide, SulphurDioxide, CarbonMonoxide}
+
+// MolarMass é a massa molar (g/mol) dos gases, para converter ppb e ppm
+var MolarMass = map[string]float64{
+	Ozone:           48.00,
+	NitrogenDioxide: 46.0055,
+	SulphurDioxide:  64.066,
+	CarbonMonoxide:  28.010,
+}
+
+// Concentrations são as concentrações em µg/m³ por poluente
+type Concentrations map[string]float64
+
+// SubIndex é o índice de um poluente
+type SubIndex struct {
+	Pollutant string `json:"pollutant"`
+	// Concentration está na unidade da tabela do padrão (Unit), já truncada
+	Concentration float64 `json:"concentration"`
+	Unit          string  `json:"unit"`
+	Index         int     `json:"index"`
+	Category      string  `json:"category"`
+}
+
+// Index é o índice de qualidade do ar segundo um padrão
+type Index struct {
+	Standard string `json:"standard"`
+	Index    int    `json:"index"`
+	Category string `json:"category"`
+	// Dominant é o poluente de maior subíndice
+	Dominant   string     `json:"dominantPollutant"`
+	SubIndexes []SubIndex `json:"subIndexes"`
+}
+
+// band é uma faixa da tabela: concentrações de cLo a cHi correspondem aos
+// índices de iLo a iHi
+type band struct {
+	cLo, cHi float64
+	iLo, iHi int
+}
+
+// table converte a concentração de um poluente para a unidade do padrão e a
+// trunca nas casas decimais da tabela antes da interpolação
+type table struct {
+	unit     string
+	decimals int
+	convert  func(ugm3 float64) float64
+	bands    []band
+	// hourly é a tabela de 1 hora do O3 da EPA, separada da de 8 horas
+	hourly *hourlyTable
+}
+
+// hourlyTable é uma tabela alternativa aplicada a partir da concentração from;
+// o subíndice é o maior entre ela e a tabela principal
+type hourlyTable struct {
+	from  float64
+	bands []band
+}
+
+// category é o nome da faixa de índice até max
+type category struct {
+	max  int
+	name string
+}
+
+// Standard é um padrão de índice de qualidade do ar
+type Standard struct {
+	name       string
+	tables     map[string]table
+	categories []category
+}
+
+// Name identifica o padrão (epa, conama)
+func (s *Standard) Name() string { return s.name }
+
+// Compute calcula o índice com os poluentes informados; sem nenhum poluente
+// coberto pelo padrão o segundo retorno é false
+func (s *Standard) Compute(c Concentrations) (Index, bool) {
+	idx := Index{Standard: s.name}
+	for _, pollutant := range Pollutants {
+		value, ok := c[pollutant]
+		t, covered := s.tables[pollutant]
+		if !ok || !covered {
+			continue
+		}
+		sub := s.subIndex(pollutant, value, t)
+		if len(idx.SubIndexes) == 0 || sub.Index > idx.Index {
+			idx.Index, idx.Dominant = sub.Index, pollutant
+		}
+		idx.SubIndexes = append(idx.SubIndexes, sub)
+	}
+	if len(idx.SubIndexes) == 0 {
+		return Index{}, false
+	}
+	idx.Category = s.category(idx.Index)
+	return idx, true
+}
+
+// subIndex calcula o índice na tabela do poluente e, se houver, na tabela de
+// 1 hora, ficando com o maior
+func (s *Standard) subIndex(pollutant string, ugm3 float64, t table) SubIndex {
+	c := truncate(t.convert(ugm3), t.decimals)
+	sub := SubIndex{Pollutant: pollutant, Concentration: c, Unit: t.unit}
+	sub.Index = interpolate(t.bands, c)
+	if t.hourly != nil && c >= t.hourly.from {
+		if hourly := interpolate(t.hourly.bands, c); hourly > sub.Index {
+			sub.Index = hourly
+		}
+	}
+	sub.Category = s.category(sub.Index)
+	return sub
+}
+
+// interpolate interpola linearmente na faixa da concentração. Acima da última
+// faixa o índice fica no máximo da tabela; concentrações entre faixas (as
+// lacunas das tabelas da EPA) usam o início da faixa seguinte.
+func interpolate(bands []band, c float64) int {
+	b := bands[len(bands)-1]
+	for _, candidate := range bands {
+		if c <= candidate.cHi {
+			b = candidate
+			break
+		}
+	}
+	c = math.Max(b.cLo, math.Min(b.cHi, c))
+	index := float64(b.iLo) + float64(b.iHi-b.iLo)/(b.cHi-b.cLo)*(c-b.cLo)
+	return int(math.Round(index))
+}
+
+func (s *Standard) category(index int) string {
+	for _, c := range s.categories {
+		if index <= c.max {
+			return c.name
+		}
+	}
+	return s.categories[len(s.categories)-1].name
+}
+
+// truncate descarta as casas além das da tabela, como determinam os padrões
+func truncate(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	// a tolerância evita que 35.4 vire 35.39999 por erro de representação
+	return math.Floor(v*p+1e-9) / p
+}
+
+// ugm3 mantém a concentração em µg/m³
+func ugm3(v float64) float64 { return v }
+
+// ppb converte um gás de µg/m³ para ppb
+func ppb(pollutant string) func(float64) float64 {
+	return func(v float64) float64 { return units.Concentration(v).PPB(MolarMass[pollutant]) }
+}
+
+// ppm converte um gás de µg/m³ para ppm
+func ppm(pollutant string) func(float64) float64 {
+	return func(v float64) float64 { return units.Concentration(v).PPM(MolarMass[pollutant]) }
+}
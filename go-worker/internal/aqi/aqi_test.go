@@ -0,0 +1,100 @@
+package aqi
+
+import "testing"
+
+func TestStandard_Compute(t *testing.T) {
+	tests := []struct {
+		name         string
+		standard     *Standard
+		c            Concentrations
+		wantIndex    int
+		wantCategory string
+		wantDominant string
+	}{
+		{"epa pm2.5 moderate", EPA, Concentrations{PM25: 12}, 56, "Moderate", PM25},
+		{"epa pm2.5 breakpoint", EPA, Concentrations{PM25: 35.4}, 100, "Moderate", PM25},
+		{"epa pm2.5 truncated into the lower band", EPA, Concentrations{PM25: 9.04}, 50, "Good", PM25},
+		{"epa pm10 sensitive groups", EPA, Concentrations{PM10: 155}, 101, "Unhealthy for Sensitive Groups", PM10},
+		{"epa ozone from µg/m³", EPA, Concentrations{Ozone: 100}, 46, "Good", Ozone},
+		{"epa beyond the scale", EPA, Concentrations{PM25: 400}, 500, "Hazardous", PM25},
+		{"epa dominant carbon monoxide", EPA, Concentrations{PM25: 12, PM10: 30, CarbonMonoxide: 10000}, 93, "Moderate", CarbonMonoxide},
+		{"conama pm10 moderate", CONAMA, Concentrations{PM10: 75}, 61, "Moderada", PM10},
+		{"conama nitrogen dioxide", CONAMA, Concentrations{NitrogenDioxide: 250}, 86, "Ruim", NitrogenDioxide},
+		{"conama dominant pm10", CONAMA, Concentrations{PM25: 12, PM10: 75, Ozone: 100, CarbonMonoxide: 10000}, 61, "Moderada", PM10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.standard.Compute(tt.c)
+			if !ok {
+				t.Fatal("Compute() ok = false")
+			}
+			if got.Index != tt.wantIndex || got.Category != tt.wantCategory || got.Dominant != tt.wantDominant {
+				t.Errorf("Compute() = %d %q dominated by %s, want %d %q dominated by %s (%+v)",
+					got.Index, got.Category, got.Dominant, tt.wantIndex, tt.wantCategory, tt.wantDominant, got.SubIndexes)
+			}
+			if len(got.SubIndexes) != len(tt.c) {
+				t.Errorf("SubIndexes = %d, want %d", len(got.SubIndexes), len(tt.c))
+			}
+		})
+	}
+}
+
+func TestStandard_Compute_SubIndexes(t *testing.T) {
+	got, _ := EPA.Compute(Concentrations{NitrogenDioxide: 188.2, CarbonMonoxide: 10000})
+	want := []SubIndex{
+		{Pollutant: NitrogenDioxide, Concentration: 100, Unit: "ppb", Index: 100, Category: "Moderate"},
+		{Pollutant: CarbonMonoxide, Concentration: 8.7, Unit: "ppm", Index: 93, Category: "Moderate"},
+	}
+	if len(got.SubIndexes) != len(want) {
+		t.Fatalf("SubIndexes = %+v", got.SubIndexes)
+	}
+	for i := range want {
+		if got.SubIndexes[i] != want[i] {
+			t.Errorf("SubIndexes[%d] = %+v, want %+v", i, got.SubIndexes[i], want[i])
+		}
+	}
+
+	if _, ok := CONAMA.Compute(Concentrations{}); ok {
+		t.Error("Compute(empty) ok = true, want false")
+	}
+}
+
+func TestStandard_Compute_Ozone(t *testing.T) {
+	// 1 ppm de O3 = 1963,2 µg/m³ a 25 °C e 1 atm
+	const ugm3PerPPM = 48.00 * 1000 / 24.45
+	tests := []struct {
+		ppm       float64
+		wantIndex int
+	}{
+		{0.124, 220},
+		{0.164, 262},
+		{0.200, 300},
+		{0.201, 300},
+		{0.204, 300},
+		{0.300, 300},
+		{0.405, 301},
+		{0.604, 500},
+	}
+	for _, tt := range tests {
+		got, _ := EPA.Compute(Concentrations{Ozone: tt.ppm*ugm3PerPPM + 1e-6})
+		if got.Index != tt.wantIndex {
+			t.Errorf("ozone %.3f ppm: index = %d, want %d (%+v)", tt.ppm, got.Index, tt.wantIndex, got.SubIndexes)
+		}
+	}
+}
+
+func TestStandard_Compute_Monotonic(t *testing.T) {
+	for _, standard := range []*Standard{EPA, CONAMA} {
+		for _, pollutant := range Pollutants {
+			prev := -1
+			for c := 0.1; c <= 100000; c *= 1.002 {
+				got, _ := standard.Compute(Concentrations{pollutant: c})
+				if got.Index < prev {
+					t.Fatalf("%s %s: index dropped from %d to %d at %v µg/m³", standard.Name(), pollutant, prev, got.Index, c)
+				}
+				prev = got.Index
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package aqi
+
+// EPA é o AQI dos EUA (40 CFR Part 58, Appendix G, revisão de 2024). O3 tem
+// duas tabelas, como na EPA: a de 8 horas, que vai até 0,200 ppm, e a de
+// 1 hora, aplicada a partir de 0,125 ppm; vale o maior dos dois subíndices.
+// As concentrações são truncadas nas casas decimais de cada tabela.
+var EPA = &Standard{
+	name: "epa",
+	tables: map[string]table{
+		PM25: {"µg/m³", 1, ugm3, []band{
+			{0, 9.0, 0, 50}, {9.1, 35.4, 51, 100}, {35.5, 55.4, 101, 150},
+			{55.5, 125.4, 151, 200}, {125.5, 225.4, 201, 300}, {225.5, 325.4, 301, 500},
+		}, nil},
+		PM10: {"µg/m³", 0, ugm3, []band{
+			{0, 54, 0, 50}, {55, 154, 51, 100}, {155, 254, 101, 150},
+			{255, 354, 151, 200}, {355, 424, 201, 300}, {425, 604, 301, 500},
+		}, nil},
+		Ozone: {"ppm", 3, ppm(Ozone), []band{
+			{0, 0.054, 0, 50}, {0.055, 0.070, 51, 100}, {0.071, 0.085, 101, 150},
+			{0.086, 0.105, 151, 200}, {0.106, 0.200, 201, 300},
+		}, &hourlyTable{0.125, []band{
+			{0.125, 0.164, 101, 150}, {0.165, 0.204, 151, 200},
+			{0.205, 0.404, 201, 300}, {0.405, 0.604, 301, 500},
+		}}},
+		NitrogenDioxide: {"ppb", 0, ppb(NitrogenDioxide), []band{
+			{0, 53, 0, 50}, {54, 100, 51, 100}, {101, 360, 101, 150},
+			{361, 649, 151, 200}, {650, 1249, 201, 300}, {1250, 2049, 301, 500},
+		}, nil},
+		SulphurDioxide: {"ppb", 0, ppb(SulphurDioxide), []band{
+			{0, 35, 0, 50}, {36, 75, 51, 100}, {76, 185, 101, 150},
+			{186, 304, 151, 200}, {305, 604, 201, 300}, {605, 1004, 301, 500},
+		}, nil},
+		CarbonMonoxide: {"ppm", 1, ppm(CarbonMonoxide), []band{
+			{0, 4.4, 0, 50}, {4.5, 9.4, 51, 100}, {9.5, 12.4, 101, 150},
+			{12.5, 15.4, 151, 200}, {15.5, 30.4, 201, 300}, {30.5, 50.4, 301, 500},
+		}, nil},
+	},
+	categories: []category{
+		{50, "Good"},
+		{100, "Moderate"},
+		{150, "Unhealthy for Sensitive Groups"},
+		{200, "Unhealthy"},
+		{300, "Very Unhealthy"},
+		{500, "Hazardous"},
+	},
+}
+
+// CONAMA é o IQAr da Resolução CONAMA 491/2018, com as faixas finais dos
+// padrões de qualidade do ar e o cálculo adotado pela CETESB. Cada faixa
+// começa logo acima do fim da anterior; CO é medido em ppm.
+var CONAMA = &Standard{
+	name: "conama",
+	tables: map[string]table{
+		PM25:            {"µg/m³", 1, ugm3, conamaBands(25, 50, 75, 125, 300), nil},
+		PM10:            {"µg/m³", 1, ugm3, conamaBands(50, 100, 150, 250, 600), nil},
+		Ozone:           {"µg/m³", 1, ugm3, conamaBands(100, 130, 160, 200, 800), nil},
+		NitrogenDioxide: {"µg/m³", 1, ugm3, conamaBands(200, 240, 320, 1130, 3750), nil},
+		SulphurDioxide:  {"µg/m³", 1, ugm3, conamaBands(20, 40, 365, 800, 2620), nil},
+		CarbonMonoxide:  {"ppm", 1, ppm(CarbonMonoxide), conamaBands(9, 11, 13, 15, 50), nil},
+	},
+	categories: []category{
+		{40, "Boa"},
+		{80, "Moderada"},
+		{120, "Ruim"},
+		{200, "Muito Ruim"},
+		{400, "Péssima"},
+	},
+}
+
+// conamaBands monta as faixas N1 a N5 (índices 0–40, 41–80, 81–120, 121–200
+// e 201–400) a partir dos limites superiores de concentração
+func conamaBands(limits ...float64) []band {
+	indexes := []int{0, 40, 80, 120, 200, 400}
+	bands := make([]band, len(limits))
+	lo := 0.0
+	for i, hi := range limits {
+		iLo := indexes[i]
+		if i > 0 {
+			iLo++
+		}
+		bands[i] = band{cLo: lo, cHi: hi, iLo: iLo, iHi: indexes[i+1]}
+		lo = hi
+	}
+	return bands
+}
+
+// Standards são os padrões calculados para cada leitura, por nome
+var Standards = []*Standard{EPA, CONAMA}
@@ -3,20 +3,26 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go-worker/internal/models"
 	"io"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 )
 
 // APIClient é o cliente HTTP para a API NestJS
 type APIClient struct {
+	mu               sync.RWMutex
 	baseURL          string
+	forecastURL      string
+	airQualityURL    string
 	httpClient       *http.Client
 	maxRetryAttempts int
 	retryDelay       time.Duration
+	contract         *Contract
 }
 
 // NewAPIClient cria uma nova instância do cliente API
@@ -31,12 +37,88 @@ func NewAPIClient(baseURL string, maxRetryAttempts int, retryDelay time.Duration
 	}
 }
 
-// SendWeatherLog envia os dados meteorológicos para a API NestJS com retry
+// SetRetryPolicy altera a política de retry usada nos próximos envios
+func (c *APIClient) SetRetryPolicy(maxRetryAttempts int, retryDelay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxRetryAttempts = maxRetryAttempts
+	c.retryDelay = retryDelay
+}
+
+// SetBaseURL altera o endpoint de destino usado nos próximos envios
+func (c *APIClient) SetBaseURL(baseURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseURL = baseURL
+}
+
+// SetForecastURL define o endpoint que recebe as previsões. Sem ele as
+// previsões são recusadas, para que nunca se misturem às observações.
+func (c *APIClient) SetForecastURL(forecastURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forecastURL = forecastURL
+}
+
+// SetAirQualityURL define o endpoint que recebe os registros de qualidade do ar
+func (c *APIClient) SetAirQualityURL(airQualityURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.airQualityURL = airQualityURL
+}
+
+// SetContract restringe o payload aos campos aceitos pela versão do backend;
+// campos fora do contrato são descartados antes do envio
+func (c *APIClient) SetContract(contract Contract) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contract = &contract
+}
+
+// SendWeatherLog envia os dados meteorológicos para a API NestJS com retry.
+// Previsões vão para o endpoint de SetForecastURL.
 func (c *APIClient) SendWeatherLog(log models.WeatherLog) error {
-	var lastErr error
+	c.mu.RLock()
+	endpoint, maxRetryAttempts, retryDelay, contract := c.baseURL, c.maxRetryAttempts, c.retryDelay, c.contract
+	if log.Kind == models.KindForecast {
+		endpoint = c.forecastURL
+	}
+	c.mu.RUnlock()
+	if log.Kind == models.KindForecast && endpoint == "" {
+		return ErrNoForecastEndpoint
+	}
+
+	// Serializa o payload
+	payload, err := encodePayload(log, contract)
+	if err != nil {
+		return err
+	}
+	return c.post(endpoint, payload, maxRetryAttempts, retryDelay)
+}
+
+// SendAirQualityLog envia um registro de qualidade do ar para o endpoint de
+// SetAirQualityURL, com o mesmo retry das observações. O payload não passa
+// pelo contrato, que descreve o DTO das observações.
+func (c *APIClient) SendAirQualityLog(log models.AirQualityLog) error {
+	c.mu.RLock()
+	endpoint, maxRetryAttempts, retryDelay := c.airQualityURL, c.maxRetryAttempts, c.retryDelay
+	c.mu.RUnlock()
+	if endpoint == "" {
+		return ErrNoAirQualityEndpoint
+	}
 
-	for attempt := 1; attempt <= c.maxRetryAttempts; attempt++ {
-		err := c.sendRequest(log, attempt)
+	payload, err := json.Marshal(log)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar payload: %w", err)
+	}
+	return c.post(endpoint, payload, maxRetryAttempts, retryDelay)
+}
+
+// post envia o payload com backoff exponencial; erros 4xx não são repetidos
+func (c *APIClient) post(endpoint string, payload []byte, maxRetryAttempts int, retryDelay time.Duration) error {
+	var lastErr error
+	for attempt := 1; attempt <= maxRetryAttempts; attempt++ {
+		err := c.sendRequest(endpoint, payload, attempt)
 		if err == nil {
 			return nil
 		}
@@ -50,27 +132,43 @@ func (c *APIClient) SendWeatherLog(log models.WeatherLog) error {
 		}
 
 		// Se não for a última tentativa, aguarda antes de tentar novamente
-		if attempt < c.maxRetryAttempts {
-			delay := c.retryDelay * time.Duration(1<<uint(attempt-1)) // Backoff exponencial
+		if attempt < maxRetryAttempts {
+			delay := retryDelay * time.Duration(1<<uint(attempt-1)) // Backoff exponencial
 			logMessage(fmt.Sprintf("[WARN] Falha ao enviar para API (tentativa %d/%d): %v. Aguardando %v...",
-				attempt, c.maxRetryAttempts, err, delay))
+				attempt, maxRetryAttempts, err, delay))
 			time.Sleep(delay)
 		}
 	}
 
-	logMessage(fmt.Sprintf("[ERROR] Todas as tentativas falharam após %d tentativas", c.maxRetryAttempts))
+	logMessage(fmt.Sprintf("[ERROR] Todas as tentativas falharam após %d tentativas", maxRetryAttempts))
 	return lastErr
 }
 
-func (c *APIClient) sendRequest(log models.WeatherLog, attempt int) error {
-	endpoint := c.baseURL
+// encodePayload serializa o log, projetado no contrato quando houver um
+func encodePayload(log models.WeatherLog, contract *Contract) ([]byte, error) {
+	if contract == nil {
+		payload, err := json.Marshal(log)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar payload: %w", err)
+		}
+		return payload, nil
+	}
 
-	// Serializa o payload
-	payload, err := json.Marshal(log)
+	fields, dropped, err := contract.Project(log)
 	if err != nil {
-		return fmt.Errorf("erro ao serializar payload: %w", err)
+		return nil, err
+	}
+	if len(dropped) > 0 {
+		logMessage(fmt.Sprintf("[DEBUG] Campos fora do contrato %s descartados: %v", contract.Version, dropped))
+	}
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar payload: %w", err)
 	}
+	return payload, nil
+}
 
+func (c *APIClient) sendRequest(endpoint string, payload []byte, attempt int) error {
 	logMessage(fmt.Sprintf("[INFO] Enviando para API NestJS: POST %s (tentativa %d)", endpoint, attempt))
 
 	// Cria a requisição
@@ -104,6 +202,12 @@ func (c *APIClient) sendRequest(log models.WeatherLog, attempt int) error {
 	}
 }
 
+// ErrNoForecastEndpoint indica uma previsão sem endpoint de previsões configurado
+var ErrNoForecastEndpoint = errors.New("endpoint de previsões não configurado")
+
+// ErrNoAirQualityEndpoint indica um registro de qualidade do ar sem endpoint configurado
+var ErrNoAirQualityEndpoint = errors.New("endpoint de qualidade do ar não configurado")
+
 // HTTPError representa um erro HTTP
 type HTTPError struct {
 	StatusCode int
@@ -117,4 +221,3 @@ func (e *HTTPError) Error() string {
 func logMessage(message string) {
 	log.Printf("%s", message)
 }
-
@@ -0,0 +1,258 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Tipos JSON esperados pelo class-validator do backend
+const (
+	typeString = "string"
+	typeNumber = "number"
+	typeArray  = "array"
+)
+
+// Contract descreve os campos aceitos por uma versão do DTO CreateWeatherLogDto
+// do backend. O backend usa forbidNonWhitelisted, portanto campos fora do
+// contrato fazem a requisição ser rejeitada com 400.
+type Contract struct {
+	Version  string
+	Required map[string]string
+	Optional map[string]string
+}
+
+// Versões do DTO; cada uma estende a anterior com os campos novos do worker
+var (
+	// contractV1 é o DTO original, sem campos derivados pelo worker
+	contractV1 = Contract{
+		Version: "v1",
+		Required: map[string]string{
+			"location":    typeString,
+			"temperature": typeNumber,
+			"humidity":    typeNumber,
+			"pressure":    typeNumber,
+		},
+		Optional: map[string]string{
+			"description":   typeString,
+			"windSpeed":     typeNumber,
+			"windDirection": typeString,
+			"visibility":    typeNumber,
+			"uvIndex":       typeNumber,
+			"source":        typeString,
+		},
+	}
+	// contractV2 aceita as regiões definidas pelo usuário
+	contractV2 = contractV1.extend("v2", map[string]string{
+		"regions": typeArray,
+	})
+	// contractV3 acrescenta o ícone da condição do tempo
+	contractV3 = contractV2.extend("v3", map[string]string{
+		"icon": typeString,
+	})
+	// contractV4 acrescenta as métricas de conforto
+	contractV4 = contractV3.extend("v4", map[string]string{
+		"dewPoint":            typeNumber,
+		"heatIndex":           typeNumber,
+		"windChill":           typeNumber,
+		"apparentTemperature": typeNumber,
+		"absoluteHumidity":    typeNumber,
+	})
+	// contractV5 torna a pressão opcional (não é mais inventada pelo worker) e
+	// aceita as demais grandezas opcionais do provedor
+	contractV5 = contractV4.extend("v5", map[string]string{
+		"pressure":             typeNumber,
+		"windDirectionDegrees": typeNumber,
+		"precipitation":        typeNumber,
+		"cloudCover":           typeNumber,
+	})
+	// contractV6 acrescenta a pressão na estação e a altitude usada na redução
+	contractV6 = contractV5.extend("v6", map[string]string{
+		"stationPressure": typeNumber,
+		"elevation":       typeNumber,
+		"elevationSource": typeString,
+	})
+	// contractV7 envia o instante da observação, que antes era o da ingestão
+	contractV7 = contractV6.extend("v7", map[string]string{
+		"timestamp": typeString,
+	})
+	// contractV8 acrescenta a rajada de vento
+	contractV8 = contractV7.extend("v8", map[string]string{
+		"windGust": typeNumber,
+	})
+	// contractV9 acrescenta as marcações das regras de qualidade configuradas
+	contractV9 = contractV8.extend("v9", map[string]string{
+		"qualityFlags": typeArray,
+	})
+	// contractV10 acrescenta a nota do controle de qualidade temporal
+	contractV10 = contractV9.extend("v10", map[string]string{
+		"qualityScore": typeNumber,
+	})
+	// contractV11 separa observações de previsões e identifica a previsão
+	contractV11 = contractV10.extend("v11", map[string]string{
+		"kind":           typeString,
+		"issuedAt":       typeString,
+		"period":         typeString,
+		"temperatureMax": typeNumber,
+		"temperatureMin": typeNumber,
+	})
+	// contractV12 acrescenta os reparos da normalização tolerante
+	contractV12 = contractV11.extend("v12", map[string]string{
+		"repairs": typeArray,
+	})
+	// contractV13 acrescenta as propriedades GeoJSON das regiões
+	contractV13 = contractV12.extend("v13", map[string]string{
+		"regionDetails": typeArray,
+	})
+)
+
+// contracts lista as versões conhecidas do backend
+var contracts = map[string]Contract{
+	"v1":  contractV1,
+	"v2":  contractV2,
+	"v3":  contractV3,
+	"v4":  contractV4,
+	"v5":  contractV5,
+	"v6":  contractV6,
+	"v7":  contractV7,
+	"v8":  contractV8,
+	"v9":  contractV9,
+	"v10": contractV10,
+	"v11": contractV11,
+	"v12": contractV12,
+	"v13": contractV13,
+}
+
+// DefaultContractVersion é a versão do backend presente neste repositório
+const DefaultContractVersion = "v13"
+
+// extend cria uma nova versão com campos opcionais adicionais. Um campo
+// obrigatório listado em optional passa a ser opcional.
+func (c Contract) extend(version string, optional map[string]string) Contract {
+	next := Contract{
+		Version:  version,
+		Required: make(map[string]string, len(c.Required)),
+		Optional: make(map[string]string, len(c.Optional)+len(optional)),
+	}
+	for name, typ := range c.Required {
+		next.Required[name] = typ
+	}
+	for name, typ := range c.Optional {
+		next.Optional[name] = typ
+	}
+	for name, typ := range optional {
+		delete(next.Required, name)
+		next.Optional[name] = typ
+	}
+	return next
+}
+
+// LookupContract retorna o contrato de uma versão do backend
+func LookupContract(version string) (Contract, error) {
+	c, ok := contracts[version]
+	if !ok {
+		return Contract{}, fmt.Errorf("versão de contrato desconhecida: %q", version)
+	}
+	return c, nil
+}
+
+// ContractReport compara um payload com o que uma versão do backend espera
+type ContractReport struct {
+	Version        string   `json:"version"`
+	Compatible     bool     `json:"compatible"`
+	Missing        []string `json:"missing,omitempty"`
+	Unexpected     []string `json:"unexpected,omitempty"`
+	TypeMismatches []string `json:"typeMismatches,omitempty"`
+}
+
+// Check verifica se o payload, na forma serializada, seria aceito pelo backend
+func (c Contract) Check(payload interface{}) (ContractReport, error) {
+	fields, err := toFields(payload)
+	if err != nil {
+		return ContractReport{}, err
+	}
+
+	report := ContractReport{Version: c.Version}
+	for name, want := range c.Required {
+		value, ok := fields[name]
+		if !ok || value == nil {
+			report.Missing = append(report.Missing, name)
+			continue
+		}
+		if got := jsonType(value); got != want {
+			report.TypeMismatches = append(report.TypeMismatches, fmt.Sprintf("%s: esperado %s, recebido %s", name, want, got))
+		}
+	}
+	for name, value := range fields {
+		if _, ok := c.Required[name]; ok {
+			continue
+		}
+		want, ok := c.Optional[name]
+		if !ok {
+			report.Unexpected = append(report.Unexpected, name)
+			continue
+		}
+		if got := jsonType(value); value != nil && got != want {
+			report.TypeMismatches = append(report.TypeMismatches, fmt.Sprintf("%s: esperado %s, recebido %s", name, want, got))
+		}
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Unexpected)
+	sort.Strings(report.TypeMismatches)
+	report.Compatible = len(report.Missing) == 0 && len(report.Unexpected) == 0 && len(report.TypeMismatches) == 0
+	return report, nil
+}
+
+// Project mantém apenas os campos aceitos pelo contrato, para que campos novos
+// do worker não façam um backend mais antigo rejeitar o payload. Retorna também
+// os nomes dos campos descartados.
+func (c Contract) Project(payload interface{}) (map[string]interface{}, []string, error) {
+	fields, err := toFields(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var dropped []string
+	for name := range fields {
+		_, required := c.Required[name]
+		_, optional := c.Optional[name]
+		if !required && !optional {
+			dropped = append(dropped, name)
+			delete(fields, name)
+		}
+	}
+	sort.Strings(dropped)
+	return fields, dropped, nil
+}
+
+// toFields serializa o payload e o lê de volta como objeto JSON genérico
+func toFields(payload interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar payload: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("payload não é um objeto JSON: %w", err)
+	}
+	return fields, nil
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return typeString
+	case float64:
+		return typeNumber
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return typeArray
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
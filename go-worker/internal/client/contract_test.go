@@ -0,0 +1,212 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"go-worker/internal/models"
+)
+
+func TestContract_Check(t *testing.T) {
+	contract, err := LookupContract("v1")
+	if err != nil {
+		t.Fatalf("LookupContract() error = %v", err)
+	}
+
+	t.Run("current payload is compatible", func(t *testing.T) {
+		report, err := contract.Check(models.WeatherLog{
+			Location:    "São Paulo, SP",
+			Temperature: 25.5,
+			Humidity:    65,
+			Pressure:    models.Float64(1013.25),
+			Source:      "go-worker",
+		})
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !report.Compatible {
+			t.Errorf("Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("missing, unexpected and mistyped fields", func(t *testing.T) {
+		report, err := contract.Check(map[string]interface{}{
+			"location":    "São Paulo, SP",
+			"temperature": "25.5",
+			"humidity":    65,
+			"regions":     []string{"Fazenda"},
+		})
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if report.Compatible {
+			t.Error("Check() compatible, want incompatible")
+		}
+		if !reflect.DeepEqual(report.Missing, []string{"pressure"}) {
+			t.Errorf("Missing = %v, want [pressure]", report.Missing)
+		}
+		if !reflect.DeepEqual(report.Unexpected, []string{"regions"}) {
+			t.Errorf("Unexpected = %v, want [regions]", report.Unexpected)
+		}
+		if len(report.TypeMismatches) != 1 {
+			t.Errorf("TypeMismatches = %v, want 1 entry", report.TypeMismatches)
+		}
+	})
+
+	t.Run("v2 accepts regions", func(t *testing.T) {
+		v2, err := LookupContract("v2")
+		if err != nil {
+			t.Fatalf("LookupContract() error = %v", err)
+		}
+		report, err := v2.Check(models.WeatherLog{
+			Location:    "Planta 3",
+			Temperature: 25.5,
+			Humidity:    65,
+			Pressure:    models.Float64(1013.25),
+			Regions:     []string{"Planta 3", "Bairro Centro"},
+		})
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !report.Compatible {
+			t.Errorf("Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("pressure is optional from v5", func(t *testing.T) {
+		withoutPressure := models.WeatherLog{Location: "São Paulo, SP", Temperature: 25.5, Humidity: 65}
+		if report, _ := contract.Check(withoutPressure); !reflect.DeepEqual(report.Missing, []string{"pressure"}) {
+			t.Errorf("v1 Missing = %v, want [pressure]", report.Missing)
+		}
+		v5, err := LookupContract("v5")
+		if err != nil {
+			t.Fatalf("LookupContract() error = %v", err)
+		}
+		if report, _ := v5.Check(withoutPressure); !report.Compatible {
+			t.Errorf("v5 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("station pressure and elevation from v6", func(t *testing.T) {
+		reduced := models.WeatherLog{
+			Location: "São Paulo, SP", Temperature: 25.5, Humidity: 65,
+			Pressure: models.Float64(1008.5), StationPressure: models.Float64(925.1),
+			Elevation: models.Float64(760), ElevationSource: "grid",
+		}
+		v5, _ := LookupContract("v5")
+		if report, _ := v5.Check(reduced); !reflect.DeepEqual(report.Unexpected, []string{"elevation", "elevationSource", "stationPressure"}) {
+			t.Errorf("v5 Unexpected = %v, want [elevation elevationSource stationPressure]", report.Unexpected)
+		}
+		v6, err := LookupContract("v6")
+		if err != nil {
+			t.Fatalf("LookupContract() error = %v", err)
+		}
+		if report, _ := v6.Check(reduced); !report.Compatible {
+			t.Errorf("v6 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("observation timestamp from v7", func(t *testing.T) {
+		observed := models.WeatherLog{Timestamp: "2025-06-15T14:30:00Z", Location: "São Paulo, SP", Temperature: 25.5, Humidity: 65}
+		v6, _ := LookupContract("v6")
+		if report, _ := v6.Check(observed); !reflect.DeepEqual(report.Unexpected, []string{"timestamp"}) {
+			t.Errorf("v6 Unexpected = %v, want [timestamp]", report.Unexpected)
+		}
+		v7, _ := LookupContract("v7")
+		if report, _ := v7.Check(observed); !report.Compatible {
+			t.Errorf("v7 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("quality flags from v9", func(t *testing.T) {
+		flagged := models.WeatherLog{Location: "São Paulo, SP", Temperature: 25.5, Humidity: 100, QualityFlags: []string{"umidade-saturada:clamped"}}
+		v8, _ := LookupContract("v8")
+		if report, _ := v8.Check(flagged); !reflect.DeepEqual(report.Unexpected, []string{"qualityFlags"}) {
+			t.Errorf("v8 Unexpected = %v, want [qualityFlags]", report.Unexpected)
+		}
+		v9, _ := LookupContract("v9")
+		if report, _ := v9.Check(flagged); !report.Compatible {
+			t.Errorf("v9 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("quality score from v10", func(t *testing.T) {
+		scored := models.WeatherLog{Location: "São Paulo, SP", Temperature: 25.5, Humidity: 65, QualityScore: models.Float64(0.75)}
+		v9, _ := LookupContract("v9")
+		if report, _ := v9.Check(scored); !reflect.DeepEqual(report.Unexpected, []string{"qualityScore"}) {
+			t.Errorf("v9 Unexpected = %v, want [qualityScore]", report.Unexpected)
+		}
+		v10, _ := LookupContract("v10")
+		if report, _ := v10.Check(scored); !report.Compatible {
+			t.Errorf("v10 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("forecast fields from v11", func(t *testing.T) {
+		forecast := models.WeatherLog{Location: "São Paulo, SP", Temperature: 21, Humidity: 70, Kind: models.KindForecast,
+			IssuedAt: "2025-06-15T12:00:00Z", Period: models.PeriodDaily, TemperatureMax: models.Float64(26), TemperatureMin: models.Float64(16)}
+		v10, _ := LookupContract("v10")
+		if report, _ := v10.Check(forecast); !reflect.DeepEqual(report.Unexpected, []string{"issuedAt", "kind", "period", "temperatureMax", "temperatureMin"}) {
+			t.Errorf("v10 Unexpected = %v, want the forecast fields", report.Unexpected)
+		}
+		v11, _ := LookupContract("v11")
+		if report, _ := v11.Check(forecast); !report.Compatible {
+			t.Errorf("v11 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("repairs from v12", func(t *testing.T) {
+		repaired := models.WeatherLog{Location: "São Paulo, SP", Temperature: 25.3, Humidity: 65, Repairs: []string{"decimal-comma:current.temperature"}}
+		v11, _ := LookupContract("v11")
+		if report, _ := v11.Check(repaired); !reflect.DeepEqual(report.Unexpected, []string{"repairs"}) {
+			t.Errorf("v11 Unexpected = %v, want [repairs]", report.Unexpected)
+		}
+		v12, _ := LookupContract("v12")
+		if report, _ := v12.Check(repaired); !report.Compatible {
+			t.Errorf("v12 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("region details from v13", func(t *testing.T) {
+		tagged := models.WeatherLog{Location: "Planta 3", Temperature: 25.3, Humidity: 65, Regions: []string{"Planta 3"},
+			RegionDetails: []models.RegionRef{{Name: "Planta 3", Properties: map[string]interface{}{"id": "PL-03"}}}}
+		v12, _ := LookupContract("v12")
+		if report, _ := v12.Check(tagged); !reflect.DeepEqual(report.Unexpected, []string{"regionDetails"}) {
+			t.Errorf("v12 Unexpected = %v, want [regionDetails]", report.Unexpected)
+		}
+		v13, _ := LookupContract("v13")
+		if report, _ := v13.Check(tagged); !report.Compatible {
+			t.Errorf("v13 Check() = %+v, want compatible", report)
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		if _, err := LookupContract("v0"); err == nil {
+			t.Error("LookupContract() error = nil, want error")
+		}
+	})
+}
+
+func TestContract_Project(t *testing.T) {
+	contract, err := LookupContract("v1")
+	if err != nil {
+		t.Fatalf("LookupContract() error = %v", err)
+	}
+
+	fields, dropped, err := contract.Project(models.WeatherLog{
+		Location:    "Planta 3",
+		Temperature: 25.5,
+		Humidity:    65,
+		Pressure:    models.Float64(1013.25),
+		Regions:     []string{"Planta 3"},
+	})
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+	if !reflect.DeepEqual(dropped, []string{"regions"}) {
+		t.Errorf("dropped = %v, want [regions]", dropped)
+	}
+	if report, _ := contract.Check(fields); !report.Compatible {
+		t.Errorf("Check(projected) = %+v, want compatible", report)
+	}
+}
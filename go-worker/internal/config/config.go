@@ -1,66 +1,420 @@
 package config
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"go-worker/internal/geo"
+	"go-worker/internal/models"
 )
 
 // Config armazena as configurações da aplicação
 type Config struct {
-	RabbitMQURL       string
-	NestJSAPIURL      string
+	RabbitMQURL  string
+	NestJSAPIURL string
+	// ForecastAPIURL recebe as previsões, separadas das observações
+	ForecastAPIURL string
+	// AirQualityAPIURL recebe os registros de qualidade do ar
+	AirQualityAPIURL  string
 	QueueName         string
 	WorkerConcurrency int
 	MaxRetryAttempts  int
 	RetryDelay        time.Duration
+	LogLevel          string
+
+	// DeadLetterQueue recebe as mensagens rejeitadas com o motivo nos headers
+	// (vazio mantém o NACK, tratado pela política de dead-letter do broker)
+	DeadLetterQueue string
+	// QuarantineQueue recebe as mensagens de tipo desconhecido (vazio as trata
+	// como as demais rejeitadas)
+	QuarantineQueue string
+	// MessageTypeBindings associa routing keys a tipos de mensagem, usados
+	// quando a mensagem não declara o tipo
+	MessageTypeBindings map[string]string
+	// SchemaStrict rejeita mensagens canônicas com campos desconhecidos ou sem
+	// os campos obrigatórios, em vez de decodificá-las com valores zero
+	SchemaStrict bool
+	// LenientNormalization corrige defeitos conhecidos dos produtores antes da
+	// validação, marcando cada reparo no registro
+	LenientNormalization bool
+
+	// BackendContract é a versão do DTO do backend usada para conferir o payload
+	BackendContract string
+	// AdminAddr é o endereço do servidor administrativo (vazio desabilita)
+	AdminAddr string
+
+	// DescriptionLanguage é o idioma da descrição do tempo (pt-BR, en, es)
+	DescriptionLanguage string
+
+	// MaxObservationAge é a idade máxima aceita para a observação (0 desabilita)
+	MaxObservationAge time.Duration
+
+	// QCWindow é a janela do teste de persistência do controle de qualidade
+	// temporal (0 desabilita o controle)
+	QCWindow time.Duration
+	// QCStateFile guarda o histórico do controle de qualidade entre execuções
+	QCStateFile string
+
+	// AnomalyThreshold é o |z-score| que caracteriza uma anomalia em relação à
+	// linha de base da estação (0 desabilita a detecção)
+	AnomalyThreshold float64
+	// AnomalyWarmUp é o número de leituras de cada hora do dia antes de alertar
+	AnomalyWarmUp int
+	// AnomalyStateFile guarda as linhas de base entre execuções
+	AnomalyStateFile string
+	// AnomalyQueue recebe os eventos de anomalia (vazio apenas registra no log)
+	AnomalyQueue string
+	// AlertQueue recebe os alertas meteorológicos (vazio: alertas vão para a DLQ)
+	AlertQueue string
+	// CommandQueue é a fila dos comandos operacionais (reload, log-level);
+	// vazio desativa os comandos
+	CommandQueue string
+
+	// GazetteerFile substitui os municípios embutidos (CSV nome,uf,latitude,longitude[,populacao])
+	GazetteerFile string
+	// GeoMaxDistanceKm é a distância máxima até o município mais próximo
+	GeoMaxDistanceKm float64
+	// RegionsFile é um FeatureCollection GeoJSON com regiões nomeadas (fazendas, plantas, bairros)
+	RegionsFile string
+	// RegionProperties são as propriedades GeoJSON das regiões repassadas no
+	// campo regionDetails do log
+	RegionProperties []string
+	// StationElevationsFile lista altitudes de estações (CSV latitude,longitude,altitude[,nome])
+	StationElevationsFile string
+	// ElevationGridFile é a grade de altitude de 0,5° gerada por cmd/elevgrid;
+	// vazio desativa a etapa grid da redução da pressão
+	ElevationGridFile string
+	// RulesFile define as regras de qualidade configuráveis (JSON); vazio desativa
+	RulesFile string
+
+	// ConfigFile é o arquivo KEY=VALUE lido além das variáveis de ambiente
+	ConfigFile          string
+	ConfigWatchInterval time.Duration
 }
 
 // Load carrega as configurações das variáveis de ambiente
 func Load() *Config {
+	// Valores inválidos assumem o default; use LoadFile para tratá-los
+	if cfg, _ := LoadFile(os.Getenv("CONFIG_FILE")); cfg != nil {
+		return cfg
+	}
+	// Sem arquivo legível, mantém o comportamento anterior: ambiente e defaults
+	cfg, _ := LoadFile("")
+	return cfg
+}
+
+// LoadFile carrega as configurações combinando o arquivo informado (formato
+// KEY=VALUE, o mesmo do .env) com as variáveis de ambiente, que têm precedência.
+// Um path vazio considera apenas o ambiente. Valores que não puderem ser
+// convertidos assumem o default e são reportados no erro retornado.
+func LoadFile(path string) (*Config, error) {
+	src := &source{}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		src.file = values
+	}
+
 	// Build RabbitMQ URL from components
-	rabbitmqHost := getEnv("RABBITMQ_HOST", "rabbitmq")
-	rabbitmqPort := getEnv("RABBITMQ_PORT", "5672")
-	rabbitmqUser := getEnv("RABBITMQ_USER", "admin")
-	rabbitmqPassword := getEnv("RABBITMQ_PASSWORD", "admin123")
+	rabbitmqHost := src.getEnv("RABBITMQ_HOST", "rabbitmq")
+	rabbitmqPort := src.getEnv("RABBITMQ_PORT", "5672")
+	rabbitmqUser := src.getEnv("RABBITMQ_USER", "admin")
+	rabbitmqPassword := src.getEnv("RABBITMQ_PASSWORD", "admin123")
 	rabbitmqURL := fmt.Sprintf("amqp://%s:%s@%s:%s/", rabbitmqUser, rabbitmqPassword, rabbitmqHost, rabbitmqPort)
 
 	// Build NestJS API URL
-	backendAPIURL := getEnv("BACKEND_API_URL", "http://backend:3000")
-	backendAPIEndpoint := getEnv("BACKEND_API_ENDPOINT", "/api/weather/logs")
+	backendAPIURL := src.getEnv("BACKEND_API_URL", "http://backend:3000")
+	backendAPIEndpoint := src.getEnv("BACKEND_API_ENDPOINT", "/api/weather/logs")
 	fullAPIURL := backendAPIURL + backendAPIEndpoint
+	forecastAPIURL := backendAPIURL + src.getEnv("BACKEND_FORECAST_ENDPOINT", "/api/weather/forecasts")
+	airQualityAPIURL := backendAPIURL + src.getEnv("BACKEND_AIR_QUALITY_ENDPOINT", "/api/weather/air-quality")
 
-	return &Config{
-		RabbitMQURL:       rabbitmqURL,
-		NestJSAPIURL:      fullAPIURL,
-		QueueName:         getEnv("RABBITMQ_QUEUE", "weather_data"),
-		WorkerConcurrency: getEnvAsInt("WORKER_CONCURRENCY", 5),
-		MaxRetryAttempts:  getEnvAsInt("MAX_RETRY_ATTEMPTS", 3),
-		RetryDelay:        getEnvAsDuration("RETRY_DELAY", 2*time.Second),
+	cfg := &Config{
+		RabbitMQURL:           rabbitmqURL,
+		NestJSAPIURL:          fullAPIURL,
+		ForecastAPIURL:        forecastAPIURL,
+		AirQualityAPIURL:      airQualityAPIURL,
+		QueueName:             src.getEnv("RABBITMQ_QUEUE", "weather_data"),
+		DeadLetterQueue:       src.getEnv("DEAD_LETTER_QUEUE", ""),
+		QuarantineQueue:       src.getEnv("QUARANTINE_QUEUE", ""),
+		MessageTypeBindings:   src.getEnvAsBindings("MESSAGE_TYPE_BINDINGS"),
+		SchemaStrict:          src.getEnvAsBool("SCHEMA_STRICT", false),
+		LenientNormalization:  src.getEnvAsBool("LENIENT_NORMALIZATION", false),
+		WorkerConcurrency:     src.getEnvAsInt("WORKER_CONCURRENCY", 5),
+		MaxRetryAttempts:      src.getEnvAsInt("MAX_RETRY_ATTEMPTS", 3),
+		RetryDelay:            src.getEnvAsDuration("RETRY_DELAY", 2*time.Second),
+		LogLevel:              strings.ToLower(src.getEnv("LOG_LEVEL", "info")),
+		BackendContract:       src.getEnv("BACKEND_CONTRACT", "v13"),
+		AdminAddr:             src.getEnv("ADMIN_ADDR", ""),
+		DescriptionLanguage:   src.getEnv("DESCRIPTION_LANGUAGE", models.DefaultLanguage),
+		MaxObservationAge:     src.getEnvAsDuration("MAX_OBSERVATION_AGE", 24*time.Hour),
+		QCWindow:              src.getEnvAsDuration("QC_WINDOW", 6*time.Hour),
+		QCStateFile:           src.getEnv("QC_STATE_FILE", ""),
+		AnomalyThreshold:      src.getEnvAsFloat("ANOMALY_THRESHOLD", 4),
+		AnomalyWarmUp:         src.getEnvAsInt("ANOMALY_WARMUP", 14),
+		AnomalyStateFile:      src.getEnv("ANOMALY_STATE_FILE", ""),
+		AnomalyQueue:          src.getEnv("ANOMALY_QUEUE", ""),
+		AlertQueue:            src.getEnv("ALERT_QUEUE", ""),
+		CommandQueue:          src.getEnv("COMMAND_QUEUE", ""),
+		GazetteerFile:         src.getEnv("GAZETTEER_FILE", ""),
+		GeoMaxDistanceKm:      src.getEnvAsFloat("GEO_MAX_DISTANCE_KM", geo.DefaultMaxDistanceKm),
+		RegionsFile:           src.getEnv("REGIONS_FILE", ""),
+		RegionProperties:      src.getEnvAsList("REGION_PROPERTIES"),
+		StationElevationsFile: src.getEnv("STATION_ELEVATIONS_FILE", ""),
+		ElevationGridFile:     src.getEnv("ELEVATION_GRID_FILE", ""),
+		RulesFile:             src.getEnv("RULES_FILE", ""),
+		ConfigFile:            path,
+		ConfigWatchInterval:   src.getEnvAsDuration("CONFIG_WATCH_INTERVAL", 0),
 	}
+	return cfg, errors.Join(src.errs...)
 }
 
-func getEnv(key, defaultValue string) string {
+// Validate verifica se as configurações são utilizáveis
+func (c *Config) Validate() error {
+	if c.QueueName == "" {
+		return fmt.Errorf("RABBITMQ_QUEUE não pode ser vazio")
+	}
+	if u, err := url.Parse(c.NestJSAPIURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("URL da API inválida: %q", c.NestJSAPIURL)
+	}
+	if u, err := url.Parse(c.ForecastAPIURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("URL da API de previsões inválida: %q", c.ForecastAPIURL)
+	}
+	if c.ForecastAPIURL == c.NestJSAPIURL {
+		return fmt.Errorf("BACKEND_FORECAST_ENDPOINT não pode ser o mesmo endpoint das observações")
+	}
+	if u, err := url.Parse(c.AirQualityAPIURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("URL da API de qualidade do ar inválida: %q", c.AirQualityAPIURL)
+	}
+	if c.AirQualityAPIURL == c.NestJSAPIURL || c.AirQualityAPIURL == c.ForecastAPIURL {
+		return fmt.Errorf("BACKEND_AIR_QUALITY_ENDPOINT não pode ser o endpoint das observações nem o das previsões")
+	}
+	if c.DeadLetterQueue != "" && c.DeadLetterQueue == c.QueueName {
+		return fmt.Errorf("DEAD_LETTER_QUEUE não pode ser a própria RABBITMQ_QUEUE")
+	}
+	if c.QuarantineQueue != "" && (c.QuarantineQueue == c.QueueName || c.QuarantineQueue == c.DeadLetterQueue) {
+		return fmt.Errorf("QUARANTINE_QUEUE não pode ser a RABBITMQ_QUEUE nem a DEAD_LETTER_QUEUE")
+	}
+	if c.WorkerConcurrency < 1 {
+		return fmt.Errorf("WORKER_CONCURRENCY deve ser >= 1, recebido %d", c.WorkerConcurrency)
+	}
+	if c.MaxRetryAttempts < 1 {
+		return fmt.Errorf("MAX_RETRY_ATTEMPTS deve ser >= 1, recebido %d", c.MaxRetryAttempts)
+	}
+	if c.RetryDelay <= 0 {
+		return fmt.Errorf("RETRY_DELAY deve ser positivo, recebido %v", c.RetryDelay)
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("LOG_LEVEL inválido: %q", c.LogLevel)
+	}
+	if _, ok := models.NormalizeLanguage(c.DescriptionLanguage); !ok {
+		return fmt.Errorf("DESCRIPTION_LANGUAGE não suportado: %q (use pt-BR, en ou es)", c.DescriptionLanguage)
+	}
+	if c.MaxObservationAge < 0 {
+		return fmt.Errorf("MAX_OBSERVATION_AGE não pode ser negativo")
+	}
+	if c.QCWindow < 0 {
+		return fmt.Errorf("QC_WINDOW não pode ser negativo")
+	}
+	if c.AnomalyThreshold < 0 {
+		return fmt.Errorf("ANOMALY_THRESHOLD não pode ser negativo")
+	}
+	if c.AnomalyThreshold > 0 && c.AnomalyWarmUp < 1 {
+		return fmt.Errorf("ANOMALY_WARMUP deve ser >= 1, recebido %d", c.AnomalyWarmUp)
+	}
+	if c.AnomalyQueue != "" && (c.AnomalyQueue == c.QueueName || c.AnomalyQueue == c.DeadLetterQueue || c.AnomalyQueue == c.QuarantineQueue) {
+		return fmt.Errorf("ANOMALY_QUEUE não pode ser a RABBITMQ_QUEUE, a DEAD_LETTER_QUEUE nem a QUARANTINE_QUEUE")
+	}
+	if c.AlertQueue != "" && (c.AlertQueue == c.QueueName || c.AlertQueue == c.DeadLetterQueue || c.AlertQueue == c.QuarantineQueue) {
+		return fmt.Errorf("ALERT_QUEUE não pode ser a RABBITMQ_QUEUE, a DEAD_LETTER_QUEUE nem a QUARANTINE_QUEUE")
+	}
+	if c.CommandQueue != "" {
+		for _, other := range []string{c.QueueName, c.DeadLetterQueue, c.QuarantineQueue, c.AnomalyQueue, c.AlertQueue} {
+			if c.CommandQueue == other {
+				return fmt.Errorf("COMMAND_QUEUE deve ser uma fila exclusiva dos comandos")
+			}
+		}
+	}
+	if c.GeoMaxDistanceKm <= 0 {
+		return fmt.Errorf("GEO_MAX_DISTANCE_KM deve ser positivo, recebido %v", c.GeoMaxDistanceKm)
+	}
+	if c.ConfigWatchInterval < 0 {
+		return fmt.Errorf("CONFIG_WATCH_INTERVAL não pode ser negativo")
+	}
+	return nil
+}
+
+// RestartRequired lista as configurações alteradas que só têm efeito após reiniciar o worker
+func RestartRequired(old, updated *Config) []string {
+	var changed []string
+	if old.RabbitMQURL != updated.RabbitMQURL {
+		changed = append(changed, "RABBITMQ_HOST/PORT/USER/PASSWORD")
+	}
+	if old.QueueName != updated.QueueName {
+		changed = append(changed, "RABBITMQ_QUEUE")
+	}
+	if old.DeadLetterQueue != updated.DeadLetterQueue {
+		changed = append(changed, "DEAD_LETTER_QUEUE")
+	}
+	if old.QuarantineQueue != updated.QuarantineQueue {
+		changed = append(changed, "QUARANTINE_QUEUE")
+	}
+	if old.ConfigWatchInterval != updated.ConfigWatchInterval {
+		changed = append(changed, "CONFIG_WATCH_INTERVAL")
+	}
+	if old.AdminAddr != updated.AdminAddr {
+		changed = append(changed, "ADMIN_ADDR")
+	}
+	if old.QCWindow != updated.QCWindow {
+		changed = append(changed, "QC_WINDOW")
+	}
+	if old.QCStateFile != updated.QCStateFile {
+		changed = append(changed, "QC_STATE_FILE")
+	}
+	if old.AnomalyThreshold != updated.AnomalyThreshold || old.AnomalyWarmUp != updated.AnomalyWarmUp {
+		changed = append(changed, "ANOMALY_THRESHOLD/WARMUP")
+	}
+	if old.AnomalyStateFile != updated.AnomalyStateFile {
+		changed = append(changed, "ANOMALY_STATE_FILE")
+	}
+	if old.AnomalyQueue != updated.AnomalyQueue {
+		changed = append(changed, "ANOMALY_QUEUE")
+	}
+	if old.AlertQueue != updated.AlertQueue {
+		changed = append(changed, "ALERT_QUEUE")
+	}
+	if old.CommandQueue != updated.CommandQueue {
+		changed = append(changed, "COMMAND_QUEUE")
+	}
+	return changed
+}
+
+// source resolve valores do ambiente e, na falta deles, do arquivo de configuração
+type source struct {
+	file map[string]string
+	errs []error
+}
+
+func (s *source) lookup(key string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
+	return s.file[key]
+}
+
+func (s *source) getEnv(key, defaultValue string) string {
+	if value := s.lookup(key); value != "" {
+		return value
+	}
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
+func (s *source) getEnvAsInt(key string, defaultValue int) int {
+	valueStr := s.lookup(key)
 	if value, err := strconv.Atoi(valueStr); err == nil {
 		return value
+	} else if valueStr != "" {
+		s.errs = append(s.errs, fmt.Errorf("%s: %w", key, err))
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := s.lookup(key)
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	} else if valueStr != "" {
+		s.errs = append(s.errs, fmt.Errorf("%s: %w", key, err))
 	}
 	return defaultValue
 }
 
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	valueStr := os.Getenv(key)
+func (s *source) getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := s.lookup(key)
 	if value, err := time.ParseDuration(valueStr); err == nil {
 		return value
+	} else if valueStr != "" {
+		s.errs = append(s.errs, fmt.Errorf("%s: %w", key, err))
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := s.lookup(key)
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	} else if valueStr != "" {
+		s.errs = append(s.errs, fmt.Errorf("%s: %w", key, err))
 	}
 	return defaultValue
 }
 
+// getEnvAsList lê uma lista separada por vírgulas, ignorando itens vazios
+func (s *source) getEnvAsList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(s.lookup(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvAsBindings lê uma lista routing.key=tipo separada por vírgulas
+func (s *source) getEnvAsBindings(key string) map[string]string {
+	valueStr := s.lookup(key)
+	if valueStr == "" {
+		return nil
+	}
+	bindings := make(map[string]string)
+	for _, item := range strings.Split(valueStr, ",") {
+		routingKey, messageType, ok := strings.Cut(strings.TrimSpace(item), "=")
+		routingKey, messageType = strings.TrimSpace(routingKey), strings.TrimSpace(messageType)
+		if !ok || routingKey == "" || messageType == "" {
+			s.errs = append(s.errs, fmt.Errorf("%s: esperado routing.key=tipo, recebido %q", key, item))
+			continue
+		}
+		bindings[routingKey] = messageType
+	}
+	return bindings
+}
+
+// readFile lê um arquivo no formato KEY=VALUE, ignorando linhas vazias e comentários
+func readFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de configuração: %w", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: esperado KEY=VALUE", path, lineNum)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		values[strings.TrimSpace(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de configuração: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Applier prepara a aplicação de uma nova configuração. Deve validar tudo o que
+// for necessário e só alterar o estado em execução dentro de commit, que é
+// chamado apenas quando todos os appliers tiverem sucesso.
+type Applier func(old, updated *Config) (commit func(), err error)
+
+// Reloader mantém a configuração em uso e a recarrega sob demanda (SIGHUP) ou
+// quando o arquivo de configuração é alterado
+type Reloader struct {
+	mu        sync.Mutex
+	current   atomic.Pointer[Config]
+	appliers  []Applier
+	overrides []func(*Config)
+	modTime   time.Time
+}
+
+// NewReloader cria um Reloader a partir da configuração inicial. Os overrides
+// (ex.: flags de linha de comando) são reaplicados a cada recarga.
+func NewReloader(cfg *Config, overrides ...func(*Config)) *Reloader {
+	r := &Reloader{overrides: overrides}
+	r.current.Store(cfg)
+	if cfg.ConfigFile != "" {
+		if info, err := os.Stat(cfg.ConfigFile); err == nil {
+			r.modTime = info.ModTime()
+		}
+	}
+	return r
+}
+
+// Current retorna a configuração em uso
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload registra um applier chamado a cada recarga
+func (r *Reloader) OnReload(a Applier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appliers = append(r.appliers, a)
+}
+
+// Reload relê e valida a configuração e aplica o que pode mudar sem reiniciar.
+// Em caso de erro a configuração em uso permanece intacta.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.current.Load()
+	updated, err := LoadFile(old.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar configuração: %w", err)
+	}
+	for _, override := range r.overrides {
+		override(updated)
+	}
+	if err := updated.Validate(); err != nil {
+		return fmt.Errorf("configuração inválida: %w", err)
+	}
+
+	for _, key := range RestartRequired(old, updated) {
+		log.Printf("[WARN] %s alterado, mas só terá efeito após reiniciar o worker", key)
+	}
+	// Mantém os valores em uso para o que exige restart
+	updated.RabbitMQURL = old.RabbitMQURL
+	updated.QueueName = old.QueueName
+	updated.ConfigWatchInterval = old.ConfigWatchInterval
+	updated.AdminAddr = old.AdminAddr
+
+	commits := make([]func(), 0, len(r.appliers))
+	for _, apply := range r.appliers {
+		commit, err := apply(old, updated)
+		if err != nil {
+			return fmt.Errorf("configuração rejeitada: %w", err)
+		}
+		if commit != nil {
+			commits = append(commits, commit)
+		}
+	}
+	for _, commit := range commits {
+		commit()
+	}
+
+	r.current.Store(updated)
+	return nil
+}
+
+// Watch verifica periodicamente a data de modificação do arquivo de
+// configuração e recarrega quando ela muda
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	path := r.Current().ConfigFile
+	if path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				log.Printf("[WARN] Erro ao verificar arquivo de configuração: %v", err)
+				continue
+			}
+			if !info.ModTime().After(r.modTime) {
+				continue
+			}
+			r.modTime = info.ModTime()
+
+			log.Printf("[INFO] Arquivo de configuração alterado, recarregando: %s", path)
+			if err := r.Reload(); err != nil {
+				log.Printf("[ERROR] Recarga falhou, mantendo configuração atual: %v", err)
+				continue
+			}
+			log.Println("[INFO] Configuração recarregada")
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	for _, key := range []string{"MAX_RETRY_ATTEMPTS", "RETRY_DELAY", "LOG_LEVEL", "RABBITMQ_QUEUE", "WORKER_CONCURRENCY"} {
+		t.Setenv(key, "")
+	}
+
+	path := writeConfigFile(t, "MAX_RETRY_ATTEMPTS=3\nRABBITMQ_QUEUE=weather_data\n")
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	t.Run("applies reloadable settings", func(t *testing.T) {
+		r := NewReloader(cfg)
+		var applied *Config
+		r.OnReload(func(old, updated *Config) (func(), error) {
+			return func() { applied = updated }, nil
+		})
+
+		os.WriteFile(path, []byte("MAX_RETRY_ATTEMPTS=9\nRABBITMQ_QUEUE=other_queue\n"), 0o644)
+		if err := r.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		if applied == nil || applied.MaxRetryAttempts != 9 {
+			t.Fatalf("applier not called with new config: %+v", applied)
+		}
+		if r.Current().MaxRetryAttempts != 9 {
+			t.Errorf("Current().MaxRetryAttempts = %v, want 9", r.Current().MaxRetryAttempts)
+		}
+		// A fila exige restart e continua com o valor em uso
+		if r.Current().QueueName != "weather_data" {
+			t.Errorf("Current().QueueName = %v, want weather_data", r.Current().QueueName)
+		}
+	})
+
+	t.Run("invalid config keeps current", func(t *testing.T) {
+		r := NewReloader(cfg)
+		called := false
+		r.OnReload(func(old, updated *Config) (func(), error) {
+			return func() { called = true }, nil
+		})
+
+		os.WriteFile(path, []byte("MAX_RETRY_ATTEMPTS=0\n"), 0o644)
+		if err := r.Reload(); err == nil {
+			t.Fatal("Reload() error = nil, want validation error")
+		}
+		if called {
+			t.Error("applier committed despite invalid config")
+		}
+		if r.Current() != cfg {
+			t.Error("Current() changed after failed reload")
+		}
+	})
+
+	t.Run("rejected by applier keeps current", func(t *testing.T) {
+		r := NewReloader(cfg)
+		committed := false
+		r.OnReload(func(old, updated *Config) (func(), error) {
+			return func() { committed = true }, nil
+		})
+		r.OnReload(func(old, updated *Config) (func(), error) {
+			return nil, errors.New("rules file invalid")
+		})
+
+		os.WriteFile(path, []byte("MAX_RETRY_ATTEMPTS=4\n"), 0o644)
+		if err := r.Reload(); err == nil {
+			t.Fatal("Reload() error = nil, want applier error")
+		}
+		if committed {
+			t.Error("first applier committed although second rejected the config")
+		}
+		if r.Current().MaxRetryAttempts != 3 {
+			t.Errorf("Current().MaxRetryAttempts = %v, want 3", r.Current().MaxRetryAttempts)
+		}
+	})
+
+	t.Run("missing file keeps current", func(t *testing.T) {
+		missing := *cfg
+		missing.ConfigFile = path + ".missing"
+		r := NewReloader(&missing)
+		if err := r.Reload(); err == nil {
+			t.Fatal("Reload() error = nil, want file error")
+		}
+		if r.Current().RetryDelay != 2*time.Second {
+			t.Errorf("Current().RetryDelay = %v, want 2s", r.Current().RetryDelay)
+		}
+	})
+}
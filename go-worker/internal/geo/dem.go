@@ -0,0 +1,184 @@
+package geo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bounds é um retângulo em graus
+type Bounds struct {
+	MinLat, MaxLat, MinLon, MaxLon float64
+}
+
+// BrazilBounds cobre o território brasileiro com folga
+var BrazilBounds = Bounds{MinLat: -34, MaxLat: 6, MinLon: -74, MaxLon: -28}
+
+func (b Bounds) contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// GridCell é uma célula da grade de altitude: centro, média em metros e número
+// de pixels do modelo de elevação que a compõem
+type GridCell struct {
+	Latitude  float64
+	Longitude float64
+	Elevation float64
+	Samples   int
+}
+
+// AggregateDEM lê um modelo digital de elevação no formato ESRI ASCII Grid
+// (gdal_translate -of AAIGrid) e calcula a média dos pixels de terra de cada
+// célula de cellSize graus dentro de bounds. Pixels sem dado e abaixo do
+// nível do mar (oceano nos modelos com batimetria, como o ETOPO) são
+// ignorados; células sem pixel de terra ficam de fora. As células saem do
+// norte para o sul e de oeste para leste.
+func AggregateDEM(r io.Reader, bounds Bounds, cellSize float64) ([]GridCell, error) {
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("modelo de elevação: tamanho de célula deve ser positivo, recebido %v", cellSize)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	header, first, err := readASCIIGridHeader(scanner)
+	if err != nil {
+		return nil, err
+	}
+
+	type sum struct {
+		total float64
+		n     int
+	}
+	sums := make(map[cellKey]*sum)
+	cellOf := func(lat, lon float64) cellKey {
+		return cellKey{int(math.Floor(lat / cellSize)), int(math.Floor(lon / cellSize))}
+	}
+
+	token, pending := first, true
+	for row := 0; row < header.rows; row++ {
+		lat := header.lat0 + float64(header.rows-1-row)*header.cellSize
+		for col := 0; col < header.cols; col++ {
+			if !pending {
+				if !scanner.Scan() {
+					if err := scanner.Err(); err != nil {
+						return nil, fmt.Errorf("modelo de elevação: %w", err)
+					}
+					return nil, fmt.Errorf("modelo de elevação: esperados %d valores, encontrados %d", header.rows*header.cols, row*header.cols+col)
+				}
+				token = scanner.Text()
+			}
+			pending = false
+
+			lon := header.lon0 + float64(col)*header.cellSize
+			if !bounds.contains(lat, lon) {
+				continue
+			}
+			v, err := strconv.ParseFloat(token, 64)
+			if err != nil {
+				return nil, fmt.Errorf("modelo de elevação: linha %d, coluna %d: %w", row+1, col+1, err)
+			}
+			if (header.hasNoData && v == header.noData) || v < 0 {
+				continue
+			}
+			key := cellOf(lat, lon)
+			s := sums[key]
+			if s == nil {
+				s = &sum{}
+				sums[key] = s
+			}
+			s.total += v
+			s.n++
+		}
+	}
+
+	cells := make([]GridCell, 0, len(sums))
+	for key, s := range sums {
+		cells = append(cells, GridCell{
+			Latitude:  (float64(key.lat) + 0.5) * cellSize,
+			Longitude: (float64(key.lon) + 0.5) * cellSize,
+			Elevation: math.Round(s.total / float64(s.n)),
+			Samples:   s.n,
+		})
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Latitude != cells[j].Latitude {
+			return cells[i].Latitude > cells[j].Latitude
+		}
+		return cells[i].Longitude < cells[j].Longitude
+	})
+	return cells, nil
+}
+
+// asciiGridHeader é o cabeçalho do ESRI ASCII Grid, com lat0/lon0 no centro
+// do pixel do canto inferior esquerdo
+type asciiGridHeader struct {
+	cols, rows int
+	lat0, lon0 float64
+	cellSize   float64
+	noData     float64
+	hasNoData  bool
+	// set registra as chaves lidas, para exigir as obrigatórias
+	set map[string]bool
+}
+
+// readASCIIGridHeader lê as chaves do cabeçalho e retorna o primeiro valor da
+// grade, já consumido do scanner
+func readASCIIGridHeader(scanner *bufio.Scanner) (asciiGridHeader, string, error) {
+	h := asciiGridHeader{set: make(map[string]bool)}
+	var xll, yll float64
+	centered := false
+	for scanner.Scan() {
+		key := strings.ToLower(scanner.Text())
+		if _, err := strconv.ParseFloat(key, 64); err == nil {
+			for _, required := range []string{"ncols", "nrows", "xll", "yll", "cellsize"} {
+				if !h.set[required] {
+					return h, "", fmt.Errorf("modelo de elevação: cabeçalho sem %s", required)
+				}
+			}
+			if h.cols <= 0 || h.rows <= 0 || h.cellSize <= 0 {
+				return h, "", fmt.Errorf("modelo de elevação: cabeçalho inválido (%dx%d, célula %v)", h.cols, h.rows, h.cellSize)
+			}
+			h.lon0, h.lat0 = xll, yll
+			if !centered {
+				h.lon0 += h.cellSize / 2
+				h.lat0 += h.cellSize / 2
+			}
+			return h, scanner.Text(), nil
+		}
+		if !scanner.Scan() {
+			break
+		}
+		value, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return h, "", fmt.Errorf("modelo de elevação: cabeçalho %s: %w", key, err)
+		}
+		switch key {
+		case "ncols":
+			h.cols = int(value)
+		case "nrows":
+			h.rows = int(value)
+		case "xllcorner", "xllcenter":
+			xll, centered = value, key == "xllcenter"
+			key = "xll"
+		case "yllcorner", "yllcenter":
+			yll = value
+			key = "yll"
+		case "cellsize":
+			h.cellSize = value
+		case "nodata_value":
+			h.noData, h.hasNoData = value, true
+		default:
+			return h, "", fmt.Errorf("modelo de elevação: chave de cabeçalho desconhecida %q", key)
+		}
+		h.set[key] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return h, "", fmt.Errorf("modelo de elevação: %w", err)
+	}
+	return h, "", fmt.Errorf("modelo de elevação: grade vazia")
+}
@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAggregateDEM(t *testing.T) {
+	// 4x4 pixels de 0,25° a partir de (-24, -47): as células de 0,5° são os
+	// quadrantes; o quadrante sudeste é só oceano e um pixel não tem dado
+	dem := `ncols 4
+nrows 4
+xllcorner -47
+yllcorner -24
+cellsize 0.25
+NODATA_value -9999
+800 820  -5 10
+780 -9999 0 20
+700 720 -30 -40
+740 760 -50 -60
+`
+	cells, err := AggregateDEM(strings.NewReader(dem), BrazilBounds, 0.5)
+	if err != nil {
+		t.Fatalf("AggregateDEM() error = %v", err)
+	}
+	want := []GridCell{
+		{Latitude: -23.25, Longitude: -46.75, Elevation: 800, Samples: 3},
+		{Latitude: -23.25, Longitude: -46.25, Elevation: 10, Samples: 3},
+		{Latitude: -23.75, Longitude: -46.75, Elevation: 730, Samples: 4},
+	}
+	if !reflect.DeepEqual(cells, want) {
+		t.Errorf("AggregateDEM() = %+v, want %+v", cells, want)
+	}
+
+	t.Run("outside bounds", func(t *testing.T) {
+		cells, err := AggregateDEM(strings.NewReader(dem), Bounds{MinLat: 0, MaxLat: 5, MinLon: -60, MaxLon: -50}, 0.5)
+		if err != nil || len(cells) != 0 {
+			t.Errorf("AggregateDEM() = %v, %v; want no cells", cells, err)
+		}
+	})
+
+	for name, bad := range map[string]string{
+		"missing cellsize": "ncols 1\nnrows 1\nxllcorner 0\nyllcorner 0\n5\n",
+		"truncated":        "ncols 2\nnrows 2\nxllcorner -47\nyllcorner -24\ncellsize 0.25\n1 2 3\n",
+		"unknown key":      "ncols 1\nnrows 1\nxllcorner 0\nyllcorner 0\ncellsize 1\nbyteorder 1\n5\n",
+		"empty":            "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := AggregateDEM(strings.NewReader(bad), BrazilBounds, 0.5); err == nil {
+				t.Error("AggregateDEM() error = nil, want error")
+			}
+		})
+	}
+}
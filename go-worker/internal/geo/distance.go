@@ -0,0 +1,21 @@
+package geo
+
+import "math"
+
+// earthRadiusKm é o raio médio da Terra
+const earthRadiusKm = 6371.0088
+
+// kmPerDegree é o comprimento de um grau de latitude
+const kmPerDegree = 2 * math.Pi * earthRadiusKm / 360
+
+// DistanceKm calcula a distância de grande círculo (haversine) entre dois pontos
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,181 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Origens possíveis da altitude usada na redução da pressão
+const (
+	ElevationFromStation = "station"
+	ElevationFromMessage = "message"
+	ElevationFromGrid    = "grid"
+)
+
+// DefaultStationMatchKm é a distância máxima entre a mensagem e uma estação
+// configurada para que a altitude da estação seja usada
+const DefaultStationMatchKm = 1.0
+
+// elevationCellSize é o tamanho, em graus, das células da grade de altitude
+// (ELEVATION_GRID_FILE, gerada por cmd/elevgrid)
+const elevationCellSize = 0.5
+
+// ElevationGrid é uma grade regular de altitudes (m); células sem dado não
+// resolvem
+type ElevationGrid struct {
+	cells map[cellKey]float64
+}
+
+func elevationCellOf(lat, lon float64) cellKey {
+	return cellKey{int(math.Floor(lat / elevationCellSize)), int(math.Floor(lon / elevationCellSize))}
+}
+
+// Len retorna o número de células com altitude
+func (g *ElevationGrid) Len() int {
+	return len(g.cells)
+}
+
+// Lookup retorna a altitude da célula que contém o ponto
+func (g *ElevationGrid) Lookup(lat, lon float64) (float64, bool) {
+	elevation, ok := g.cells[elevationCellOf(lat, lon)]
+	return elevation, ok
+}
+
+// StationElevation é a altitude conhecida de uma estação
+type StationElevation struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Elevation float64
+}
+
+// ElevationResolver escolhe a altitude de uma coordenada: estação configurada
+// mais próxima (até MatchKm) e, na falta dela, a grade grosseira, quando
+// configurada. A altitude informada na mensagem é tratada pelo chamador, entre
+// as duas.
+type ElevationResolver struct {
+	Stations []StationElevation
+	MatchKm  float64
+	Grid     *ElevationGrid
+}
+
+// Station retorna a altitude da estação configurada mais próxima do ponto
+func (r *ElevationResolver) Station(lat, lon float64) (float64, bool) {
+	best, bestDist := -1, math.Inf(1)
+	for i, st := range r.Stations {
+		if d := DistanceKm(lat, lon, st.Latitude, st.Longitude); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	if best < 0 || bestDist > r.MatchKm {
+		return 0, false
+	}
+	return r.Stations[best].Elevation, true
+}
+
+// GridElevation retorna a altitude da grade, quando configurada
+func (r *ElevationResolver) GridElevation(lat, lon float64) (float64, bool) {
+	if r.Grid == nil {
+		return 0, false
+	}
+	return r.Grid.Lookup(lat, lon)
+}
+
+// parseElevationCSV lê linhas latitude,longitude,altitude[,nome], ignorando
+// comentários e o cabeçalho
+func parseElevationCSV(r io.Reader, what string) ([]StationElevation, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	var rows []StationElevation
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", what, err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "latitude") {
+			continue
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("%s: linha %d: esperado latitude,longitude,altitude", what, line)
+		}
+
+		var values [3]float64
+		for i, field := range []string{"latitude", "longitude", "altitude"} {
+			if values[i], err = strconv.ParseFloat(strings.TrimSpace(record[i]), 64); err != nil {
+				return nil, fmt.Errorf("%s: linha %d: %s: %w", what, line, field, err)
+			}
+		}
+		row := StationElevation{Latitude: values[0], Longitude: values[1], Elevation: values[2]}
+		if len(record) > 3 {
+			row.Name = strings.TrimSpace(record[3])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// LoadStationElevations carrega as altitudes por estação de um CSV
+// latitude,longitude,altitude[,nome]
+func LoadStationElevations(path string) ([]StationElevation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("altitudes das estações: %w", err)
+	}
+	defer f.Close()
+	return parseElevationCSV(f, "altitudes das estações")
+}
+
+// LoadElevationGrid carrega uma grade de altitude de 0,5° gerada por
+// cmd/elevgrid (CSV latitude,longitude,altitude com o centro de cada célula)
+func LoadElevationGrid(path string) (*ElevationGrid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("grade de altitude: %w", err)
+	}
+	defer f.Close()
+	rows, err := parseElevationCSV(f, "grade de altitude")
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]GridCell, len(rows))
+	for i, row := range rows {
+		cells[i] = GridCell{Latitude: row.Latitude, Longitude: row.Longitude, Elevation: row.Elevation}
+	}
+	return NewElevationGrid(cells), nil
+}
+
+// NewElevationGrid monta a grade de 0,5° a partir das células
+func NewElevationGrid(cells []GridCell) *ElevationGrid {
+	g := &ElevationGrid{cells: make(map[cellKey]float64, len(cells))}
+	for _, c := range cells {
+		g.cells[elevationCellOf(c.Latitude, c.Longitude)] = c.Elevation
+	}
+	return g
+}
+
+var defaultElevations atomic.Pointer[ElevationResolver]
+
+// DefaultElevations retorna o resolver de altitude usado por
+// WeatherMessage.ToWeatherLog; sem configuração não há estações nem grade
+func DefaultElevations() *ElevationResolver {
+	if r := defaultElevations.Load(); r != nil {
+		return r
+	}
+	return &ElevationResolver{MatchKm: DefaultStationMatchKm}
+}
+
+// SetDefaultElevations substitui o resolver de altitude padrão
+func SetDefaultElevations(r *ElevationResolver) {
+	defaultElevations.Store(r)
+}
@@ -0,0 +1,225 @@
+package geo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMaxDistanceKm é a distância máxima até a sede do município para que
+// uma coordenada receba o seu nome. A lista embutida é parcial: com um raio
+// maior, pontos em municípios ausentes receberiam o nome de um vizinho listado.
+// Com a lista completa gerada por cmd/gazetteer, use o raio sugerido no
+// cabeçalho do CSV, calculado do espaçamento entre as sedes.
+const DefaultMaxDistanceKm = 10
+
+//go:embed data/municipios.csv
+var embeddedMunicipios []byte
+
+// Place é um município do gazetteer
+type Place struct {
+	Name       string  `json:"name"`
+	UF         string  `json:"uf"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Population int     `json:"population,omitempty"`
+}
+
+// Label retorna o nome no formato usado pelo dashboard ("Campinas, SP")
+func (p Place) Label() string {
+	return p.Name + ", " + p.UF
+}
+
+// cellSize é o tamanho, em graus, das células do índice espacial
+const cellSize = 1.0
+
+type cellKey struct {
+	lat, lon int
+}
+
+func cellOf(lat, lon float64) cellKey {
+	return cellKey{int(math.Floor(lat / cellSize)), int(math.Floor(lon / cellSize))}
+}
+
+// Gazetteer resolve coordenadas para o município mais próximo usando uma
+// grade regular de células como índice espacial
+type Gazetteer struct {
+	places []Place
+	cells  map[cellKey][]int
+}
+
+// NewGazetteer indexa os municípios informados
+func NewGazetteer(places []Place) *Gazetteer {
+	g := &Gazetteer{
+		places: places,
+		cells:  make(map[cellKey][]int),
+	}
+	for i, p := range places {
+		key := cellOf(p.Latitude, p.Longitude)
+		g.cells[key] = append(g.cells[key], i)
+	}
+	return g
+}
+
+// Len retorna o número de municípios indexados
+func (g *Gazetteer) Len() int {
+	return len(g.places)
+}
+
+// Nearest retorna o município mais próximo dentro de maxKm. A busca percorre
+// anéis de células ao redor do ponto e para quando o anel seguinte já está
+// mais distante que o melhor candidato ou que o limite.
+func (g *Gazetteer) Nearest(lat, lon, maxKm float64) (Place, float64, bool) {
+	center := cellOf(lat, lon)
+	best, bestDist := -1, math.Inf(1)
+
+	maxRing := int(math.Ceil(maxKm/(kmPerDegree*cellSize*minCos(lat, maxKm)))) + 1
+	for ring := 0; ring <= maxRing; ring++ {
+		// Distância mínima possível até qualquer célula deste anel
+		if ring > 1 {
+			lowerBound := float64(ring-1) * cellSize * kmPerDegree * minCos(lat, float64(ring)*cellSize*kmPerDegree)
+			if lowerBound > bestDist || lowerBound > maxKm {
+				break
+			}
+		}
+		for dLat := -ring; dLat <= ring; dLat++ {
+			for dLon := -ring; dLon <= ring; dLon++ {
+				if abs(dLat) != ring && abs(dLon) != ring {
+					continue // apenas a borda do anel
+				}
+				for _, i := range g.cells[cellKey{center.lat + dLat, center.lon + dLon}] {
+					p := g.places[i]
+					if d := DistanceKm(lat, lon, p.Latitude, p.Longitude); d < bestDist {
+						best, bestDist = i, d
+					}
+				}
+			}
+		}
+	}
+
+	if best < 0 || bestDist > maxKm {
+		return Place{}, 0, false
+	}
+	return g.places[best], bestDist, true
+}
+
+// minCos é o menor cosseno de latitude em um raio de km ao redor de lat,
+// usado para converter graus de longitude em distância de forma conservadora
+func minCos(lat, km float64) float64 {
+	extreme := math.Min(89, math.Abs(lat)+km/kmPerDegree)
+	return math.Cos(extreme * math.Pi / 180)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// ParseCSV lê municípios no formato nome,uf,latitude,longitude[,populacao].
+// Linhas iniciadas com '#' e o cabeçalho são ignorados.
+func ParseCSV(r io.Reader) ([]Place, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	var places []Place
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer: %w", err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "nome") {
+			continue
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("gazetteer: linha %d: esperado nome,uf,latitude,longitude", line)
+		}
+
+		lat, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer: linha %d: latitude: %w", line, err)
+		}
+		lon, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer: linha %d: longitude: %w", line, err)
+		}
+		place := Place{Name: record[0], UF: record[1], Latitude: lat, Longitude: lon}
+		if len(record) > 4 && record[4] != "" {
+			if place.Population, err = strconv.Atoi(record[4]); err != nil {
+				return nil, fmt.Errorf("gazetteer: linha %d: população: %w", line, err)
+			}
+		}
+		places = append(places, place)
+	}
+	return places, nil
+}
+
+// LoadFile carrega um gazetteer de um arquivo CSV (ex.: a lista completa do IBGE)
+func LoadFile(path string) (*Gazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("gazetteer: %w", err)
+	}
+	defer f.Close()
+
+	places, err := ParseCSV(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewGazetteer(places), nil
+}
+
+// Resolver combina o gazetteer com a distância máxima aceita
+type Resolver struct {
+	Gazetteer     *Gazetteer
+	MaxDistanceKm float64
+}
+
+// Resolve retorna o município mais próximo dentro da distância máxima
+func (r *Resolver) Resolve(lat, lon float64) (Place, float64, bool) {
+	return r.Gazetteer.Nearest(lat, lon, r.MaxDistanceKm)
+}
+
+var (
+	embeddedOnce      sync.Once
+	embeddedGazetteer *Gazetteer
+	defaultResolver   atomic.Pointer[Resolver]
+)
+
+// Embedded retorna o gazetteer embutido no binário com os principais municípios
+func Embedded() *Gazetteer {
+	embeddedOnce.Do(func() {
+		places, err := ParseCSV(bytes.NewReader(embeddedMunicipios))
+		if err != nil {
+			panic(err) // arquivo embutido validado pelos testes
+		}
+		embeddedGazetteer = NewGazetteer(places)
+	})
+	return embeddedGazetteer
+}
+
+// DefaultResolver retorna o resolver usado por WeatherMessage.GetLocationString
+func DefaultResolver() *Resolver {
+	if r := defaultResolver.Load(); r != nil {
+		return r
+	}
+	return &Resolver{Gazetteer: Embedded(), MaxDistanceKm: DefaultMaxDistanceKm}
+}
+
+// SetDefaultResolver substitui o resolver padrão (arquivo ou distância configurados)
+func SetDefaultResolver(r *Resolver) {
+	defaultResolver.Store(r)
+}
@@ -0,0 +1,167 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDistanceKm(t *testing.T) {
+	// São Paulo - Rio de Janeiro: ~357 km
+	d := DistanceKm(-23.5505, -46.6333, -22.9068, -43.1729)
+	if math.Abs(d-357) > 5 {
+		t.Errorf("DistanceKm(SP, RJ) = %.1f, want ~357", d)
+	}
+	if d := DistanceKm(-23.5, -46.6, -23.5, -46.6); d != 0 {
+		t.Errorf("DistanceKm(same point) = %v, want 0", d)
+	}
+}
+
+func TestEmbedded_Nearest(t *testing.T) {
+	g := Embedded()
+	if g.Len() < 150 {
+		t.Fatalf("embedded gazetteer has %d places, want at least 150", g.Len())
+	}
+
+	tests := []struct {
+		name      string
+		lat, lon  float64
+		wantLabel string
+		wantFound bool
+	}{
+		{"São Paulo centro", -23.5505, -46.6333, "São Paulo, SP", true},
+		{"Campinas", -22.91, -47.06, "Campinas, SP", true},
+		{"Rio de Janeiro", -22.9068, -43.1729, "Rio de Janeiro, RJ", true},
+		{"Manaus", -3.10, -60.02, "Manaus, AM", true},
+		{"Macapá (hemisfério norte)", 0.03, -51.07, "Macapá, AP", true},
+		{"Oceano Atlântico", -25.0, -40.0, "", false},
+		{"Interior sem município próximo", -10.0, -55.0, "", false},
+		// Cajamar não está na lista parcial; Santana de Parnaíba fica a ~11 km
+		{"Município ausente não herda o vizinho", -23.355, -46.8781, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			place, _, found := g.Nearest(tt.lat, tt.lon, DefaultMaxDistanceKm)
+			if found != tt.wantFound {
+				t.Fatalf("Nearest() found = %v, want %v (%+v)", found, tt.wantFound, place)
+			}
+			if found && place.Label() != tt.wantLabel {
+				t.Errorf("Nearest() = %v, want %v", place.Label(), tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestGazetteer_NearestMatchesLinearScan(t *testing.T) {
+	g := Embedded()
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		lat := -33 + rng.Float64()*38
+		lon := -73 + rng.Float64()*40
+		maxKm := 50 + rng.Float64()*400
+
+		want, wantDist := -1, math.Inf(1)
+		for j, p := range g.places {
+			if d := DistanceKm(lat, lon, p.Latitude, p.Longitude); d < wantDist {
+				want, wantDist = j, d
+			}
+		}
+
+		place, dist, found := g.Nearest(lat, lon, maxKm)
+		if wantDist > maxKm {
+			if found {
+				t.Fatalf("(%.3f,%.3f) max %.0f: found %v at %.1f km, want none", lat, lon, maxKm, place.Label(), dist)
+			}
+			continue
+		}
+		if !found || place != g.places[want] {
+			t.Fatalf("(%.3f,%.3f) max %.0f: got %v (%v), want %v at %.1f km",
+				lat, lon, maxKm, place.Label(), found, g.places[want].Label(), wantDist)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	input := "# comentário\nnome,uf,latitude,longitude,populacao\nCampinas,SP,-22.9056,-47.0608,1139047\n\"Santa Bárbara d'Oeste\",SP,-22.7553,-47.4143,\n"
+	places, err := ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(places) != 2 {
+		t.Fatalf("len = %d, want 2", len(places))
+	}
+	if places[0].Population != 1139047 || places[1].Population != 0 {
+		t.Errorf("populations = %d, %d", places[0].Population, places[1].Population)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("Campinas,SP,abc,-47\n")); err == nil {
+		t.Error("ParseCSV() error = nil for invalid latitude")
+	}
+}
+
+func TestSunElevation(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		time     string
+		want     float64
+	}{
+		// Valores de referência da calculadora solar da NOAA
+		{"São Paulo, meio-dia solar no solstício de junho", -23.5505, -46.6333, "2025-06-21T15:07:00Z", 42.9},
+		{"Equador, equinócio ao meio-dia solar", 0, 0, "2025-03-20T12:07:00Z", 89.8},
+		{"São Paulo, meia-noite", -23.5505, -46.6333, "2025-06-21T03:00:00Z", -89.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := SunElevation(tt.lat, tt.lon, at); math.Abs(got-tt.want) > 1 {
+				t.Errorf("SunElevation() = %.2f, want ~%.1f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadElevationGrid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "elevacao.csv")
+	data := "# gerada por cmd/elevgrid\nlatitude,longitude,altitude\n-15.75,-47.75,1120\n-8.25,-34.75,12\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	g, err := LoadElevationGrid(path)
+	if err != nil {
+		t.Fatalf("LoadElevationGrid() error = %v", err)
+	}
+	if g.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", g.Len())
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     float64
+		wantOK   bool
+	}{
+		{"Brasília", -15.79, -47.88, 1120, true},
+		{"Recife", -8.05, -34.88, 12, true},
+		{"cell without data", -25, -40, 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := g.Lookup(tt.lat, tt.lon); got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: Lookup() = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if DefaultElevations().Grid != nil {
+		t.Error("DefaultElevations() has a grid without ELEVATION_GRID_FILE")
+	}
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ufByCode mapeia o código IBGE da UF para a sigla
+var ufByCode = map[string]string{
+	"11": "RO", "12": "AC", "13": "AM", "14": "RR", "15": "PA", "16": "AP", "17": "TO",
+	"21": "MA", "22": "PI", "23": "CE", "24": "RN", "25": "PB", "26": "PE", "27": "AL", "28": "SE", "29": "BA",
+	"31": "MG", "32": "ES", "33": "RJ", "35": "SP",
+	"41": "PR", "42": "SC", "43": "RS",
+	"50": "MS", "51": "MT", "52": "GO", "53": "DF",
+}
+
+// ParseIBGEMunicipios lê a lista de municípios com as colunas codigo_ibge,
+// nome, latitude, longitude e codigo_uf, em qualquer ordem, como no
+// municipios.csv do repositório kelvins/municipios-brasileiros (dados do
+// IBGE). A coluna opcional populacao é repassada. Os municípios saem
+// ordenados por UF e nome.
+func ParseIBGEMunicipios(r io.Reader) ([]Place, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("municípios do IBGE: cabeçalho: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"codigo_ibge", "nome", "latitude", "longitude", "codigo_uf"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("municípios do IBGE: coluna %s ausente", required)
+		}
+	}
+	population, hasPopulation := columns["populacao"]
+
+	var places []Place
+	seen := make(map[string]bool)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("municípios do IBGE: %w", err)
+		}
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		code := field("codigo_ibge")
+		if seen[code] {
+			return nil, fmt.Errorf("municípios do IBGE: linha %d: código %s repetido", line, code)
+		}
+		seen[code] = true
+		uf, ok := ufByCode[field("codigo_uf")]
+		if !ok {
+			return nil, fmt.Errorf("municípios do IBGE: linha %d: código de UF desconhecido %q", line, field("codigo_uf"))
+		}
+		lat, err := strconv.ParseFloat(field("latitude"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("municípios do IBGE: linha %d: latitude: %w", line, err)
+		}
+		lon, err := strconv.ParseFloat(field("longitude"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("municípios do IBGE: linha %d: longitude: %w", line, err)
+		}
+		if !BrazilBounds.contains(lat, lon) {
+			return nil, fmt.Errorf("municípios do IBGE: linha %d: %s fora do Brasil (%v, %v)", line, field("nome"), lat, lon)
+		}
+		place := Place{Name: field("nome"), UF: uf, Latitude: lat, Longitude: lon}
+		if hasPopulation && population < len(record) && strings.TrimSpace(record[population]) != "" {
+			if place.Population, err = strconv.Atoi(strings.TrimSpace(record[population])); err != nil {
+				return nil, fmt.Errorf("municípios do IBGE: linha %d: população: %w", line, err)
+			}
+		}
+		places = append(places, place)
+	}
+
+	sort.SliceStable(places, func(i, j int) bool {
+		if places[i].UF != places[j].UF {
+			return places[i].UF < places[j].UF
+		}
+		return places[i].Name < places[j].Name
+	})
+	return places, nil
+}
+
+// SeatSpacingKm retorna o quantil q (0 a 1) da distância de cada sede à sede
+// vizinha mais próxima. Metade da mediana é a escolha de GEO_MAX_DISTANCE_KM
+// para a lista completa: um ponto mais distante que isso de qualquer sede
+// provavelmente está num município maior que o típico, e o nome da sede mais
+// próxima deixa de ser confiável.
+func SeatSpacingKm(places []Place, q float64) float64 {
+	if len(places) < 2 {
+		return 0
+	}
+	nearest := make([]float64, len(places))
+	for i := range nearest {
+		nearest[i] = math.Inf(1)
+	}
+	for i, a := range places {
+		for j := i + 1; j < len(places); j++ {
+			d := DistanceKm(a.Latitude, a.Longitude, places[j].Latitude, places[j].Longitude)
+			nearest[i] = math.Min(nearest[i], d)
+			nearest[j] = math.Min(nearest[j], d)
+		}
+	}
+	sort.Float64s(nearest)
+	return nearest[int(math.Round(q*float64(len(nearest)-1)))]
+}
@@ -0,0 +1,64 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseIBGEMunicipios(t *testing.T) {
+	data := "\ufeffcodigo_ibge,nome,latitude,longitude,capital,codigo_uf\n" +
+		"3556701,Vinhedo,-23.0302,-46.9833,0,35\n" +
+		"3509502,Campinas,-22.9053,-47.0659,0,35\n" +
+		"5300108,Brasília,-15.7795,-47.9297,1,53\n"
+	places, err := ParseIBGEMunicipios(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseIBGEMunicipios() error = %v", err)
+	}
+	want := []string{"Brasília, DF", "Campinas, SP", "Vinhedo, SP"}
+	if len(places) != len(want) {
+		t.Fatalf("places = %+v", places)
+	}
+	for i, label := range want {
+		if places[i].Label() != label {
+			t.Errorf("places[%d] = %s, want %s", i, places[i].Label(), label)
+		}
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"missing column", "codigo_ibge,nome,latitude,longitude\n", "codigo_uf"},
+		{"unknown UF", "codigo_ibge,nome,latitude,longitude,codigo_uf\n1,X,-10,-50,99\n", "UF"},
+		{"outside Brazil", "codigo_ibge,nome,latitude,longitude,codigo_uf\n1,X,-46.98,-23.03,35\n", "fora do Brasil"},
+		{"repeated code", "codigo_ibge,nome,latitude,longitude,codigo_uf\n1,X,-10,-50,17\n1,Y,-11,-50,17\n", "repetido"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseIBGEMunicipios(strings.NewReader(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseIBGEMunicipios() error = %v, want mention of %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeatSpacingKm(t *testing.T) {
+	// três sedes em linha a 0,1° de latitude (~11,1 km) e uma isolada
+	places := []Place{
+		{Latitude: -23.0, Longitude: -47.0},
+		{Latitude: -23.1, Longitude: -47.0},
+		{Latitude: -23.2, Longitude: -47.0},
+		{Latitude: -10.0, Longitude: -50.0},
+	}
+	if got := SeatSpacingKm(places, 0.5); math.Abs(got-11.1) > 0.2 {
+		t.Errorf("SeatSpacingKm(0.5) = %.2f, want ~11.1", got)
+	}
+	if got := SeatSpacingKm(places, 1); got < 1000 {
+		t.Errorf("SeatSpacingKm(1) = %.2f, want the isolated seat", got)
+	}
+	if got := SeatSpacingKm(places[:1], 0.5); got != 0 {
+		t.Errorf("SeatSpacingKm(one place) = %v, want 0", got)
+	}
+}
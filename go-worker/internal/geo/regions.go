@@ -0,0 +1,317 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// Modos de aplicação de uma região ao nome da localização
+const (
+	// RegionOverride substitui o nome da localização pelo nome da região
+	RegionOverride = "override"
+	// RegionEnrich antepõe o nome da região ao município ("Fazenda Boa Vista - Campinas, SP")
+	RegionEnrich = "enrich"
+	// RegionTagOnly apenas marca o log com a região, sem alterar a localização
+	RegionTagOnly = "tag"
+)
+
+// regionCellSize é o tamanho, em graus, das células do índice de regiões
+const regionCellSize = 0.25
+
+// Region é um polígono (ou multipolígono) nomeado, lido de um Feature GeoJSON.
+// Propriedades reconhecidas: name (obrigatória), priority e location (modo).
+// As demais ficam disponíveis em Properties.
+type Region struct {
+	Name       string                 `json:"name"`
+	Priority   int                    `json:"priority"`
+	Mode       string                 `json:"mode"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+
+	polygons [][]ring
+	bbox     bbox
+	area     float64
+}
+
+// ring é uma sequência fechada de pontos [lon, lat]
+type ring [][2]float64
+
+type bbox struct {
+	minLat, minLon, maxLat, maxLon float64
+}
+
+func (b bbox) contains(lat, lon float64) bool {
+	return lat >= b.minLat && lat <= b.maxLat && lon >= b.minLon && lon <= b.maxLon
+}
+
+// Contains indica se o ponto está dentro da região. Pontos sobre a borda podem
+// cair em qualquer um dos lados.
+func (r *Region) Contains(lat, lon float64) bool {
+	if !r.bbox.contains(lat, lon) {
+		return false
+	}
+	for _, polygon := range r.polygons {
+		// O primeiro anel é o contorno externo; os seguintes são buracos
+		if !polygon[0].contains(lat, lon) {
+			continue
+		}
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if hole.contains(lat, lon) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// contains implementa o teste de paridade (ray casting)
+func (r ring) contains(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// area calcula a área do anel em graus² (fórmula do laço), usada apenas para
+// ordenar regiões sobrepostas
+func (r ring) area() float64 {
+	sum := 0.0
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		sum += r[j][0]*r[i][1] - r[i][0]*r[j][1]
+	}
+	return math.Abs(sum) / 2
+}
+
+// RegionIndex localiza as regiões que contêm um ponto usando uma grade de
+// células sobre as caixas envolventes dos polígonos
+type RegionIndex struct {
+	regions []*Region
+	cells   map[cellKey][]int
+}
+
+// NewRegionIndex indexa as regiões informadas
+func NewRegionIndex(regions []*Region) *RegionIndex {
+	idx := &RegionIndex{
+		regions: regions,
+		cells:   make(map[cellKey][]int),
+	}
+	for i, r := range regions {
+		minCell := regionCellOf(r.bbox.minLat, r.bbox.minLon)
+		maxCell := regionCellOf(r.bbox.maxLat, r.bbox.maxLon)
+		for lat := minCell.lat; lat <= maxCell.lat; lat++ {
+			for lon := minCell.lon; lon <= maxCell.lon; lon++ {
+				key := cellKey{lat, lon}
+				idx.cells[key] = append(idx.cells[key], i)
+			}
+		}
+	}
+	return idx
+}
+
+func regionCellOf(lat, lon float64) cellKey {
+	return cellKey{int(math.Floor(lat / regionCellSize)), int(math.Floor(lon / regionCellSize))}
+}
+
+// Len retorna o número de regiões indexadas
+func (idx *RegionIndex) Len() int {
+	if idx == nil {
+		return 0
+	}
+	return len(idx.regions)
+}
+
+// Has indica se há uma região indexada com o nome informado
+func (idx *RegionIndex) Has(name string) bool {
+	if idx == nil {
+		return false
+	}
+	for _, r := range idx.regions {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup retorna as regiões que contêm o ponto, da mais prioritária para a
+// menos. Em caso de empate vence a região de menor área (a mais específica) e,
+// depois, a ordem alfabética.
+func (idx *RegionIndex) Lookup(lat, lon float64) []*Region {
+	if idx == nil {
+		return nil
+	}
+	var matches []*Region
+	for _, i := range idx.cells[regionCellOf(lat, lon)] {
+		if r := idx.regions[i]; r.Contains(lat, lon) {
+			matches = append(matches, r)
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		ra, rb := matches[a], matches[b]
+		if ra.Priority != rb.Priority {
+			return ra.Priority > rb.Priority
+		}
+		if ra.area != rb.area {
+			return ra.area < rb.area
+		}
+		return ra.Name < rb.Name
+	})
+	return matches
+}
+
+// geoJSON cobre o subconjunto de GeoJSON (RFC 7946) usado pelas regiões
+type geoJSON struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   *struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// ParseRegions lê um FeatureCollection GeoJSON com geometrias Polygon e
+// MultiPolygon. Coordenadas seguem a ordem GeoJSON: [longitude, latitude].
+func ParseRegions(data []byte) ([]*Region, error) {
+	var fc geoJSON
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("regiões: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("regiões: esperado FeatureCollection, recebido %q", fc.Type)
+	}
+
+	regions := make([]*Region, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		r, err := parseFeature(f)
+		if err != nil {
+			return nil, fmt.Errorf("regiões: feature %d: %w", i, err)
+		}
+		regions = append(regions, r)
+	}
+	return regions, nil
+}
+
+func parseFeature(f geoJSONFeature) (*Region, error) {
+	name, _ := f.Properties["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("propriedade name ausente")
+	}
+	r := &Region{Name: name, Mode: RegionOverride, Properties: f.Properties}
+
+	if v, ok := f.Properties["priority"]; ok {
+		priority, ok := v.(float64)
+		if !ok || priority != math.Trunc(priority) {
+			return nil, fmt.Errorf("%s: priority deve ser inteiro", name)
+		}
+		r.Priority = int(priority)
+	}
+	if v, ok := f.Properties["location"]; ok {
+		mode, _ := v.(string)
+		switch mode {
+		case RegionOverride, RegionEnrich, RegionTagOnly:
+			r.Mode = mode
+		default:
+			return nil, fmt.Errorf("%s: location deve ser %q, %q ou %q", name, RegionOverride, RegionEnrich, RegionTagOnly)
+		}
+	}
+
+	if f.Geometry == nil {
+		return nil, fmt.Errorf("%s: geometria ausente", name)
+	}
+	switch f.Geometry.Type {
+	case "Polygon":
+		var coords [][][]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		polygon, err := toPolygon(coords)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		r.polygons = append(r.polygons, polygon)
+	case "MultiPolygon":
+		var coords [][][][]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, c := range coords {
+			polygon, err := toPolygon(c)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			r.polygons = append(r.polygons, polygon)
+		}
+	default:
+		return nil, fmt.Errorf("%s: geometria %q não suportada (use Polygon ou MultiPolygon)", name, f.Geometry.Type)
+	}
+	if len(r.polygons) == 0 {
+		return nil, fmt.Errorf("%s: geometria vazia", name)
+	}
+
+	r.bbox = bbox{minLat: math.Inf(1), minLon: math.Inf(1), maxLat: math.Inf(-1), maxLon: math.Inf(-1)}
+	for _, polygon := range r.polygons {
+		outer := polygon[0]
+		r.area += outer.area()
+		for _, p := range outer {
+			r.bbox.minLon = math.Min(r.bbox.minLon, p[0])
+			r.bbox.maxLon = math.Max(r.bbox.maxLon, p[0])
+			r.bbox.minLat = math.Min(r.bbox.minLat, p[1])
+			r.bbox.maxLat = math.Max(r.bbox.maxLat, p[1])
+		}
+	}
+	return r, nil
+}
+
+func toPolygon(coords [][][]float64) ([]ring, error) {
+	if len(coords) == 0 {
+		return nil, fmt.Errorf("polígono sem anéis")
+	}
+	polygon := make([]ring, 0, len(coords))
+	for _, c := range coords {
+		if len(c) < 4 {
+			return nil, fmt.Errorf("anel com %d posições, mínimo 4", len(c))
+		}
+		rg := make(ring, 0, len(c))
+		for _, pos := range c {
+			if len(pos) < 2 {
+				return nil, fmt.Errorf("posição inválida: %v", pos)
+			}
+			if pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+				return nil, fmt.Errorf("posição fora do intervalo [lon, lat]: %v", pos)
+			}
+			rg = append(rg, [2]float64{pos[0], pos[1]})
+		}
+		polygon = append(polygon, rg)
+	}
+	return polygon, nil
+}
+
+// LoadRegions carrega e indexa as regiões de um arquivo GeoJSON
+func LoadRegions(path string) (*RegionIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("regiões: %w", err)
+	}
+	regions, err := ParseRegions(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegionIndex(regions), nil
+}
@@ -0,0 +1,106 @@
+package geo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Coordenadas GeoJSON: [longitude, latitude]
+const testRegions = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "properties": {"name": "Distrito Industrial", "priority": 1, "location": "enrich", "zona": "ZI-2"},
+      "geometry": {"type": "Polygon", "coordinates": [
+        [[-47.20, -22.95], [-47.00, -22.95], [-47.00, -22.80], [-47.20, -22.80], [-47.20, -22.95]],
+        [[-47.12, -22.90], [-47.08, -22.90], [-47.08, -22.86], [-47.12, -22.86], [-47.12, -22.90]]
+      ]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"name": "Planta Norte", "priority": 5},
+      "geometry": {"type": "Polygon", "coordinates": [
+        [[-47.06, -22.84], [-47.02, -22.84], [-47.02, -22.81], [-47.06, -22.81], [-47.06, -22.84]]
+      ]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"name": "Fazendas do Grupo", "location": "tag"},
+      "geometry": {"type": "MultiPolygon", "coordinates": [
+        [[[-48.00, -21.00], [-47.90, -21.00], [-47.95, -20.90], [-48.00, -21.00]]],
+        [[[-47.19, -22.94], [-47.15, -22.94], [-47.15, -22.91], [-47.19, -22.91], [-47.19, -22.94]]]
+      ]}
+    }
+  ]
+}`
+
+func TestParseRegions_Lookup(t *testing.T) {
+	regions, err := ParseRegions([]byte(testRegions))
+	if err != nil {
+		t.Fatalf("ParseRegions() error = %v", err)
+	}
+	idx := NewRegionIndex(regions)
+	if idx.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", idx.Len())
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     []string
+	}{
+		{"inside polygon", -22.93, -47.05, []string{"Distrito Industrial"}},
+		{"inside hole", -22.88, -47.10, nil},
+		{"overlap resolved by priority", -22.82, -47.04, []string{"Planta Norte", "Distrito Industrial"}},
+		{"overlap with default priority", -22.92, -47.17, []string{"Distrito Industrial", "Fazendas do Grupo"}},
+		{"second polygon of multipolygon", -20.97, -47.95, []string{"Fazendas do Grupo"}},
+		{"outside triangle, inside bbox", -20.91, -47.99, nil},
+		{"far away", -3.10, -60.02, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range idx.Lookup(tt.lat, tt.lon) {
+				got = append(got, r.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+
+	top := idx.Lookup(-22.93, -47.05)[0]
+	if top.Mode != RegionEnrich || top.Properties["zona"] != "ZI-2" {
+		t.Errorf("region = %+v, want mode enrich and zona ZI-2", top)
+	}
+}
+
+func TestParseRegions_Errors(t *testing.T) {
+	square := `[[[-47.2, -22.9], [-47.0, -22.9], [-47.0, -22.8], [-47.2, -22.9]]]`
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"invalid json", `{`, "unexpected end"},
+		{"not a collection", `{"type": "Feature"}`, "FeatureCollection"},
+		{"missing name", `{"type": "FeatureCollection", "features": [{"properties": {}, "geometry": {"type": "Polygon", "coordinates": ` + square + `}}]}`, "name"},
+		{"point geometry", `{"type": "FeatureCollection", "features": [{"properties": {"name": "A"}, "geometry": {"type": "Point", "coordinates": [-47, -22]}}]}`, "não suportada"},
+		{"short ring", `{"type": "FeatureCollection", "features": [{"properties": {"name": "A"}, "geometry": {"type": "Polygon", "coordinates": [[[-47, -22], [-46, -22], [-47, -22]]]}}]}`, "mínimo 4"},
+		{"swapped coordinates", `{"type": "FeatureCollection", "features": [{"properties": {"name": "A"}, "geometry": {"type": "Polygon", "coordinates": [[[-22, -147], [-22, -146], [-23, -146], [-22, -147]]]}}]}`, "fora do intervalo"},
+		{"invalid mode", `{"type": "FeatureCollection", "features": [{"properties": {"name": "A", "location": "replace"}, "geometry": {"type": "Polygon", "coordinates": ` + square + `}}]}`, "location"},
+		{"fractional priority", `{"type": "FeatureCollection", "features": [{"properties": {"name": "A", "priority": 1.5}, "geometry": {"type": "Polygon", "coordinates": ` + square + `}}]}`, "priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRegions([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRegions() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// sunriseElevation é a elevação do centro do Sol no nascer e no pôr, já
+// considerando a refração atmosférica e o raio aparente do disco solar
+const sunriseElevation = -0.833
+
+// SunElevation calcula a elevação do Sol, em graus, para o ponto e o instante
+// informados (algoritmo simplificado da NOAA, erro inferior a 0,1° entre 1950 e 2050)
+func SunElevation(lat, lon float64, t time.Time) float64 {
+	t = t.UTC()
+	dayOfYear := float64(t.YearDay())
+	hours := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+
+	// Ângulo fracionário do ano, em radianos
+	gamma := 2 * math.Pi / daysInYear(t.Year()) * (dayOfYear - 1 + (hours-12)/24)
+
+	// Equação do tempo (minutos) e declinação solar (radianos)
+	eqTime := 229.18 * (0.000075 + 0.001868*math.Cos(gamma) - 0.032077*math.Sin(gamma) -
+		0.014615*math.Cos(2*gamma) - 0.040849*math.Sin(2*gamma))
+	decl := 0.006918 - 0.399912*math.Cos(gamma) + 0.070257*math.Sin(gamma) -
+		0.006758*math.Cos(2*gamma) + 0.000907*math.Sin(2*gamma) -
+		0.002697*math.Cos(3*gamma) + 0.00148*math.Sin(3*gamma)
+
+	// Ângulo horário a partir do tempo solar verdadeiro
+	trueSolarMinutes := hours*60 + eqTime + 4*lon
+	hourAngle := (trueSolarMinutes/4 - 180) * math.Pi / 180
+
+	phi := lat * math.Pi / 180
+	cosZenith := math.Sin(phi)*math.Sin(decl) + math.Cos(phi)*math.Cos(decl)*math.Cos(hourAngle)
+	cosZenith = math.Max(-1, math.Min(1, cosZenith))
+	return 90 - math.Acos(cosZenith)*180/math.Pi
+}
+
+// IsDaylight indica se o Sol está acima do horizonte no ponto e instante informados
+func IsDaylight(lat, lon float64, t time.Time) bool {
+	return SunElevation(lat, lon, t) > sunriseElevation
+}
+
+func daysInYear(year int) float64 {
+	if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		return 366
+	}
+	return 365
+}
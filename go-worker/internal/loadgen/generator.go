@@ -0,0 +1,256 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// Kind classifica as mensagens geradas
+type Kind string
+
+const (
+	KindValid     Kind = "valid"
+	KindInvalid   Kind = "invalid"   // JSON correto, valores fora das faixas de Validate
+	KindMalformed Kind = "malformed" // JSON quebrado ou com tipos errados
+)
+
+// Station é uma estação virtual com clima próprio
+type Station struct {
+	ID        int
+	Latitude  float64
+	Longitude float64
+
+	meanTemp      float64 // média diária (°C)
+	tempAmplitude float64 // metade da amplitude térmica diária (°C)
+	meanHumidity  float64 // média diária (%)
+	humAmplitude  float64
+	wind          float64 // vento médio (km/h)
+	rainChance    float64 // probabilidade de início de chuva por hora
+	raining       bool
+	rainIntensity float64 // mm/h
+	lastUpdate    time.Time
+}
+
+// Options configura o gerador
+type Options struct {
+	Stations          int
+	InvalidFraction   float64
+	MalformedFraction float64
+	Seed              int64
+}
+
+// Generator produz WeatherMessages com ciclos diurnos realistas
+type Generator struct {
+	opts     Options
+	rng      *rand.Rand
+	stations []*Station
+	next     int
+}
+
+// NewGenerator cria estações espalhadas pelo Sudeste brasileiro
+func NewGenerator(opts Options) (*Generator, error) {
+	if opts.Stations < 1 {
+		return nil, fmt.Errorf("número de estações deve ser >= 1, recebido %d", opts.Stations)
+	}
+	if opts.InvalidFraction < 0 || opts.MalformedFraction < 0 || opts.InvalidFraction+opts.MalformedFraction > 1 {
+		return nil, fmt.Errorf("frações inválidas: invalid=%v malformed=%v", opts.InvalidFraction, opts.MalformedFraction)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	g := &Generator{opts: opts, rng: rng}
+	for i := 0; i < opts.Stations; i++ {
+		g.stations = append(g.stations, &Station{
+			ID:            i + 1,
+			Latitude:      -25 + rng.Float64()*6,  // -25..-19
+			Longitude:     -50 + rng.Float64()*10, // -50..-40
+			meanTemp:      18 + rng.Float64()*8,
+			tempAmplitude: 3 + rng.Float64()*4,
+			meanHumidity:  65 + rng.Float64()*15,
+			humAmplitude:  10 + rng.Float64()*10,
+			wind:          5 + rng.Float64()*15,
+			rainChance:    0.02 + rng.Float64()*0.06,
+		})
+	}
+	return g, nil
+}
+
+// Stations retorna as estações virtuais
+func (g *Generator) Stations() []*Station {
+	return g.stations
+}
+
+// Next gera a próxima mensagem (estações em round-robin) no instante simulado t
+func (g *Generator) Next(t time.Time) ([]byte, Kind, error) {
+	st := g.stations[g.next]
+	g.next = (g.next + 1) % len(g.stations)
+
+	msg := st.Observe(t, g.rng)
+
+	r := g.rng.Float64()
+	switch {
+	case r < g.opts.MalformedFraction:
+		return g.malformed(msg), KindMalformed, nil
+	case r < g.opts.MalformedFraction+g.opts.InvalidFraction:
+		g.corrupt(&msg)
+		body, err := json.Marshal(msg)
+		return body, KindInvalid, err
+	default:
+		body, err := json.Marshal(msg)
+		return body, KindValid, err
+	}
+}
+
+// Observe calcula a observação da estação no instante t. Temperatura e
+// umidade seguem senoides diurnas (máxima de temperatura às 15h locais e
+// mínima de umidade no mesmo horário) mais ruído; a chuva é um processo de
+// dois estados cuja chance cresce à tarde, e o código WMO acompanha a
+// intensidade.
+func (st *Station) Observe(t time.Time, rng *rand.Rand) models.WeatherMessage {
+	hour := solarHour(t, st.Longitude)
+	phase := 2 * math.Pi * (hour - 9) / 24 // pico em 15h
+
+	st.updateRain(t, hour, rng)
+
+	temp := st.meanTemp + st.tempAmplitude*math.Sin(phase) + rng.NormFloat64()*0.4
+	humidity := st.meanHumidity - st.humAmplitude*math.Sin(phase) + rng.NormFloat64()*2
+	wind := math.Max(0, st.wind*(1+0.3*math.Sin(phase))+rng.NormFloat64()*2)
+	precipitation := 0.0
+	if st.raining {
+		temp -= 2
+		humidity = 88 + rng.Float64()*12
+		precipitation = st.rainIntensity
+		wind += st.rainIntensity * 0.8
+	}
+
+	return models.WeatherMessage{
+		Timestamp: t.UTC().Format("2006-01-02T15:04:05.000000"),
+		Location: models.WeatherLocation{
+			Latitude:  round(st.Latitude, 4),
+			Longitude: round(st.Longitude, 4),
+			Timezone:  "America/Sao_Paulo",
+		},
+		Current: models.WeatherCurrent{
+			Temperature:   round(temp, 1),
+			Humidity:      round(clamp(humidity, 5, 100), 0),
+			Precipitation: models.Float64(round(precipitation, 1)),
+			WindSpeed:     round(wind, 1),
+			WeatherCode:   st.weatherCode(hour, humidity),
+			Time:          models.FormatLocalTime(t, "America/Sao_Paulo"),
+		},
+	}
+}
+
+// updateRain avança o processo de chuva proporcionalmente ao tempo decorrido
+func (st *Station) updateRain(t time.Time, hour float64, rng *rand.Rand) {
+	elapsed := time.Hour
+	if !st.lastUpdate.IsZero() {
+		elapsed = t.Sub(st.lastUpdate)
+	}
+	st.lastUpdate = t
+	hours := math.Max(0, elapsed.Hours())
+
+	if st.raining {
+		// Duração média de ~2h
+		if rng.Float64() < 1-math.Exp(-hours/2) {
+			st.raining = false
+			st.rainIntensity = 0
+		}
+		return
+	}
+
+	// Convecção: chance maior entre 13h e 19h
+	chance := st.rainChance
+	if hour >= 13 && hour <= 19 {
+		chance *= 3
+	}
+	if rng.Float64() < 1-math.Exp(-chance*hours) {
+		st.raining = true
+		st.rainIntensity = 0.2 + rng.ExpFloat64()*3
+	}
+}
+
+// weatherCode aproxima o código WMO a partir do estado da estação
+func (st *Station) weatherCode(hour, humidity float64) int {
+	if st.raining {
+		switch {
+		case st.rainIntensity < 0.5:
+			return 51 // garoa fraca
+		case st.rainIntensity < 2.5:
+			return 61 // chuva fraca
+		case st.rainIntensity < 7.6:
+			if hour >= 13 && hour <= 19 {
+				return 81 // pancadas moderadas
+			}
+			return 63 // chuva moderada
+		case hour >= 13 && hour <= 21:
+			return 95 // trovoada
+		default:
+			return 65 // chuva forte
+		}
+	}
+	if humidity >= 95 && (hour < 8 || hour > 22) {
+		return 45 // neblina
+	}
+	switch {
+	case humidity < 55:
+		return 0
+	case humidity < 70:
+		return 1
+	case humidity < 85:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// corrupt coloca um campo fora da faixa aceita por Validate
+func (g *Generator) corrupt(msg *models.WeatherMessage) {
+	switch g.rng.Intn(5) {
+	case 0:
+		msg.Timestamp = ""
+	case 1:
+		msg.Location.Latitude = 90 + g.rng.Float64()*90
+	case 2:
+		msg.Location.Longitude = -180 - g.rng.Float64()*180
+	case 3:
+		msg.Current.Temperature = 150 + g.rng.Float64()*50
+	default:
+		msg.Current.Humidity = -1 - g.rng.Float64()*50
+	}
+}
+
+// malformed gera um corpo que não pode ser deserializado como WeatherMessage
+func (g *Generator) malformed(msg models.WeatherMessage) []byte {
+	body, _ := json.Marshal(msg)
+	switch g.rng.Intn(3) {
+	case 0:
+		return body[:len(body)/2] // JSON truncado
+	case 1:
+		return []byte(fmt.Sprintf(`{"timestamp":%q,"location":"%.4f,%.4f","current":{}}`,
+			msg.Timestamp, msg.Location.Latitude, msg.Location.Longitude)) // tipo errado
+	default:
+		return []byte(fmt.Sprintf(`{"timestamp":%q,"current":{"temperature":"%.1f"}}`,
+			msg.Timestamp, msg.Current.Temperature)) // número como string
+	}
+}
+
+// solarHour aproxima a hora solar local pela longitude
+func solarHour(t time.Time, longitude float64) float64 {
+	u := t.UTC()
+	hour := float64(u.Hour()) + float64(u.Minute())/60 + float64(u.Second())/3600 + longitude/15
+	return math.Mod(hour+48, 24)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
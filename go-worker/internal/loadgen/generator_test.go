@@ -0,0 +1,181 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+func TestStation_DiurnalCycle(t *testing.T) {
+	st := &Station{
+		Latitude:      -23.55,
+		Longitude:     -45, // hora solar = UTC - 3h
+		meanTemp:      22,
+		tempAmplitude: 5,
+		meanHumidity:  70,
+		humAmplitude:  15,
+		wind:          10,
+	}
+	rng := rand.New(rand.NewSource(1))
+	day := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	// 15h e 03h solares
+	afternoon := st.Observe(day.Add(18*time.Hour), rng)
+	night := st.Observe(day.Add(30*time.Hour), rng)
+
+	if afternoon.Current.Temperature-night.Current.Temperature < 7 {
+		t.Errorf("afternoon %.1f°C vs night %.1f°C: expected a diurnal amplitude close to 10°C",
+			afternoon.Current.Temperature, night.Current.Temperature)
+	}
+	if afternoon.Current.Humidity >= night.Current.Humidity {
+		t.Errorf("afternoon humidity %.0f%% should be below night humidity %.0f%%",
+			afternoon.Current.Humidity, night.Current.Humidity)
+	}
+}
+
+func TestStation_RainMatchesWeatherCode(t *testing.T) {
+	gen, err := NewGenerator(Options{Stations: 20, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(7))
+	start := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	rainy := 0
+	for i := 0; i < 24*10; i++ {
+		for _, st := range gen.Stations() {
+			msg := st.Observe(start.Add(time.Duration(i)*time.Hour), rng)
+			isRainCode := msg.Current.WeatherCode >= 51
+			if (*msg.Current.Precipitation > 0) != isRainCode {
+				t.Fatalf("precipitation %.1f with WMO code %d", *msg.Current.Precipitation, msg.Current.WeatherCode)
+			}
+			if isRainCode {
+				rainy++
+			}
+		}
+	}
+	if rainy == 0 {
+		t.Error("no rain events generated in 10 simulated days")
+	}
+}
+
+func TestGenerator_Fractions(t *testing.T) {
+	gen, err := NewGenerator(Options{Stations: 5, InvalidFraction: 0.2, MalformedFraction: 0.1, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[Kind]int{}
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	const total = 5000
+	for i := 0; i < total; i++ {
+		body, kind, err := gen.Next(now.Add(time.Duration(i) * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[kind]++
+
+		var msg models.WeatherMessage
+		decodeErr := json.Unmarshal(body, &msg)
+		switch kind {
+		case KindValid:
+			if decodeErr != nil || msg.Validate() != nil {
+				t.Fatalf("valid message rejected: %s", body)
+			}
+		case KindInvalid:
+			if decodeErr != nil || msg.Validate() == nil {
+				t.Fatalf("invalid message accepted: %s", body)
+			}
+		case KindMalformed:
+			if decodeErr == nil {
+				t.Fatalf("malformed message decoded: %s", body)
+			}
+		}
+	}
+
+	if got := float64(counts[KindInvalid]) / total; got < 0.17 || got > 0.23 {
+		t.Errorf("invalid fraction = %.3f, want ~0.2", got)
+	}
+	if got := float64(counts[KindMalformed]) / total; got < 0.08 || got > 0.12 {
+		t.Errorf("malformed fraction = %.3f, want ~0.1", got)
+	}
+}
+
+func TestNewGenerator_InvalidOptions(t *testing.T) {
+	if _, err := NewGenerator(Options{Stations: 0}); err == nil {
+		t.Error("NewGenerator() error = nil for zero stations")
+	}
+	if _, err := NewGenerator(Options{Stations: 1, InvalidFraction: 0.7, MalformedFraction: 0.5}); err == nil {
+		t.Error("NewGenerator() error = nil for fractions above 1")
+	}
+}
+
+type countingOutput struct {
+	n int
+}
+
+func (o *countingOutput) Publish(context.Context, []byte) error {
+	o.n++
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	gen, _ := NewGenerator(Options{Stations: 2, Seed: 1})
+	out := &countingOutput{}
+
+	report, err := Run(context.Background(), gen, out, RunOptions{Rate: 200, Duration: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Published != out.n || report.Published == 0 {
+		t.Errorf("Published = %d, output received %d", report.Published, out.n)
+	}
+	if report.Throughput <= 0 {
+		t.Errorf("Throughput = %v, want > 0", report.Throughput)
+	}
+}
+
+type collectingOutput struct {
+	bodies [][]byte
+}
+
+func (o *collectingOutput) Publish(_ context.Context, body []byte) error {
+	o.bodies = append(o.bodies, body)
+	return nil
+}
+
+func TestRun_SimulatedClock(t *testing.T) {
+	gen, _ := NewGenerator(Options{Stations: 3, Seed: 5})
+	out := &collectingOutput{}
+
+	// Uma hora simulada por segundo: sem Start, o relógio termina agora e as
+	// mensagens passam pela política de horários do worker
+	started := time.Now()
+	if _, err := Run(context.Background(), gen, out, RunOptions{Rate: 100, Duration: 200 * time.Millisecond, TimeScale: 3600}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(out.bodies) == 0 {
+		t.Fatal("no messages published")
+	}
+	for _, body := range out.bodies {
+		var msg models.WeatherMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := msg.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v for %s", err, body)
+		}
+	}
+
+	var first models.WeatherMessage
+	if err := json.Unmarshal(out.bodies[0], &first); err != nil {
+		t.Fatal(err)
+	}
+	if observed, _ := first.ObservedAt(); !observed.Before(started.Add(-5 * time.Minute)) {
+		t.Errorf("first message at %v, want about 12 simulated minutes before %v", observed, started)
+	}
+}
@@ -0,0 +1,106 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Output é o destino das mensagens geradas (AMQP, NDJSON, ...)
+type Output interface {
+	Publish(ctx context.Context, body []byte) error
+}
+
+// RunOptions controla taxa, duração e relógio simulado da execução
+type RunOptions struct {
+	Rate     float64       // mensagens por segundo
+	Duration time.Duration // duração real da execução
+	// Start é o instante simulado inicial. Sem ele, o relógio simulado começa
+	// Duration*TimeScale antes de agora, para terminar no instante atual
+	Start time.Time
+	// TimeScale acelera o relógio simulado (ex.: 3600 = uma hora por segundo),
+	// permitindo observar o ciclo diurno em execuções curtas. O relógio nunca
+	// passa do instante real: mensagens no futuro seriam rejeitadas pelo worker
+	TimeScale float64
+}
+
+// Report resume a execução
+type Report struct {
+	Published  int            `json:"published"`
+	Failed     int            `json:"failed"`
+	ByKind     map[Kind]int   `json:"byKind"`
+	Elapsed    time.Duration  `json:"-"`
+	ElapsedSec float64        `json:"elapsedSeconds"`
+	Throughput float64        `json:"throughputPerSecond"`
+	Confirms   *ConfirmStats  `json:"confirms,omitempty"`
+	Errors     map[string]int `json:"errors,omitempty"`
+}
+
+// ConfirmStats são as confirmações do broker (publisher confirms)
+type ConfirmStats struct {
+	Acked  int     `json:"acked"`
+	Nacked int     `json:"nacked"`
+	Rate   float64 `json:"rate"`
+}
+
+// Run publica mensagens na taxa pedida até a duração acabar ou o contexto ser cancelado
+func Run(ctx context.Context, g *Generator, out Output, opts RunOptions) (Report, error) {
+	if opts.Rate <= 0 {
+		return Report{}, fmt.Errorf("taxa deve ser positiva, recebido %v", opts.Rate)
+	}
+	if opts.TimeScale <= 0 {
+		opts.TimeScale = 1
+	}
+	if opts.Start.IsZero() {
+		opts.Start = time.Now().Add(-time.Duration(float64(opts.Duration) * opts.TimeScale))
+	}
+
+	report := Report{ByKind: make(map[Kind]int), Errors: make(map[string]int)}
+	interval := time.Duration(float64(time.Second) / opts.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	begin := time.Now()
+	var deadline <-chan time.Time
+	if opts.Duration > 0 {
+		timer := time.NewTimer(opts.Duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline:
+			break loop
+		case now := <-ticker.C:
+			simulated := opts.Start.Add(time.Duration(float64(now.Sub(begin)) * opts.TimeScale))
+			if simulated.After(now) {
+				simulated = now
+			}
+			body, kind, err := g.Next(simulated)
+			if err != nil {
+				return report, err
+			}
+			if err := out.Publish(ctx, body); err != nil {
+				report.Failed++
+				report.Errors[err.Error()]++
+				continue
+			}
+			report.Published++
+			report.ByKind[kind]++
+		}
+	}
+
+	report.Elapsed = time.Since(begin)
+	report.ElapsedSec = report.Elapsed.Seconds()
+	if secs := report.ElapsedSec; secs > 0 {
+		report.Throughput = float64(report.Published) / secs
+	}
+	if len(report.Errors) == 0 {
+		report.Errors = nil
+	}
+	return report, nil
+}
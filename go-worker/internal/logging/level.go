@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Níveis reconhecidos pelo prefixo das mensagens ("[DEBUG]", "[INFO]", ...)
+const (
+	LevelDebug int32 = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var minLevel atomic.Int32
+
+func init() {
+	minLevel.Store(LevelInfo)
+}
+
+// Setup instala o filtro de nível na saída do pacote log
+func Setup(level string) error {
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+	log.SetOutput(&levelWriter{out: os.Stderr})
+	return nil
+}
+
+// SetLevel altera o nível mínimo das mensagens registradas
+func SetLevel(level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	minLevel.Store(l)
+	return nil
+}
+
+// ParseLevel converte o nome do nível (debug, info, warn, error)
+func ParseLevel(level string) (int32, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, fmt.Errorf("nível de log inválido: %q", level)
+	}
+}
+
+// levelWriter descarta as linhas abaixo do nível mínimo. Linhas sem prefixo
+// conhecido (ex.: "[ACK]") são tratadas como INFO.
+type levelWriter struct {
+	out io.Writer
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	if lineLevel(p) < minLevel.Load() {
+		return len(p), nil
+	}
+	return w.out.Write(p)
+}
+
+func lineLevel(line []byte) int32 {
+	start := bytes.IndexByte(line, '[')
+	if start < 0 {
+		return LevelInfo
+	}
+	end := bytes.IndexByte(line[start:], ']')
+	if end < 0 {
+		return LevelInfo
+	}
+	switch string(line[start+1 : start+end]) {
+	case "DEBUG":
+		return LevelDebug
+	case "WARN":
+		return LevelWarn
+	case "ERROR", "FATAL":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
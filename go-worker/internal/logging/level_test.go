@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLevelWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &levelWriter{out: &buf}
+	defer SetLevel("info")
+
+	if err := SetLevel("warn"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+
+	w.Write([]byte("2025/06/15 14:30:00 [INFO] ignorada\n"))
+	w.Write([]byte("2025/06/15 14:30:00 [ACK] ignorada\n"))
+	w.Write([]byte("2025/06/15 14:30:00 [WARN] registrada\n"))
+	w.Write([]byte("2025/06/15 14:30:00 [ERROR] registrada\n"))
+
+	if got := bytes.Count(buf.Bytes(), []byte("registrada")); got != 2 {
+		t.Errorf("lines written = %d, want 2: %q", got, buf.String())
+	}
+	if bytes.Contains(buf.Bytes(), []byte("ignorada")) {
+		t.Errorf("lines below level written: %q", buf.String())
+	}
+}
+
+func TestSetLevel_Invalid(t *testing.T) {
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("SetLevel() error = nil, want error")
+	}
+}
@@ -0,0 +1,141 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQPublisher publica mensagens na fila (mesmo formato do serviço Python)
+type RabbitMQPublisher struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	queue   string
+
+	// Publisher confirms (opcional): confirmações ainda não resolvidas e totais
+	confirming bool
+	pending    []*amqp.DeferredConfirmation
+	acked      int
+	nacked     int
+}
+
+// NewRabbitMQPublisher cria uma nova instância do publisher RabbitMQ
+func NewRabbitMQPublisher(url, queueName string) (*RabbitMQPublisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar ao RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("falha ao abrir canal: %w", err)
+	}
+
+	// Declara a fila com os mesmos parâmetros do consumer
+	if _, err := channel.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("falha ao declarar fila: %w", err)
+	}
+
+	return &RabbitMQPublisher{
+		conn:    conn,
+		channel: channel,
+		queue:   queueName,
+	}, nil
+}
+
+// EnableConfirms coloca o canal em modo confirm; as confirmações do broker
+// são contabilizadas e podem ser aguardadas com WaitConfirms
+func (p *RabbitMQPublisher) EnableConfirms() error {
+	if err := p.channel.Confirm(false); err != nil {
+		return fmt.Errorf("falha ao habilitar publisher confirms: %w", err)
+	}
+	p.confirming = true
+	return nil
+}
+
+// Publish publica uma mensagem persistente na fila
+func (p *RabbitMQPublisher) Publish(ctx context.Context, body []byte, contentType string) error {
+	msg := amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  contentType,
+		Body:         body,
+	}
+
+	if !p.confirming {
+		err := p.channel.PublishWithContext(ctx,
+			"",      // exchange padrão
+			p.queue, // routing key
+			false,   // mandatory
+			false,   // immediate
+			msg,
+		)
+		if err != nil {
+			return fmt.Errorf("falha ao publicar mensagem: %w", err)
+		}
+		return nil
+	}
+
+	dc, err := p.channel.PublishWithDeferredConfirmWithContext(ctx, "", p.queue, false, false, msg)
+	if err != nil {
+		return fmt.Errorf("falha ao publicar mensagem: %w", err)
+	}
+	p.pending = append(p.pending, dc)
+	p.collectConfirms()
+	return nil
+}
+
+// collectConfirms contabiliza as confirmações já resolvidas, na ordem de publicação
+func (p *RabbitMQPublisher) collectConfirms() {
+	for len(p.pending) > 0 {
+		select {
+		case <-p.pending[0].Done():
+			p.record(p.pending[0])
+			p.pending = p.pending[1:]
+		default:
+			return
+		}
+	}
+}
+
+func (p *RabbitMQPublisher) record(dc *amqp.DeferredConfirmation) {
+	if dc.Acked() {
+		p.acked++
+	} else {
+		p.nacked++
+	}
+}
+
+// WaitConfirms aguarda as confirmações pendentes e retorna os totais de ACK e
+// NACK recebidos. As que não chegarem antes do contexto expirar não são contadas.
+func (p *RabbitMQPublisher) WaitConfirms(ctx context.Context) (acked, nacked int, err error) {
+	for len(p.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return p.acked, p.nacked, fmt.Errorf("%d confirmação(ões) pendente(s): %w", len(p.pending), ctx.Err())
+		case <-p.pending[0].Done():
+			p.record(p.pending[0])
+			p.pending = p.pending[1:]
+		}
+	}
+	return p.acked, p.nacked, nil
+}
+
+// Close fecha a conexão com o RabbitMQ
+func (p *RabbitMQPublisher) Close() error {
+	if p.channel != nil {
+		if err := p.channel.Close(); err != nil {
+			log.Printf("[WARN] Erro ao fechar canal: %v", err)
+		}
+	}
+	if p.conn != nil {
+		if err := p.conn.Close(); err != nil {
+			log.Printf("[WARN] Erro ao fechar conexão: %v", err)
+		}
+	}
+	return nil
+}
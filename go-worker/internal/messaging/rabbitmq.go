@@ -2,8 +2,10 @@ package messaging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -11,13 +13,69 @@ import (
 
 // RabbitMQConsumer gerencia a conexão e consumo do RabbitMQ
 type RabbitMQConsumer struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	queue   string
+	conn       *amqp.Connection
+	channel    *amqp.Channel
+	queue      string
+	deadLetter string
+	quarantine string
+	limiter    *limiter
+}
+
+// Headers das mensagens enviadas à dead-letter queue
+const (
+	HeaderError    = "x-error"
+	HeaderFailedAt = "x-failed-at"
+	HeaderQueue    = "x-original-queue"
+	// HeaderRoutingKey preserva a routing key, que pode escolher o tipo da
+	// mensagem ao ser reprocessada
+	HeaderRoutingKey = "x-original-routing-key"
+)
+
+// DeadLetterHeaderer é implementado por erros que descrevem a falha em headers
+// adicionais da mensagem enviada à dead-letter queue
+type DeadLetterHeaderer interface {
+	DeadLetterHeaders() map[string]string
+}
+
+// Quarantiner é implementado por erros de mensagens que o worker não sabe
+// tratar (ex.: tipo desconhecido); com Quarantine true elas vão para a
+// quarentena, e não para a dead-letter queue
+type Quarantiner interface {
+	Quarantine() bool
+}
+
+// Delivery é uma mensagem entregue ao handler, com os headers AMQP convertidos
+// para texto
+type Delivery struct {
+	Body        []byte
+	Headers     map[string]string
+	Type        string
+	ContentType string
+	RoutingKey  string
 }
 
 // MessageHandler é a função que processa cada mensagem
-type MessageHandler func([]byte) error
+type MessageHandler func(Delivery) error
+
+// newDelivery converte uma entrega AMQP; headers não textuais são formatados
+// com fmt
+func newDelivery(msg amqp.Delivery) Delivery {
+	d := Delivery{Body: msg.Body, Type: msg.Type, ContentType: msg.ContentType, RoutingKey: msg.RoutingKey}
+	if len(msg.Headers) > 0 {
+		d.Headers = make(map[string]string, len(msg.Headers))
+		for key, value := range msg.Headers {
+			switch v := value.(type) {
+			case string:
+				d.Headers[key] = v
+			case []byte:
+				d.Headers[key] = string(v)
+			default:
+				d.Headers[key] = fmt.Sprint(v)
+			}
+		}
+	}
+	return d
+}
 
 // NewRabbitMQConsumer cria uma nova instância do consumer RabbitMQ
 func NewRabbitMQConsumer(url, queueName string) (*RabbitMQConsumer, error) {
@@ -65,9 +123,44 @@ func NewRabbitMQConsumer(url, queueName string) (*RabbitMQConsumer, error) {
 		conn:    conn,
 		channel: channel,
 		queue:   queueName,
+		limiter: newLimiter(1),
 	}, nil
 }
 
+// SetConcurrency altera quantas mensagens são processadas em paralelo,
+// ajustando também o prefetch do canal
+func (r *RabbitMQConsumer) SetConcurrency(n int) error {
+	if n < 1 {
+		return fmt.Errorf("concorrência deve ser >= 1, recebido %d", n)
+	}
+	if err := r.channel.Qos(n, 0, false); err != nil {
+		return fmt.Errorf("falha ao configurar QoS: %w", err)
+	}
+	r.limiter.resize(n)
+	return nil
+}
+
+// SetDeadLetterQueue declara a fila que recebe as mensagens rejeitadas. Elas
+// são republicadas com o motivo nos headers e confirmadas na fila de origem;
+// se a republicação falhar, recebem NACK como antes.
+func (r *RabbitMQConsumer) SetDeadLetterQueue(name string) error {
+	if _, err := r.channel.QueueDeclare(name, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("falha ao declarar dead-letter queue: %w", err)
+	}
+	r.deadLetter = name
+	return nil
+}
+
+// SetQuarantineQueue declara a fila que recebe as mensagens de tipo
+// desconhecido (ver Quarantiner), republicadas como na dead-letter queue
+func (r *RabbitMQConsumer) SetQuarantineQueue(name string) error {
+	if _, err := r.channel.QueueDeclare(name, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("falha ao declarar fila de quarentena: %w", err)
+	}
+	r.quarantine = name
+	return nil
+}
+
 // Consume inicia o consumo de mensagens
 func (r *RabbitMQConsumer) Consume(ctx context.Context, handler MessageHandler) error {
 	msgs, err := r.channel.Consume(
@@ -85,6 +178,10 @@ func (r *RabbitMQConsumer) Consume(ctx context.Context, handler MessageHandler)
 
 	log.Printf("[INFO] Aguardando mensagens na fila '%s'. Para sair pressione CTRL+C", r.queue)
 
+	// Aguarda as mensagens em processamento antes de retornar
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -94,18 +191,45 @@ func (r *RabbitMQConsumer) Consume(ctx context.Context, handler MessageHandler)
 			if !ok {
 				return fmt.Errorf("canal de mensagens foi fechado")
 			}
-			r.handleMessage(msg, handler)
+			if !r.limiter.acquire(ctx) {
+				// Encerrando: a mensagem não confirmada volta para a fila
+				log.Println("[INFO] Encerrando consumer...")
+				return nil
+			}
+			wg.Add(1)
+			go func(msg amqp.Delivery) {
+				defer wg.Done()
+				defer r.limiter.release()
+				r.handleMessage(msg, handler)
+			}(msg)
 		}
 	}
 }
 
 func (r *RabbitMQConsumer) handleMessage(msg amqp.Delivery, handler MessageHandler) {
 	// Processa a mensagem
-	err := handler(msg.Body)
+	err := handler(newDelivery(msg))
 
 	if err != nil {
 		log.Printf("[ERROR] Erro ao processar mensagem: %v", err)
 
+		target, name := r.deadLetter, "dead-letter queue"
+		var q Quarantiner
+		if r.quarantine != "" && errors.As(err, &q) && q.Quarantine() {
+			target, name = r.quarantine, "quarentena"
+		}
+		if target != "" {
+			if pubErr := r.publishFailed(target, msg, err); pubErr != nil {
+				log.Printf("[ERROR] Erro ao publicar na %s: %v", name, pubErr)
+			} else {
+				if ackErr := msg.Ack(false); ackErr != nil {
+					log.Printf("[ERROR] Erro ao enviar ACK: %v", ackErr)
+				}
+				log.Printf("[NACK] Mensagem enviada para a %s '%s'", name, target)
+				return
+			}
+		}
+
 		// NACK - rejeita a mensagem (pode ser reenfileirada ou enviada para DLQ)
 		if nackErr := msg.Nack(false, false); nackErr != nil {
 			log.Printf("[ERROR] Erro ao enviar NACK: %v", nackErr)
@@ -123,6 +247,46 @@ func (r *RabbitMQConsumer) handleMessage(msg amqp.Delivery, handler MessageHandl
 	}
 }
 
+// publishFailed republica a mensagem na fila informada (dead-letter queue ou
+// quarentena) com os headers originais e o motivo da rejeição
+func (r *RabbitMQConsumer) publishFailed(queue string, msg amqp.Delivery, cause error) error {
+	headers := deadLetterHeaders(msg.Headers, cause, r.queue, msg.RoutingKey, time.Now())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return r.channel.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		Type:          msg.Type,
+		MessageId:     msg.MessageId,
+		CorrelationId: msg.CorrelationId,
+		Timestamp:     msg.Timestamp,
+		DeliveryMode:  amqp.Persistent,
+		Body:          msg.Body,
+	})
+}
+
+// deadLetterHeaders copia os headers da mensagem e acrescenta o motivo da
+// rejeição, incluindo os headers fornecidos pelo erro
+func deadLetterHeaders(original amqp.Table, cause error, queue, routingKey string, now time.Time) amqp.Table {
+	headers := make(amqp.Table, len(original)+4)
+	for k, v := range original {
+		headers[k] = v
+	}
+	headers[HeaderError] = cause.Error()
+	headers[HeaderFailedAt] = now.UTC().Format(time.RFC3339)
+	headers[HeaderQueue] = queue
+	if routingKey != "" {
+		headers[HeaderRoutingKey] = routingKey
+	}
+	var h DeadLetterHeaderer
+	if errors.As(cause, &h) {
+		for k, v := range h.DeadLetterHeaders() {
+			headers[k] = v
+		}
+	}
+	return headers
+}
+
 // Close fecha a conexão com o RabbitMQ
 func (r *RabbitMQConsumer) Close() error {
 	if r.channel != nil {
@@ -157,3 +321,38 @@ func WaitForConnection(url string, maxAttempts int, delay time.Duration) error {
 	return fmt.Errorf("falha ao conectar ao RabbitMQ após %d tentativas", maxAttempts)
 }
 
+// Inspect entrega até max mensagens ao handler sem confirmá-las nem
+// rejeitá-las (modo dry-run). As mensagens ficam retidas até o canal ser
+// fechado, quando o broker as devolve à fila na ordem original; por isso o
+// prefetch é limitado a max e nenhuma mensagem é reentregue durante a execução.
+func (r *RabbitMQConsumer) Inspect(ctx context.Context, max int, handler MessageHandler) error {
+	if max < 1 {
+		return fmt.Errorf("max deve ser >= 1, recebido %d", max)
+	}
+	if err := r.channel.Qos(max, 0, false); err != nil {
+		return fmt.Errorf("falha ao configurar QoS: %w", err)
+	}
+
+	msgs, err := r.channel.Consume(r.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar consumer: %w", err)
+	}
+
+	log.Printf("[INFO] Dry-run: inspecionando até %d mensagem(ns) da fila '%s' sem ACK", max, r.queue)
+
+	for seen := 0; seen < max; {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("canal de mensagens foi fechado")
+			}
+			seen++
+			if err := handler(newDelivery(msg)); err != nil {
+				log.Printf("[ERROR] Erro ao inspecionar mensagem: %v", err)
+			}
+		}
+	}
+	return nil
+}
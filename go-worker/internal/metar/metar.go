@@ -0,0 +1,426 @@
+// Package metar decodifica boletins METAR e SPECI (formato do Anexo 3 da OACI
+// e a variante norte-americana com visibilidade em milhas e altímetro em inHg).
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNil indica um boletim NIL (estação sem observação)
+var ErrNil = errors.New("boletim NIL")
+
+// Report é um boletim decodificado. Grupos ausentes ficam nil ou vazios.
+type Report struct {
+	Kind      string `json:"kind"`
+	Station   string `json:"station"`
+	Day       int    `json:"day"`
+	Hour      int    `json:"hour"`
+	Minute    int    `json:"minute"`
+	Auto      bool   `json:"auto,omitempty"`
+	Corrected bool   `json:"corrected,omitempty"`
+
+	Wind       *Wind        `json:"wind,omitempty"`
+	Visibility *Visibility  `json:"visibility,omitempty"`
+	CAVOK      bool         `json:"cavok,omitempty"`
+	RVR        []RVR        `json:"rvr,omitempty"`
+	Weather    []Phenomenon `json:"weather,omitempty"`
+	Clouds     []CloudLayer `json:"clouds,omitempty"`
+	// SkyClear guarda NSC, NCD, SKC ou CLR
+	SkyClear  string   `json:"skyClear,omitempty"`
+	Temp      *float64 `json:"temperature,omitempty"`
+	DewPoint  *float64 `json:"dewPoint,omitempty"`
+	QNH       *float64 `json:"qnh,omitempty"`
+	Altimeter *float64 `json:"altimeter,omitempty"`
+
+	Recent    []Phenomenon  `json:"recent,omitempty"`
+	WindShear []string      `json:"windShear,omitempty"`
+	Runways   []RunwayState `json:"runwayState,omitempty"`
+	Trend     string        `json:"trend,omitempty"`
+	Remarks   string        `json:"remarks,omitempty"`
+	// SeaLevel (hPa) e Precip (polegadas na última hora) vêm das observações
+	SeaLevel *float64 `json:"seaLevelPressure,omitempty"`
+	Precip   *float64 `json:"precipitation,omitempty"`
+	Unparsed []string `json:"unparsed,omitempty"`
+}
+
+// Wind é o grupo de vento. Direction nil indica vento variável (VRB); a
+// velocidade está em Unit (kn, m/s ou km/h).
+type Wind struct {
+	Direction    *float64 `json:"direction,omitempty"`
+	Speed        float64  `json:"speed"`
+	Gust         *float64 `json:"gust,omitempty"`
+	Unit         string   `json:"unit"`
+	VariableFrom *float64 `json:"variableFrom,omitempty"`
+	VariableTo   *float64 `json:"variableTo,omitempty"`
+}
+
+// Visibility é a visibilidade predominante em Unit (m ou mi). MoreThan e
+// LessThan marcam os limites do instrumento (9999, P6SM, M1/4SM).
+type Visibility struct {
+	Distance     float64  `json:"distance"`
+	Unit         string   `json:"unit"`
+	MoreThan     bool     `json:"moreThan,omitempty"`
+	LessThan     bool     `json:"lessThan,omitempty"`
+	Minimum      *float64 `json:"minimum,omitempty"`
+	MinDirection string   `json:"minDirection,omitempty"`
+}
+
+// RVR é o alcance visual na pista, em Unit (m ou ft). Max é preenchido quando
+// o alcance varia (R28L/4000V6000FT).
+type RVR struct {
+	Runway   string   `json:"runway"`
+	Distance float64  `json:"distance"`
+	Max      *float64 `json:"max,omitempty"`
+	Unit     string   `json:"unit"`
+	Prefix   string   `json:"prefix,omitempty"`
+	Tendency string   `json:"tendency,omitempty"`
+}
+
+// Phenomenon é um grupo de tempo presente ou recente (ex.: -SHRA, VCTS)
+type Phenomenon struct {
+	Intensity  string   `json:"intensity,omitempty"`
+	Descriptor string   `json:"descriptor,omitempty"`
+	Codes      []string `json:"codes,omitempty"`
+}
+
+// CloudLayer é uma camada de nuvens; Height é a base em pés. Cover VV indica
+// céu obscurecido, com Height igual à visibilidade vertical.
+type CloudLayer struct {
+	Cover  string   `json:"cover"`
+	Height *float64 `json:"height,omitempty"`
+	Type   string   `json:"type,omitempty"`
+}
+
+// RunwayState é o grupo de estado da pista (R06R/190050), mantido sem decodificar
+type RunwayState struct {
+	Runway string `json:"runway"`
+	State  string `json:"state"`
+}
+
+var (
+	stationRe     = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	timeRe        = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	windRe        = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	windVarRe     = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	visMetersRe   = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	visMinRe      = regexp.MustCompile(`^(\d{4})(N|NE|E|SE|S|SW|W|NW)$`)
+	visMilesRe    = regexp.MustCompile(`^([PM])?(\d+)?(?:(\d)/(\d{1,2}))?SM$`)
+	wholeMilesRe  = regexp.MustCompile(`^\d$`)
+	rvrRe         = regexp.MustCompile(`^R(\d{2}[LCR]?)/([PM])?(\d{4})(?:V([PM])?(\d{4}))?(FT)?/?([UDN])?$`)
+	runwayStateRe = regexp.MustCompile(`^R(\d{2}[LCR]?)/([0-9/]{6}|CLRD//)$`)
+	weatherRe     = regexp.MustCompile(`^(-|\+|VC)?(MI|BC|PR|DR|BL|SH|TS|FZ)?((?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*)$`)
+	cloudRe       = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(CB|TCU|///)?$`)
+	tempRe        = regexp.MustCompile(`^(M?\d{2}|//)/(M?\d{2}|//)?$`)
+	qnhRe         = regexp.MustCompile(`^Q(\d{4})$`)
+	altimeterRe   = regexp.MustCompile(`^A(\d{4})$`)
+	rmkTempRe     = regexp.MustCompile(`^T([01])(\d{3})([01])(\d{3})$`)
+	rmkSLPRe      = regexp.MustCompile(`^SLP(\d{3})$`)
+	rmkPrecipRe   = regexp.MustCompile(`^P(\d{4})$`)
+)
+
+// Parse decodifica um boletim. Grupos não reconhecidos no corpo vão para
+// Unparsed em vez de rejeitar o boletim; indicador da estação e horário são
+// obrigatórios.
+func Parse(text string) (*Report, error) {
+	tokens := strings.Fields(strings.ToUpper(strings.TrimSpace(text)))
+	if n := len(tokens); n > 0 {
+		tokens[n-1] = strings.TrimSuffix(tokens[n-1], "=")
+		if tokens[n-1] == "" {
+			tokens = tokens[:n-1]
+		}
+	}
+
+	r := &Report{Kind: "METAR"}
+	i := 0
+	next := func() string {
+		if i < len(tokens) {
+			return tokens[i]
+		}
+		return ""
+	}
+
+	if t := next(); t == "METAR" || t == "SPECI" {
+		r.Kind = t
+		i++
+	}
+	if next() == "COR" {
+		r.Corrected = true
+		i++
+	}
+	if !stationRe.MatchString(next()) {
+		return nil, fmt.Errorf("indicador de estação inválido: %q", next())
+	}
+	r.Station = next()
+	i++
+
+	m := timeRe.FindStringSubmatch(next())
+	if m == nil {
+		return nil, fmt.Errorf("horário inválido: %q", next())
+	}
+	r.Day, r.Hour, r.Minute = atoi(m[1]), atoi(m[2]), atoi(m[3])
+	if r.Day < 1 || r.Day > 31 || r.Hour > 23 || r.Minute > 59 {
+		return nil, fmt.Errorf("horário inválido: %q", next())
+	}
+	i++
+
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t == "NIL":
+			return r, ErrNil
+		case t == "AUTO":
+			r.Auto = true
+		case t == "COR":
+			r.Corrected = true
+		case t == "RMK":
+			r.parseRemarks(tokens[i+1:])
+			return r, nil
+		case t == "NOSIG" || t == "BECMG" || t == "TEMPO":
+			// A tendência vai até as observações
+			end := i
+			for end < len(tokens) && tokens[end] != "RMK" {
+				end++
+			}
+			r.Trend = strings.Join(tokens[i:end], " ")
+			i = end - 1
+		case t == "WS":
+			// Tesoura de vento: WS R09 ou WS ALL RWY
+			switch {
+			case i+2 < len(tokens) && tokens[i+1] == "ALL" && tokens[i+2] == "RWY":
+				r.WindShear = append(r.WindShear, "ALL RWY")
+				i += 2
+			case i+1 < len(tokens) && strings.HasPrefix(tokens[i+1], "R"):
+				r.WindShear = append(r.WindShear, tokens[i+1])
+				i++
+			default:
+				r.Unparsed = append(r.Unparsed, t)
+			}
+		case t == "CAVOK":
+			r.CAVOK = true
+		case t == "NSC" || t == "NCD" || t == "SKC" || t == "CLR":
+			r.SkyClear = t
+		case t == "//" || t == "////" || t == "//////":
+			// Grupo não observado pela estação automática
+		default:
+			if !r.parseGroup(t, tokens, &i) {
+				r.Unparsed = append(r.Unparsed, t)
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseGroup reconhece os grupos do corpo do boletim; i avança quando o grupo
+// ocupa mais de um token (visibilidade em milhas com fração)
+func (r *Report) parseGroup(t string, tokens []string, i *int) bool {
+	if m := windRe.FindStringSubmatch(t); m != nil {
+		w := &Wind{Speed: *number(m[2]), Unit: windUnit(m[4])}
+		if m[1] != "VRB" {
+			w.Direction = number(m[1])
+		}
+		if m[3] != "" {
+			w.Gust = number(m[3])
+		}
+		r.Wind = w
+		return true
+	}
+	if m := windVarRe.FindStringSubmatch(t); m != nil && r.Wind != nil {
+		r.Wind.VariableFrom = number(m[1])
+		r.Wind.VariableTo = number(m[2])
+		return true
+	}
+	if m := visMetersRe.FindStringSubmatch(t); m != nil && r.Visibility == nil {
+		v := &Visibility{Distance: float64(atoi(m[1])), Unit: "m"}
+		if v.Distance == 9999 {
+			v.Distance, v.MoreThan = 10000, true
+		}
+		r.Visibility = v
+		return true
+	}
+	if m := visMinRe.FindStringSubmatch(t); m != nil && r.Visibility != nil {
+		r.Visibility.Minimum = number(m[1])
+		r.Visibility.MinDirection = m[2]
+		return true
+	}
+	if wholeMilesRe.MatchString(t) && *i+1 < len(tokens) && strings.HasSuffix(tokens[*i+1], "SM") {
+		// "1 1/2SM": milhas inteiras seguidas da fração
+		if v, ok := parseMiles(tokens[*i+1]); ok && v.Distance < 1 {
+			v.Distance += float64(atoi(t))
+			r.Visibility = v
+			*i++
+			return true
+		}
+	}
+	if v, ok := parseMiles(t); ok {
+		r.Visibility = v
+		return true
+	}
+	if m := rvrRe.FindStringSubmatch(t); m != nil {
+		rvr := RVR{Runway: m[1], Prefix: m[2], Distance: float64(atoi(m[3])), Unit: "m", Tendency: m[7]}
+		if m[5] != "" {
+			rvr.Max = number(m[5])
+		}
+		if m[6] == "FT" {
+			rvr.Unit = "ft"
+		}
+		r.RVR = append(r.RVR, rvr)
+		return true
+	}
+	if m := runwayStateRe.FindStringSubmatch(t); m != nil {
+		r.Runways = append(r.Runways, RunwayState{Runway: m[1], State: m[2]})
+		return true
+	}
+	if strings.HasPrefix(t, "RE") && len(t) > 2 {
+		if p, ok := parsePhenomenon(t[2:]); ok {
+			r.Recent = append(r.Recent, p)
+			return true
+		}
+	}
+	if p, ok := parsePhenomenon(t); ok {
+		r.Weather = append(r.Weather, p)
+		return true
+	}
+	if m := cloudRe.FindStringSubmatch(t); m != nil {
+		layer := CloudLayer{Cover: m[1]}
+		if m[2] != "///" {
+			layer.Height = float(*number(m[2]) * 100)
+		}
+		if m[3] != "///" {
+			layer.Type = m[3]
+		}
+		r.Clouds = append(r.Clouds, layer)
+		return true
+	}
+	if m := tempRe.FindStringSubmatch(t); m != nil {
+		r.Temp = parseTemp(m[1])
+		r.DewPoint = parseTemp(m[2])
+		return true
+	}
+	if m := qnhRe.FindStringSubmatch(t); m != nil {
+		r.QNH = number(m[1])
+		return true
+	}
+	if m := altimeterRe.FindStringSubmatch(t); m != nil {
+		r.Altimeter = float(*number(m[1]) / 100)
+		return true
+	}
+	return false
+}
+
+// parseRemarks guarda o texto das observações e decodifica os grupos
+// norte-americanos com temperatura em décimos (T), pressão ao nível do mar
+// (SLP) e precipitação da última hora (P, em centésimos de polegada)
+func (r *Report) parseRemarks(tokens []string) {
+	r.Remarks = strings.Join(tokens, " ")
+	for _, t := range tokens {
+		if m := rmkTempRe.FindStringSubmatch(t); m != nil {
+			r.Temp = float(tenths(m[1], m[2]))
+			r.DewPoint = float(tenths(m[3], m[4]))
+		} else if m := rmkSLPRe.FindStringSubmatch(t); m != nil {
+			// Só as três últimas casas em décimos de hPa: 145 = 1014,5, 982 = 998,2
+			slp := float64(atoi(m[1])) / 10
+			if slp < 60 {
+				slp += 1000
+			} else {
+				slp += 900
+			}
+			r.SeaLevel = &slp
+		} else if m := rmkPrecipRe.FindStringSubmatch(t); m != nil {
+			r.Precip = float(*number(m[1]) / 100)
+		}
+	}
+}
+
+// Time resolve o dia do boletim para o instante mais recente, até ref, com
+// aquele dia, hora e minuto. Uma hora de tolerância cobre relógios adiantados.
+func (r *Report) Time(ref time.Time) time.Time {
+	ref = ref.UTC()
+	limit := ref.Add(time.Hour)
+	for back := 0; back < 3; back++ {
+		t := time.Date(ref.Year(), ref.Month()-time.Month(back), r.Day, r.Hour, r.Minute, 0, 0, time.UTC)
+		if t.Day() == r.Day && !t.After(limit) {
+			return t
+		}
+	}
+	return time.Date(ref.Year(), ref.Month(), r.Day, r.Hour, r.Minute, 0, 0, time.UTC)
+}
+
+func parseMiles(t string) (*Visibility, bool) {
+	m := visMilesRe.FindStringSubmatch(t)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return nil, false
+	}
+	v := &Visibility{Unit: "mi", MoreThan: m[1] == "P", LessThan: m[1] == "M"}
+	if m[2] != "" {
+		v.Distance = float64(atoi(m[2]))
+	}
+	if m[3] != "" {
+		den := atoi(m[4])
+		if den == 0 {
+			return nil, false
+		}
+		v.Distance += float64(atoi(m[3])) / float64(den)
+	}
+	return v, true
+}
+
+func parsePhenomenon(t string) (Phenomenon, bool) {
+	m := weatherRe.FindStringSubmatch(t)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return Phenomenon{}, false
+	}
+	p := Phenomenon{Intensity: m[1], Descriptor: m[2]}
+	for c := m[3]; c != ""; c = c[2:] {
+		p.Codes = append(p.Codes, c[:2])
+	}
+	return p, true
+}
+
+func parseTemp(s string) *float64 {
+	if s == "" || s == "//" {
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(s, "M"); ok {
+		return float(-*number(rest))
+	}
+	return number(s)
+}
+
+func tenths(sign, digits string) float64 {
+	v := float64(atoi(digits)) / 10
+	if sign == "1" {
+		return -v
+	}
+	return v
+}
+
+func windUnit(u string) string {
+	switch u {
+	case "MPS":
+		return "m/s"
+	case "KMH":
+		return "km/h"
+	default:
+		return "kn"
+	}
+}
+
+// number converte grupos numéricos já validados pelas expressões regulares
+func number(s string) *float64 {
+	return float(float64(atoi(s)))
+}
+
+// atoi converte grupos já validados pelas expressões regulares
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func float(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,214 @@
+package metar
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "regrava testdata/corpus.golden.json")
+
+// decoded é uma entrada do arquivo golden do corpus
+type decoded struct {
+	Raw        string   `json:"raw"`
+	Report     *Report  `json:"report"`
+	WMOCode    int      `json:"wmoCode"`
+	CloudCover *float64 `json:"cloudCover,omitempty"`
+}
+
+func TestParse_Corpus(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "corpus.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var got []decoded
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		report, err := Parse(line)
+		if errors.Is(err, ErrNil) {
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", line, err)
+			continue
+		}
+		if len(report.Unparsed) > 0 {
+			t.Errorf("Parse(%q) unparsed groups %v", line, report.Unparsed)
+		}
+		entry := decoded{Raw: line, Report: report, WMOCode: report.WMOCode()}
+		if cover, ok := report.CloudCover(); ok {
+			entry.CloudCover = &cover
+		}
+		got = append(got, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "corpus.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(want) != string(data)+"\n" {
+		t.Errorf("corpus decodificado difere de %s; rode go test -update e revise o diff", golden)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("wind with gusts and variable direction", func(t *testing.T) {
+		r, err := Parse("METAR SBSP 151500Z 32015G27KT 280V360 4000 -TSRA BKN030 FEW035CB 26/20 Q1011 RERA=")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := r.Wind
+		if w == nil || *w.Direction != 320 || w.Speed != 15 || *w.Gust != 27 || w.Unit != "kn" || *w.VariableFrom != 280 || *w.VariableTo != 360 {
+			t.Errorf("Wind = %+v", w)
+		}
+		if len(r.Recent) != 1 || r.Recent[0].Codes[0] != "RA" {
+			t.Errorf("Recent = %+v", r.Recent)
+		}
+		if len(r.Clouds) != 2 || r.Clouds[1].Type != "CB" || *r.Clouds[1].Height != 3500 {
+			t.Errorf("Clouds = %+v", r.Clouds)
+		}
+	})
+
+	t.Run("fractional miles and rvr in feet", func(t *testing.T) {
+		r, err := Parse("METAR KORD 151351Z 27015KT 1 1/2SM R28L/4000VP6000FT -SN BR OVC008 M02/M04 A2978 RMK AO2 SLP091 P0002 T10171039")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := r.Visibility; v.Distance != 1.5 || v.Unit != "mi" {
+			t.Errorf("Visibility = %+v", v)
+		}
+		want := RVR{Runway: "28L", Distance: 4000, Max: float(6000), Unit: "ft"}
+		if len(r.RVR) != 1 || !reflect.DeepEqual(r.RVR[0], want) {
+			t.Errorf("RVR = %+v, want %+v", r.RVR, want)
+		}
+		// O grupo T das observações tem precedência sobre M02/M04
+		if *r.Temp != -1.7 || *r.DewPoint != -3.9 {
+			t.Errorf("Temp/DewPoint = %v/%v, want -1.7/-3.9", *r.Temp, *r.DewPoint)
+		}
+		if *r.Altimeter != 29.78 || *r.SeaLevel != 1009.1 || *r.Precip != 0.02 {
+			t.Errorf("Altimeter/SeaLevel/Precip = %v/%v/%v", *r.Altimeter, *r.SeaLevel, *r.Precip)
+		}
+	})
+
+	t.Run("cavok and variable wind", func(t *testing.T) {
+		r, err := Parse("METAR SBKP 151200Z VRB03KT CAVOK 24/12 Q1017=")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.CAVOK || r.Wind.Direction != nil || *r.QNH != 1017 {
+			t.Errorf("Report = %+v", r)
+		}
+	})
+
+	t.Run("trend and remarks are kept apart", func(t *testing.T) {
+		r, err := Parse("METAR LFPG 151400Z 22010KT 9999 -RA FEW012 14/12 Q1009 TEMPO 4000 RA RMK SKC")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Trend != "TEMPO 4000 RA" || r.Remarks != "SKC" || len(r.Weather) != 1 || r.SkyClear != "" {
+			t.Errorf("Trend = %q, Remarks = %q, Weather = %+v", r.Trend, r.Remarks, r.Weather)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, text := range []string{"", "METAR", "METAR SBGR 1514Z 14008KT", "METAR SBGR 321400Z 14008KT"} {
+			if _, err := Parse(text); err == nil {
+				t.Errorf("Parse(%q) error = nil", text)
+			}
+		}
+		if _, err := Parse("METAR SBGR 151500Z NIL="); !errors.Is(err, ErrNil) {
+			t.Errorf("Parse(NIL) error = %v, want ErrNil", err)
+		}
+	})
+}
+
+func TestReport_Time(t *testing.T) {
+	tests := []struct {
+		day, hour int
+		ref       string
+		want      string
+	}{
+		{15, 14, "2025-06-15T14:10:00Z", "2025-06-15T14:00:00Z"},
+		// Boletim ligeiramente adiantado em relação ao relógio local
+		{15, 14, "2025-06-15T13:30:00Z", "2025-06-15T14:00:00Z"},
+		{31, 23, "2025-07-01T00:20:00Z", "2025-05-31T23:00:00Z"},
+		{31, 23, "2025-01-01T00:20:00Z", "2024-12-31T23:00:00Z"},
+		{28, 12, "2025-03-01T06:00:00Z", "2025-02-28T12:00:00Z"},
+	}
+	for _, tt := range tests {
+		ref, _ := time.Parse(time.RFC3339, tt.ref)
+		r := &Report{Day: tt.day, Hour: tt.hour}
+		if got := r.Time(ref).Format(time.RFC3339); got != tt.want {
+			t.Errorf("Time(day %d, ref %s) = %s, want %s", tt.day, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestReport_WMOCode(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"SBGR 151400Z 14008KT CAVOK 25/18 Q1015", 0},
+		{"SBGR 151400Z 14008KT 9999 FEW025 25/18 Q1015", 1},
+		{"SBGR 151400Z 14008KT 9999 SCT025 25/18 Q1015", 2},
+		{"SBGR 151400Z 14008KT 9999 SCT025 BKN040 25/18 Q1015", 3},
+		{"SBGR 151400Z 14008KT 4000 HZ FEW025 25/18 Q1015", 1},
+		{"SBGR 151400Z 14008KT 9999 VCSH SCT025 25/18 Q1015", 2},
+		{"SBGR 151400Z 14008KT 0300 FG VV001 14/14 Q1018", 45},
+		{"UUEE 151400Z 36005MPS 0800 FZFG VV002 M08/M09 Q1030", 48},
+		{"SBRJ 151300Z 16012KT 6000 -DZ OVC025 22/21 Q1016", 51},
+		{"SBSP 151300Z 16012KT 6000 RA OVC025 22/21 Q1016", 63},
+		{"SBSP 151300Z 16012KT 2000 +SHRA OVC025 22/21 Q1016", 82},
+		{"KDEN 152217Z 24018KT 1/2SM +FZRA PL OVC004 M01/M02 A2985", 77},
+		{"KORD 151351Z 27015KT 1SM -SN BR OVC008 M02/M04 A2978", 71},
+		{"SBSP 151500Z 32015KT 4000 -TSRA BKN030 26/20 Q1011", 95},
+		{"SBPA 150900Z 20025KT 1500 +TSRAGR BKN020CB 16/15 Q1005", 99},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.text)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.text, err)
+		}
+		if got := r.WMOCode(); got != tt.want {
+			t.Errorf("WMOCode(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLookupStation(t *testing.T) {
+	for _, icao := range []string{"SBGR", "SBSP", "sbkp"} {
+		if st, ok := LookupStation(icao); !ok || st.Latitude > -22 || st.Elevation < 600 {
+			t.Errorf("LookupStation(%q) = %+v, %v", icao, st, ok)
+		}
+	}
+	if _, ok := LookupStation("ZZZZ"); ok {
+		t.Error("LookupStation(ZZZZ) ok = true")
+	}
+}
@@ -0,0 +1,76 @@
+package metar
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/aerodromos.csv
+var embeddedStations []byte
+
+// Station é um aeródromo que emite METAR
+type Station struct {
+	ICAO      string  `json:"icao"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Elevation float64 `json:"elevation"`
+}
+
+// ParseStationsCSV lê aeródromos no formato icao,nome,latitude,longitude,altitude.
+// Linhas iniciadas com '#' e o cabeçalho são ignorados.
+func ParseStationsCSV(r io.Reader) (map[string]Station, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	stations := make(map[string]Station)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("aeródromos: %w", err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "icao") {
+			continue
+		}
+		if len(record) < 5 {
+			return nil, fmt.Errorf("aeródromos: linha %d: esperado icao,nome,latitude,longitude,altitude", line)
+		}
+
+		var values [3]float64
+		for i, field := range []string{"latitude", "longitude", "altitude"} {
+			if values[i], err = strconv.ParseFloat(strings.TrimSpace(record[i+2]), 64); err != nil {
+				return nil, fmt.Errorf("aeródromos: linha %d: %s: %w", line, field, err)
+			}
+		}
+		icao := strings.ToUpper(strings.TrimSpace(record[0]))
+		stations[icao] = Station{ICAO: icao, Name: record[1], Latitude: values[0], Longitude: values[1], Elevation: values[2]}
+	}
+	return stations, nil
+}
+
+var (
+	stationsOnce sync.Once
+	stations     map[string]Station
+)
+
+// LookupStation retorna o aeródromo da tabela embutida no binário
+func LookupStation(icao string) (Station, bool) {
+	stationsOnce.Do(func() {
+		var err error
+		if stations, err = ParseStationsCSV(bytes.NewReader(embeddedStations)); err != nil {
+			panic(err) // arquivo embutido validado pelos testes
+		}
+	})
+	st, ok := stations[strings.ToUpper(icao)]
+	return st, ok
+}
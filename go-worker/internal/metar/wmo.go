@@ -0,0 +1,116 @@
+package metar
+
+// Oktas (oitavos de céu) no meio da faixa de cada cobertura
+var coverOktas = map[string]float64{
+	"FEW": 1.5,
+	"SCT": 3.5,
+	"BKN": 6,
+	"OVC": 8,
+	"VV":  8,
+}
+
+// CloudCover estima a nebulosidade total (%) pela camada de maior cobertura.
+// Sem grupo de nuvens, e sem CAVOK ou céu claro declarado, não há estimativa.
+func (r *Report) CloudCover() (float64, bool) {
+	if len(r.Clouds) == 0 {
+		return 0, r.CAVOK || r.SkyClear != ""
+	}
+	var oktas float64
+	for _, layer := range r.Clouds {
+		if o := coverOktas[layer.Cover]; o > oktas {
+			oktas = o
+		}
+	}
+	return oktas / 8 * 100, true
+}
+
+// WMOCode aproxima o tempo presente por um código da tabela WMO 4677 usada
+// pela Open-Meteo. Entre vários fenômenos vale o de maior código, que na
+// tabela é o mais significativo; fenômenos nas vizinhanças (VC) e névoa seca,
+// fumaça ou poeira não entram, e sem precipitação ou nevoeiro o código vem da
+// nebulosidade.
+func (r *Report) WMOCode() int {
+	code := -1
+	for _, p := range r.Weather {
+		if c, ok := p.wmoCode(); ok && c > code {
+			code = c
+		}
+	}
+	if code >= 0 {
+		return code
+	}
+
+	if cover, ok := r.CloudCover(); ok {
+		for _, layer := range r.Clouds {
+			if layer.Cover == "VV" {
+				return 45 // céu obscurecido
+			}
+		}
+		switch {
+		case cover > 50:
+			return 3
+		case cover > 25:
+			return 2
+		case cover > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// wmoCode converte um grupo de tempo presente; ok é falso para fenômenos sem
+// equivalente na tabela
+func (p Phenomenon) wmoCode() (int, bool) {
+	if p.Intensity == "VC" {
+		return 0, false
+	}
+	// 0 fraca, 1 moderada, 2 forte
+	level := 1
+	switch p.Intensity {
+	case "-":
+		level = 0
+	case "+":
+		level = 2
+	}
+	pick := func(codes ...int) int { return codes[level] }
+
+	has := func(code string) bool {
+		for _, c := range p.Codes {
+			if c == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case p.Descriptor == "TS" && (has("GR") || has("GS")):
+		if level == 2 {
+			return 99, true
+		}
+		return 96, true
+	case p.Descriptor == "TS":
+		return 95, true
+	case p.Descriptor == "SH" && (has("SN") || has("GS")):
+		return pick(85, 86, 86), true
+	case has("SN"):
+		return pick(71, 73, 75), true
+	case has("SG") || has("PL") || has("IC"):
+		return 77, true
+	case p.Descriptor == "FZ" && has("RA"):
+		return pick(66, 67, 67), true
+	case p.Descriptor == "SH" && (has("RA") || has("GR")), has("GR"):
+		return pick(80, 81, 82), true
+	case has("RA"):
+		return pick(61, 63, 65), true
+	case p.Descriptor == "FZ" && has("DZ"):
+		return pick(56, 57, 57), true
+	case has("DZ"), has("UP"):
+		return pick(51, 53, 55), true
+	case p.Descriptor == "FZ" && has("FG"):
+		return 48, true
+	case has("FG"):
+		return 45, true
+	}
+	return 0, false
+}
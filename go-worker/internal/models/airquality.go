@@ -0,0 +1,230 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go-worker/internal/aqi"
+	"go-worker/internal/units"
+)
+
+// AirQualityCurrent são as concentrações medidas, em µg/m³ (ou na unidade
+// declarada em units). Poluentes ausentes ficam nil.
+type AirQualityCurrent struct {
+	Time            string   `json:"time,omitempty"`
+	PM25            *float64 `json:"pm2_5,omitempty"`
+	PM10            *float64 `json:"pm10,omitempty"`
+	Ozone           *float64 `json:"ozone,omitempty"`
+	NitrogenDioxide *float64 `json:"nitrogen_dioxide,omitempty"`
+	SulphurDioxide  *float64 `json:"sulphur_dioxide,omitempty"`
+	CarbonMonoxide  *float64 `json:"carbon_monoxide,omitempty"`
+}
+
+// AirQualityMessage é a mensagem de qualidade do ar, com a localização e os
+// horários no formato de WeatherMessage e os poluentes da API de qualidade do
+// ar da Open-Meteo
+type AirQualityMessage struct {
+	Timestamp string            `json:"timestamp"`
+	Location  WeatherLocation   `json:"location"`
+	Current   AirQualityCurrent `json:"current"`
+	Source    string            `json:"source,omitempty"`
+
+	// Units declara a unidade de cada poluente: µg/m³, mg/m³ ou, para os
+	// gases, ppb e ppm. Poluentes sem unidade declarada estão em µg/m³.
+	Units map[string]string `json:"units,omitempty"`
+}
+
+// AirQualityLog é o payload de qualidade do ar enviado para a API NestJS
+type AirQualityLog struct {
+	Timestamp string  `json:"timestamp,omitempty"`
+	Location  string  `json:"location"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Source    string  `json:"source"`
+
+	// Concentrações em µg/m³
+	PM25            *float64 `json:"pm25,omitempty"`
+	PM10            *float64 `json:"pm10,omitempty"`
+	Ozone           *float64 `json:"ozone,omitempty"`
+	NitrogenDioxide *float64 `json:"nitrogenDioxide,omitempty"`
+	SulphurDioxide  *float64 `json:"sulphurDioxide,omitempty"`
+	CarbonMonoxide  *float64 `json:"carbonMonoxide,omitempty"`
+
+	// USAQI é o AQI da EPA e IQAr o índice da Resolução CONAMA 491/2018;
+	// ausentes quando nenhum poluente do padrão foi informado
+	USAQI *aqi.Index `json:"usAqi,omitempty"`
+	IQAr  *aqi.Index `json:"iqar,omitempty"`
+}
+
+// pollutants liga cada poluente ao campo da mensagem, com o máximo plausível
+// em µg/m³, bem acima dos piores episódios registrados
+var pollutants = []struct {
+	key   string
+	max   float64
+	value func(c *AirQualityCurrent) *float64
+}{
+	{aqi.PM25, 2000, func(c *AirQualityCurrent) *float64 { return c.PM25 }},
+	{aqi.PM10, 5000, func(c *AirQualityCurrent) *float64 { return c.PM10 }},
+	{aqi.Ozone, 2000, func(c *AirQualityCurrent) *float64 { return c.Ozone }},
+	{aqi.NitrogenDioxide, 5000, func(c *AirQualityCurrent) *float64 { return c.NitrogenDioxide }},
+	{aqi.SulphurDioxide, 5000, func(c *AirQualityCurrent) *float64 { return c.SulphurDioxide }},
+	{aqi.CarbonMonoxide, 100000, func(c *AirQualityCurrent) *float64 { return c.CarbonMonoxide }},
+}
+
+// Concentrations retorna os poluentes informados, em µg/m³
+func (c *AirQualityCurrent) Concentrations() aqi.Concentrations {
+	out := make(aqi.Concentrations, len(pollutants))
+	for _, p := range pollutants {
+		if v := p.value(c); v != nil {
+			out[p.key] = *v
+		}
+	}
+	return out
+}
+
+// weather expõe horários e localização como uma WeatherMessage, para reusar
+// as regras de horário e a resolução da localização das observações
+func (a *AirQualityMessage) weather() *WeatherMessage {
+	return &WeatherMessage{
+		Timestamp: a.Timestamp,
+		Location:  a.Location,
+		Current:   WeatherCurrent{Time: a.Current.Time},
+		Source:    a.Source,
+	}
+}
+
+// ObservedAt é o instante da medição, como em WeatherMessage.ObservedAt
+func (a *AirQualityMessage) ObservedAt() (time.Time, error) {
+	return a.weather().ObservedAt()
+}
+
+// NormalizeUnits converte os poluentes declarados em units para µg/m³. Em
+// caso de unidade desconhecida a mensagem não é alterada; após a conversão o
+// bloco é descartado.
+func (a *AirQualityMessage) NormalizeUnits() ([]UnitConversion, error) {
+	if len(a.Units) == 0 {
+		return nil, nil
+	}
+
+	type pending struct {
+		target *float64
+		value  float64
+	}
+	var (
+		conversions []UnitConversion
+		updates     []pending
+	)
+	for _, p := range pollutants {
+		unit, ok := a.Units[p.key]
+		if !ok || units.IsBase(units.DimConcentration, unit) {
+			continue
+		}
+		target := p.value(&a.Current)
+		value := 0.0
+		if target != nil {
+			value = *target
+		}
+		converted, err := units.NewConcentration(value, unit, aqi.MolarMass[p.key])
+		if err != nil {
+			return nil, fmt.Errorf("units.%s: %w", p.key, err)
+		}
+		if target == nil {
+			continue
+		}
+		v := math.Round(converted.MicrogramsPerCubicMeter()*1000) / 1000
+		updates = append(updates, pending{target, v})
+		conversions = append(conversions, UnitConversion{
+			Field: p.key, From: unit, To: units.Base(units.DimConcentration), Value: v,
+		})
+	}
+
+	for _, u := range updates {
+		*u.target = u.value
+	}
+	a.Units = nil
+	return conversions, nil
+}
+
+// CheckRules avalia as regras de validação, na ordem de Validate: horários e
+// coordenadas como nas observações, ao menos um poluente e cada concentração
+// entre zero e o máximo plausível
+func (a *AirQualityMessage) CheckRules() []RuleResult {
+	w := a.weather()
+	rules := []RuleResult{
+		{Rule: "required", Field: "timestamp", Value: a.Timestamp,
+			Passed: a.Timestamp != "", Err: ErrInvalidTimestamp},
+	}
+	rules = append(rules, w.timestampRules()...)
+	rules = append(rules,
+		RuleResult{Rule: "range[-90,90]", Field: "location.latitude", Value: a.Location.Latitude,
+			Passed: a.Location.Latitude >= -90 && a.Location.Latitude <= 90, Err: ErrInvalidLocation},
+		RuleResult{Rule: "range[-180,180]", Field: "location.longitude", Value: a.Location.Longitude,
+			Passed: a.Location.Longitude >= -180 && a.Location.Longitude <= 180, Err: ErrInvalidLocation},
+		RuleResult{Rule: "required[any]", Field: "current", Value: len(a.Current.Concentrations()),
+			Passed: len(a.Current.Concentrations()) > 0, Err: ErrNoPollutants},
+	)
+	for _, p := range pollutants {
+		v := p.value(&a.Current)
+		rules = append(rules,
+			RuleResult{Rule: "min[0]", Field: "current." + p.key, Value: v,
+				Passed: optionalInRange(v, 0, math.Inf(1)), Err: ErrInvalidConcentration},
+			RuleResult{Rule: fmt.Sprintf("max[%g]", p.max), Field: "current." + p.key, Value: v,
+				Passed: v == nil || *v <= p.max, Err: ErrImplausibleConcentration},
+		)
+	}
+	return rules
+}
+
+// Validate valida a mensagem. O erro é um *ValidationError com todas as
+// regras violadas.
+func (a *AirQualityMessage) Validate() error {
+	var violations []Violation
+	for _, r := range a.CheckRules() {
+		if !r.Passed {
+			violations = append(violations, r.violation())
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ResolveLocation retorna o nome da localização e o método usado, como nas
+// observações
+func (a *AirQualityMessage) ResolveLocation() (string, string) {
+	return a.weather().ResolveLocation()
+}
+
+// ToAirQualityLog converte a mensagem no payload da API, com os índices da
+// EPA e da CONAMA calculados a partir das concentrações
+func (a *AirQualityMessage) ToAirQualityLog() AirQualityLog {
+	w := a.weather()
+	var timestamp string
+	if observed, err := w.ObservedAt(); err == nil {
+		timestamp = observed.Format(time.RFC3339)
+	}
+	out := AirQualityLog{
+		Timestamp: timestamp,
+		Location:  w.GetLocationString(),
+		Latitude:  a.Location.Latitude,
+		Longitude: a.Location.Longitude,
+		Source:    w.source(),
+
+		PM25:            a.Current.PM25,
+		PM10:            a.Current.PM10,
+		Ozone:           a.Current.Ozone,
+		NitrogenDioxide: a.Current.NitrogenDioxide,
+		SulphurDioxide:  a.Current.SulphurDioxide,
+		CarbonMonoxide:  a.Current.CarbonMonoxide,
+	}
+	concentrations := a.Current.Concentrations()
+	if idx, ok := aqi.EPA.Compute(concentrations); ok {
+		out.USAQI = &idx
+	}
+	if idx, ok := aqi.CONAMA.Compute(concentrations); ok {
+		out.IQAr = &idx
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAirQualityMessage_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		wantErr error
+	}{
+		{
+			name:    "valid message",
+			message: `{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"pm2_5":12.4,"pm10":30,"ozone":80}}`,
+		},
+		{
+			name:    "no pollutants",
+			message: `{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{}}`,
+			wantErr: ErrNoPollutants,
+		},
+		{
+			name:    "negative concentration",
+			message: `{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"pm10":-3}}`,
+			wantErr: ErrInvalidConcentration,
+		},
+		{
+			name:    "implausible concentration",
+			message: `{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"pm2_5":12000}}`,
+			wantErr: ErrImplausibleConcentration,
+		},
+		{
+			name:    "missing timestamp",
+			message: `{"location":{"latitude":-23.55,"longitude":-46.63},"current":{"pm2_5":12}}`,
+			wantErr: ErrInvalidTimestamp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg AirQualityMessage
+			if err := json.Unmarshal([]byte(tt.message), &msg); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			err := msg.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAirQualityMessage_ToAirQualityLog(t *testing.T) {
+	msg := AirQualityMessage{
+		Timestamp: "2025-06-15T14:00:00Z",
+		Location:  WeatherLocation{Latitude: -23.55, Longitude: -46.63},
+		Current:   AirQualityCurrent{PM25: Float64(12), PM10: Float64(75), NitrogenDioxide: Float64(100)},
+		Units:     map[string]string{"pm2_5": "μg/m³", "nitrogen_dioxide": "ppb"},
+	}
+	conversions, err := msg.NormalizeUnits()
+	if err != nil {
+		t.Fatalf("NormalizeUnits() error = %v", err)
+	}
+	if len(conversions) != 1 || conversions[0].Field != "nitrogen_dioxide" || conversions[0].Value != 188.162 {
+		t.Errorf("conversions = %+v, want nitrogen_dioxide 188.162 µg/m³", conversions)
+	}
+
+	log := msg.ToAirQualityLog()
+	if log.USAQI == nil || log.USAQI.Index != 100 || log.USAQI.Dominant != "nitrogen_dioxide" {
+		t.Errorf("USAQI = %+v, want 100 dominated by nitrogen_dioxide", log.USAQI)
+	}
+	if log.IQAr == nil || log.IQAr.Index != 61 || log.IQAr.Category != "Moderada" || log.IQAr.Dominant != "pm10" {
+		t.Errorf("IQAr = %+v, want 61 Moderada dominated by pm10", log.IQAr)
+	}
+	if log.Timestamp != "2025-06-15T14:00:00Z" || log.Source != DefaultSource {
+		t.Errorf("log = %+v", log)
+	}
+
+	if _, err := (&AirQualityMessage{Units: map[string]string{"pm10": "ppb"}}).NormalizeUnits(); err == nil {
+		t.Error("NormalizeUnits(pm10 in ppb) error = nil, want unknown unit")
+	}
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Severidades aceitas nos alertas, as mesmas do CAP (Common Alerting Protocol)
+var alertSeverities = []string{"minor", "moderate", "severe", "extreme"}
+
+// AlertMessage é um alerta meteorológico (tempestade, onda de calor, ...)
+// emitido por um provedor para uma localização e um período
+type AlertMessage struct {
+	Event       string          `json:"event"`
+	Severity    string          `json:"severity"`
+	Headline    string          `json:"headline,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Onset       string          `json:"onset"`
+	Expires     string          `json:"expires"`
+	Location    WeatherLocation `json:"location"`
+	Source      string          `json:"source,omitempty"`
+}
+
+// AlertLog é o alerta publicado pelo worker, com os horários em UTC e a
+// localização resolvida como nas observações
+type AlertLog struct {
+	Event       string  `json:"event"`
+	Severity    string  `json:"severity"`
+	Headline    string  `json:"headline,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Onset       string  `json:"onset"`
+	Expires     string  `json:"expires"`
+	Location    string  `json:"location"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Source      string  `json:"source"`
+}
+
+// weather expõe a localização como uma WeatherMessage, para reusar a
+// resolução da localização das observações
+func (a *AlertMessage) weather() *WeatherMessage {
+	return &WeatherMessage{Location: a.Location, Source: a.Source}
+}
+
+// period interpreta onset e expires; horários sem fuso são UTC
+func (a *AlertMessage) period() (onset, expires time.Time, onsetErr, expiresErr error) {
+	onset, onsetErr = ParseTimestamp(a.Onset)
+	expires, expiresErr = ParseTimestamp(a.Expires)
+	return onset, expires, onsetErr, expiresErr
+}
+
+// CheckRules avalia as regras de validação, na ordem de Validate: evento,
+// severidade, período e coordenadas
+func (a *AlertMessage) CheckRules() []RuleResult {
+	onset, expires, onsetErr, expiresErr := a.period()
+	severity := strings.ToLower(a.Severity)
+	knownSeverity := false
+	for _, s := range alertSeverities {
+		knownSeverity = knownSeverity || s == severity
+	}
+	return []RuleResult{
+		{Rule: "required", Field: "event", Value: a.Event,
+			Passed: strings.TrimSpace(a.Event) != "", Err: ErrNoAlertEvent},
+		{Rule: "enum[" + strings.Join(alertSeverities, ",") + "]", Field: "severity", Value: a.Severity,
+			Passed: knownSeverity, Err: ErrInvalidSeverity},
+		{Rule: "format[rfc3339]", Field: "onset", Value: a.Onset,
+			Passed: onsetErr == nil, Err: ErrInvalidTimestamp},
+		{Rule: "format[rfc3339]", Field: "expires", Value: a.Expires,
+			Passed: expiresErr == nil, Err: ErrInvalidTimestamp},
+		{Rule: "after[onset]", Field: "expires", Value: a.Expires,
+			Passed: onsetErr != nil || expiresErr != nil || expires.After(onset), Err: ErrInvalidAlertPeriod},
+		{Rule: "range[-90,90]", Field: "location.latitude", Value: a.Location.Latitude,
+			Passed: a.Location.Latitude >= -90 && a.Location.Latitude <= 90, Err: ErrInvalidLocation},
+		{Rule: "range[-180,180]", Field: "location.longitude", Value: a.Location.Longitude,
+			Passed: a.Location.Longitude >= -180 && a.Location.Longitude <= 180, Err: ErrInvalidLocation},
+	}
+}
+
+// Validate valida o alerta. O erro é um *ValidationError com todas as regras
+// violadas.
+func (a *AlertMessage) Validate() error {
+	var violations []Violation
+	for _, r := range a.CheckRules() {
+		if !r.Passed {
+			violations = append(violations, r.violation())
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ResolveLocation retorna o nome da localização e o método usado, como nas
+// observações
+func (a *AlertMessage) ResolveLocation() (string, string) {
+	return a.weather().ResolveLocation()
+}
+
+// ToAlertLog converte o alerta validado no registro publicado
+func (a *AlertMessage) ToAlertLog() AlertLog {
+	w := a.weather()
+	onset, expires, _, _ := a.period()
+	return AlertLog{
+		Event:       strings.TrimSpace(a.Event),
+		Severity:    strings.ToLower(a.Severity),
+		Headline:    a.Headline,
+		Description: a.Description,
+		Onset:       onset.Format(time.RFC3339),
+		Expires:     expires.Format(time.RFC3339),
+		Location:    w.GetLocationString(),
+		Latitude:    a.Location.Latitude,
+		Longitude:   a.Location.Longitude,
+		Source:      w.source(),
+	}
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAlertMessage_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		wantErr error
+	}{
+		{
+			name:    "valid alert",
+			message: `{"event":"Tempestade","severity":"Severe","onset":"2025-06-15T18:00:00Z","expires":"2025-06-16T06:00:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`,
+		},
+		{
+			name:    "missing event",
+			message: `{"severity":"minor","onset":"2025-06-15T18:00:00Z","expires":"2025-06-16T06:00:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`,
+			wantErr: ErrNoAlertEvent,
+		},
+		{
+			name:    "unknown severity",
+			message: `{"event":"Tempestade","severity":"alta","onset":"2025-06-15T18:00:00Z","expires":"2025-06-16T06:00:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`,
+			wantErr: ErrInvalidSeverity,
+		},
+		{
+			name:    "missing onset",
+			message: `{"event":"Tempestade","severity":"minor","expires":"2025-06-16T06:00:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`,
+			wantErr: ErrInvalidTimestamp,
+		},
+		{
+			name:    "expires before onset",
+			message: `{"event":"Tempestade","severity":"minor","onset":"2025-06-16T06:00:00Z","expires":"2025-06-15T18:00:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`,
+			wantErr: ErrInvalidAlertPeriod,
+		},
+		{
+			name:    "invalid location",
+			message: `{"event":"Tempestade","severity":"minor","onset":"2025-06-15T18:00:00Z","expires":"2025-06-16T06:00:00Z","location":{"latitude":-123.55,"longitude":-46.63}}`,
+			wantErr: ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg AlertMessage
+			if err := json.Unmarshal([]byte(tt.message), &msg); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			err := msg.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlertMessage_ToAlertLog(t *testing.T) {
+	msg := AlertMessage{
+		Event:    " Tempestade ",
+		Severity: "Severe",
+		Onset:    "2025-06-15T15:00:00-03:00",
+		Expires:  "2025-06-16T06:00",
+		Location: WeatherLocation{Latitude: -23.5505, Longitude: -46.6333},
+	}
+	got := msg.ToAlertLog()
+	if got.Event != "Tempestade" || got.Severity != "severe" || got.Source != DefaultSource {
+		t.Errorf("ToAlertLog() = %+v", got)
+	}
+	if got.Onset != "2025-06-15T18:00:00Z" || got.Expires != "2025-06-16T06:00:00Z" {
+		t.Errorf("period = %s .. %s, want UTC", got.Onset, got.Expires)
+	}
+	if got.Location == "" || got.Latitude != -23.5505 {
+		t.Errorf("location = %q (%v)", got.Location, got.Latitude)
+	}
+}
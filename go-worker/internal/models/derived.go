@@ -0,0 +1,141 @@
+package models
+
+import "math"
+
+// Grandezas derivadas de temperatura (°C), umidade relativa (%) e vento (km/h).
+// Cada função informa, no segundo retorno, se as entradas estão dentro da faixa
+// em que a fórmula é definida; fora dela o valor não deve ser usado.
+
+// Coeficientes de Magnus (Sonntag, 1990) sobre água líquida
+const (
+	magnusA = 17.62
+	magnusB = 243.12
+)
+
+func validHumidity(rh float64) bool {
+	return rh > 0 && rh <= 100
+}
+
+// DewPoint calcula o ponto de orvalho pela fórmula de Magnus, definida entre
+// -45 °C e 60 °C
+func DewPoint(tempC, rh float64) (float64, bool) {
+	if tempC < -45 || tempC > 60 || !validHumidity(rh) {
+		return 0, false
+	}
+	gamma := math.Log(rh/100) + magnusA*tempC/(magnusB+tempC)
+	return magnusB * gamma / (magnusA - gamma), true
+}
+
+// RelativeHumidity inverte a fórmula de Magnus: umidade relativa a partir da
+// temperatura e do ponto de orvalho (como informados no METAR). Ponto de
+// orvalho acima da temperatura não é aceito.
+func RelativeHumidity(tempC, dewPointC float64) (float64, bool) {
+	if tempC < -45 || tempC > 60 || dewPointC > tempC || dewPointC < -45 {
+		return 0, false
+	}
+	return 100 * math.Exp(magnusA*dewPointC/(magnusB+dewPointC)-magnusA*tempC/(magnusB+tempC)), true
+}
+
+// HeatIndex calcula o índice de calor pela regressão de Rothfusz com os ajustes
+// do NWS para umidade baixa e alta. É definido para temperaturas entre 80 °F
+// (26,7 °C) e 120 °F (48,9 °C) e índices de até 130 °F, limites das tabelas do NWS.
+func HeatIndex(tempC, rh float64) (float64, bool) {
+	t := celsiusToFahrenheit(tempC)
+	if t < 80 || t > 120 || !validHumidity(rh) {
+		return 0, false
+	}
+
+	// Fórmula simples de Steadman, usada pelo NWS quando o resultado fica abaixo de 80 °F
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + rh*0.094)
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+			0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+			0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+		switch {
+		case rh < 13 && t <= 112:
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		case rh > 85 && t <= 87:
+			hi += (rh - 85) / 10 * (87 - t) / 5
+		}
+	}
+	if hi > 130 {
+		return 0, false
+	}
+	return fahrenheitToCelsius(hi), true
+}
+
+// WindChill calcula a sensação térmica pelo vento (fórmula de 2001 do NWS e do
+// Environment Canada), definida para temperaturas até 10 °C e vento acima de
+// 4,8 km/h
+func WindChill(tempC, windKmh float64) (float64, bool) {
+	if tempC > 10 || tempC < -50 || windKmh <= 4.8 {
+		return 0, false
+	}
+	v := math.Pow(windKmh, 0.16)
+	return 13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v, true
+}
+
+// ApparentTemperature calcula a temperatura aparente de Steadman (1994), na
+// versão sem radiação usada pelo Bureau of Meteorology australiano. O vento é
+// convertido para m/s; a fórmula é definida entre -40 °C e 50 °C.
+func ApparentTemperature(tempC, rh, windKmh float64) (float64, bool) {
+	if tempC < -40 || tempC > 50 || !validHumidity(rh) || windKmh < 0 {
+		return 0, false
+	}
+	e := rh / 100 * 6.105 * math.Exp(17.27*tempC/(237.7+tempC))
+	return tempC + 0.33*e - 0.70*(windKmh/3.6) - 4.00, true
+}
+
+// AbsoluteHumidity calcula a umidade absoluta em g/m³. A aproximação tem erro
+// abaixo de 0,1% entre -30 °C e 35 °C.
+func AbsoluteHumidity(tempC, rh float64) (float64, bool) {
+	if tempC < -30 || tempC > 35 || !validHumidity(rh) {
+		return 0, false
+	}
+	// Coeficientes de Bolton (1980), usados na formulação original da aproximação
+	es := 6.112 * math.Exp(17.67*tempC/(tempC+243.5))
+	return es * rh * 2.1674 / (273.15 + tempC), true
+}
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func fahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// DerivedQuantities são as métricas de conforto calculadas a partir da mensagem.
+// Campos nil estão fora da faixa de validade da fórmula.
+type DerivedQuantities struct {
+	DewPoint            *float64 `json:"dewPoint,omitempty"`
+	HeatIndex           *float64 `json:"heatIndex,omitempty"`
+	WindChill           *float64 `json:"windChill,omitempty"`
+	ApparentTemperature *float64 `json:"apparentTemperature,omitempty"`
+	AbsoluteHumidity    *float64 `json:"absoluteHumidity,omitempty"`
+}
+
+// Derive calcula as grandezas derivadas das condições atuais. O ponto de
+// orvalho informado pelo provedor tem precedência sobre o calculado.
+func (c WeatherCurrent) Derive() DerivedQuantities {
+	var d DerivedQuantities
+	d.DewPoint = rounded(DewPoint(c.Temperature, c.Humidity))
+	if c.DewPoint != nil {
+		d.DewPoint = rounded(*c.DewPoint, true)
+	}
+	d.HeatIndex = rounded(HeatIndex(c.Temperature, c.Humidity))
+	d.WindChill = rounded(WindChill(c.Temperature, c.WindSpeed))
+	d.ApparentTemperature = rounded(ApparentTemperature(c.Temperature, c.Humidity, c.WindSpeed))
+	d.AbsoluteHumidity = rounded(AbsoluteHumidity(c.Temperature, c.Humidity))
+	return d
+}
+
+// rounded arredonda para uma casa decimal; valores fora da faixa viram nil
+func rounded(v float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	r := math.Round(v*10) / 10
+	return &r
+}
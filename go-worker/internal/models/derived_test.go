@@ -0,0 +1,155 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDewPoint(t *testing.T) {
+	// Tabela de referência do ponto de orvalho (Magnus-Tetens, Sonntag 1990)
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{25, 60, 16.7},
+		{30, 50, 18.4},
+		{10, 80, 6.7},
+		{0, 100, 0},
+		{-10, 70, -14.4},
+	}
+	for _, tt := range tests {
+		got, ok := DewPoint(tt.temp, tt.rh)
+		if !ok || math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("DewPoint(%v, %v) = %.2f, %v; want %v", tt.temp, tt.rh, got, ok, tt.want)
+		}
+	}
+	if _, ok := DewPoint(25, 0); ok {
+		t.Error("DewPoint(25, 0) ok = true, want out of range")
+	}
+}
+
+func TestRelativeHumidity(t *testing.T) {
+	// Inversa da tabela de TestDewPoint
+	tests := []struct {
+		temp, dew, want float64
+	}{
+		{25, 16.7, 60},
+		{30, 18.4, 50},
+		{10, 6.7, 80},
+		{14, 14, 100},
+		{-2, -4, 86.1},
+	}
+	for _, tt := range tests {
+		got, ok := RelativeHumidity(tt.temp, tt.dew)
+		if !ok || math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("RelativeHumidity(%v, %v) = %.2f, %v; want %v", tt.temp, tt.dew, got, ok, tt.want)
+		}
+	}
+	if _, ok := RelativeHumidity(20, 21); ok {
+		t.Error("RelativeHumidity(20, 21) ok = true, want dew point above temperature rejected")
+	}
+}
+
+func TestHeatIndex(t *testing.T) {
+	// Tabela de índice de calor do NWS, em °F
+	tests := []struct {
+		tempF, rh, wantF float64
+	}{
+		{80, 40, 80},
+		{90, 50, 95},
+		{100, 40, 109},
+		{86, 90, 105},
+		{96, 65, 121},
+		{104, 40, 119},
+		{84, 95, 100}, // ajuste para umidade alta
+	}
+	for _, tt := range tests {
+		got, ok := HeatIndex(fahrenheitToCelsius(tt.tempF), tt.rh)
+		if !ok {
+			t.Errorf("HeatIndex(%v°F, %v) out of range", tt.tempF, tt.rh)
+			continue
+		}
+		if gotF := celsiusToFahrenheit(got); math.Abs(gotF-tt.wantF) > 1.5 {
+			t.Errorf("HeatIndex(%v°F, %v) = %.1f°F, want %v°F", tt.tempF, tt.rh, gotF, tt.wantF)
+		}
+	}
+	if _, ok := HeatIndex(25, 60); ok {
+		t.Error("HeatIndex(25 °C) ok = true, want out of range below 80 °F")
+	}
+}
+
+func TestWindChill(t *testing.T) {
+	// Tabela de sensação térmica do Environment Canada (°C, km/h)
+	tests := []struct {
+		temp, wind, want float64
+	}{
+		{0, 10, -3},
+		{-10, 20, -18},
+		{-20, 30, -33},
+		{5, 40, -1},
+		{-40, 60, -64},
+	}
+	for _, tt := range tests {
+		got, ok := WindChill(tt.temp, tt.wind)
+		if !ok || math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("WindChill(%v, %v) = %.2f, %v; want %v", tt.temp, tt.wind, got, ok, tt.want)
+		}
+	}
+	for _, in := range [][2]float64{{15, 20}, {0, 3}} {
+		if _, ok := WindChill(in[0], in[1]); ok {
+			t.Errorf("WindChill(%v, %v) ok = true, want out of range", in[0], in[1])
+		}
+	}
+}
+
+func TestApparentTemperature(t *testing.T) {
+	// Valores do Bureau of Meteorology (Steadman 1994, sem radiação)
+	tests := []struct {
+		temp, rh, windKmh, want float64
+	}{
+		{30, 50, 0, 33.0},
+		{20, 50, 18, 16.3},
+		{10, 80, 36, 2.3},
+	}
+	for _, tt := range tests {
+		got, ok := ApparentTemperature(tt.temp, tt.rh, tt.windKmh)
+		if !ok || math.Abs(got-tt.want) > 0.2 {
+			t.Errorf("ApparentTemperature(%v, %v, %v) = %.2f, %v; want %v", tt.temp, tt.rh, tt.windKmh, got, ok, tt.want)
+		}
+	}
+}
+
+func TestAbsoluteHumidity(t *testing.T) {
+	// Densidade de vapor na saturação (g/m³), tabelas psicrométricas
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{20, 100, 17.3},
+		{30, 100, 30.4},
+		{0, 100, 4.85},
+		{25, 50, 11.5},
+	}
+	for _, tt := range tests {
+		got, ok := AbsoluteHumidity(tt.temp, tt.rh)
+		if !ok || math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("AbsoluteHumidity(%v, %v) = %.2f, %v; want %v", tt.temp, tt.rh, got, ok, tt.want)
+		}
+	}
+}
+
+func TestWeatherCurrent_Derive(t *testing.T) {
+	hot := WeatherCurrent{Temperature: 32, Humidity: 60, WindSpeed: 10}.Derive()
+	if hot.HeatIndex == nil || hot.WindChill != nil || hot.DewPoint == nil {
+		t.Errorf("Derive(hot) = %+v, want heat index and no wind chill", hot)
+	}
+	cold := WeatherCurrent{Temperature: -5, Humidity: 60, WindSpeed: 25}.Derive()
+	if cold.HeatIndex != nil || cold.WindChill == nil {
+		t.Errorf("Derive(cold) = %+v, want wind chill and no heat index", cold)
+	}
+	if *hot.DewPoint != math.Round(*hot.DewPoint*10)/10 {
+		t.Errorf("DewPoint = %v, want one decimal", *hot.DewPoint)
+	}
+	measured := WeatherCurrent{Temperature: 32, Humidity: 60, DewPoint: Float64(22.84)}.Derive()
+	if *measured.DewPoint != 22.8 {
+		t.Errorf("DewPoint = %v, want the provider value 22.8", *measured.DewPoint)
+	}
+}
@@ -1,11 +1,95 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 var (
-	ErrInvalidLocation    = errors.New("invalid location coordinates")
-	ErrInvalidTemperature = errors.New("temperature must be between -100 and 100")
-	ErrInvalidHumidity    = errors.New("humidity must be between 0 and 100")
-	ErrInvalidTimestamp   = errors.New("timestamp is required")
+	ErrInvalidLocation      = errors.New("invalid location coordinates")
+	ErrInvalidTemperature   = errors.New("temperature must be between -100 and 100")
+	ErrInvalidHumidity      = errors.New("humidity must be between 0 and 100")
+	ErrInvalidTimestamp     = errors.New("timestamp is required")
+	ErrFutureTimestamp      = errors.New("timestamp is in the future")
+	ErrStaleTimestamp       = errors.New("timestamp is too old")
+	ErrInvalidWindSpeed     = errors.New("wind speed must not be negative")
+	ErrInvalidWindDirection = errors.New("wind direction must be between 0 and 360")
+	ErrInvalidPrecipitation = errors.New("precipitation must not be negative")
+	ErrInvalidWeatherCode   = errors.New("weather code must be a WMO 4677 code (0 to 99)")
+	ErrInvalidPressure      = errors.New("pressure must be between 300 and 1100 hPa")
+	ErrInvalidVisibility    = errors.New("visibility must not be negative")
+	ErrInvalidUVIndex       = errors.New("uv index must not be negative")
+	ErrInvalidCloudCover    = errors.New("cloud cover must be between 0 and 100")
+
+	ErrNoPollutants             = errors.New("at least one pollutant is required")
+	ErrInvalidConcentration     = errors.New("concentration must not be negative")
+	ErrImplausibleConcentration = errors.New("concentration is above the plausible maximum")
+
+	ErrNoAlertEvent       = errors.New("alert event is required")
+	ErrInvalidSeverity    = errors.New("severity must be minor, moderate, severe or extreme")
+	ErrInvalidAlertPeriod = errors.New("expires must be after onset")
 )
 
+// Violation é uma regra de validação não atendida
+type Violation struct {
+	Field   string      `json:"field"`
+	Value   interface{} `json:"value"`
+	Rule    string      `json:"rule"`
+	Message string      `json:"message"`
+	Err     error       `json:"-"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s=%v (%s): %s", v.Field, v.Value, v.Rule, v.Message)
+}
+
+// ValidationError reúne todas as violações de uma mensagem. errors.Is
+// reconhece o sentinel de cada violação (ErrInvalidLocation, ...).
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return fmt.Sprintf("%d violações: %s", len(parts), strings.Join(parts, "; "))
+}
+
+// Unwrap expõe os sentinels das violações para errors.Is
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		if v.Err != nil {
+			errs = append(errs, v.Err)
+		}
+	}
+	return errs
+}
+
+// violation converte uma regra reprovada; campos opcionais são desreferenciados
+// para que o valor apareça no log e no JSON
+func (r RuleResult) violation() Violation {
+	value := r.Value
+	switch v := value.(type) {
+	case *float64:
+		if v == nil {
+			value = nil
+		} else {
+			value = *v
+		}
+	case time.Time:
+		value = v.Format(time.RFC3339)
+	}
+	v := Violation{Field: r.Field, Value: value, Rule: r.Rule, Err: r.Err}
+	if r.Err != nil {
+		v.Message = r.Err.Error()
+	}
+	return v
+}
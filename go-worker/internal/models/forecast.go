@@ -0,0 +1,246 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Tipos de registro gerados a partir de uma mensagem
+const (
+	KindObservation = "observation"
+	KindForecast    = "forecast"
+)
+
+// Resoluções das séries de previsão
+const (
+	PeriodHourly = "hourly"
+	PeriodDaily  = "daily"
+)
+
+// ErrInvalidForecast indica séries de previsão malformadas (arrays de
+// tamanhos diferentes, variáveis desconhecidas, horários inválidos)
+var ErrInvalidForecast = errors.New("invalid forecast series")
+
+// ForecastSeries são previsões em arrays paralelos, como o hourly e o daily
+// da Open-Meteo: time e um array por variável, com os nomes de current
+// (temperature, humidity, ...) e null onde falta o valor. As séries diárias
+// aceitam também temperature_max e temperature_min.
+type ForecastSeries struct {
+	Time   []string
+	Values map[string][]*float64
+}
+
+// UnmarshalJSON lê time e as variáveis do objeto
+func (s *ForecastSeries) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	s.Time, s.Values = nil, make(map[string][]*float64, len(raw))
+	for name, value := range raw {
+		if name == "time" {
+			if err := json.Unmarshal(value, &s.Time); err != nil {
+				return fmt.Errorf("time: %w", err)
+			}
+			continue
+		}
+		var values []*float64
+		if err := json.Unmarshal(value, &values); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		s.Values[name] = values
+	}
+	return nil
+}
+
+// MarshalJSON grava a série no mesmo formato lido
+func (s ForecastSeries) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(s.Values)+1)
+	out["time"] = s.Time
+	for name, values := range s.Values {
+		out[name] = values
+	}
+	return json.Marshal(out)
+}
+
+// HasForecast indica se a mensagem traz séries de previsão
+func (w *WeatherMessage) HasForecast() bool {
+	return w.Hourly != nil || w.Daily != nil
+}
+
+// hasCurrent indica se a mensagem traz uma observação; mensagens só de
+// previsão omitem o bloco current
+func (w *WeatherMessage) hasCurrent() bool {
+	return w.Current != (WeatherCurrent{})
+}
+
+// Expand separa a mensagem em um registro por horário: a observação de
+// current, quando presente, e uma previsão por posição das séries. Cada
+// previsão é uma mensagem comum, com o horário previsto em timestamp, Kind
+// igual a KindForecast, o período da série e a emissão (issued_at ou, na
+// falta dele, timestamp). Mensagens sem séries retornam a si mesmas.
+func (w *WeatherMessage) Expand() ([]WeatherMessage, error) {
+	if !w.HasForecast() {
+		return []WeatherMessage{*w}, nil
+	}
+
+	var issuedAt string
+	if issued := w.IssuedAt; issued != "" || w.Timestamp != "" {
+		if issued == "" {
+			issued = w.Timestamp
+		}
+		t, err := ParseTimestamp(issued)
+		if err != nil {
+			return nil, fmt.Errorf("%w: emissão: %v", ErrInvalidForecast, err)
+		}
+		issuedAt = t.Format(time.RFC3339)
+	}
+
+	var out []WeatherMessage
+	if w.hasCurrent() {
+		observation := *w
+		observation.Hourly, observation.Daily = nil, nil
+		observation.Kind = KindObservation
+		out = append(out, observation)
+	}
+	for _, series := range []struct {
+		period string
+		s      *ForecastSeries
+	}{{PeriodHourly, w.Hourly}, {PeriodDaily, w.Daily}} {
+		if series.s == nil {
+			continue
+		}
+		entries, err := w.expandSeries(series.period, series.s, issuedAt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entries...)
+	}
+	return out, nil
+}
+
+// expandSeries confere que todas as variáveis têm um valor por horário e
+// monta uma mensagem por posição
+func (w *WeatherMessage) expandSeries(period string, s *ForecastSeries, issuedAt string) ([]WeatherMessage, error) {
+	names := make([]string, 0, len(s.Values))
+	for name, values := range s.Values {
+		if len(values) != len(s.Time) {
+			return nil, fmt.Errorf("%w: %s.%s tem %d valores, %s.time tem %d",
+				ErrInvalidForecast, period, name, len(values), period, len(s.Time))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	loc, err := loadLocation(w.Location.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidForecast, err)
+	}
+
+	out := make([]WeatherMessage, 0, len(s.Time))
+	for i, value := range s.Time {
+		valid, err := parseForecastTime(value, period, loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.time[%d]: %v", ErrInvalidForecast, period, i, err)
+		}
+		current, meanTemperature, err := forecastCurrent(s, names, i, period)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s[%d]: %v", ErrInvalidForecast, period, i, err)
+		}
+		units := w.Units
+		if meanTemperature {
+			if units, err = meanTemperatureUnits(w.Units); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidForecast, err)
+			}
+		}
+		out = append(out, WeatherMessage{
+			Timestamp: valid.Format(time.RFC3339),
+			Location:  w.Location,
+			Current:   current,
+			Source:    w.Source,
+			Units:     units,
+			Kind:      KindForecast,
+			IssuedAt:  issuedAt,
+			Period:    period,
+			Repairs:   w.Repairs,
+		})
+	}
+	return out, nil
+}
+
+// parseForecastTime aceita os horários de current e, nas séries diárias, a
+// data (AAAA-MM-DD, início do dia no fuso da localização)
+func parseForecastTime(value, period string, loc *time.Location) (time.Time, error) {
+	if period == PeriodDaily {
+		if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return parseTime(value, loc)
+}
+
+// forecastCurrent monta as condições de uma posição da série. Temperatura e
+// umidade são obrigatórias, como no backend; nas séries diárias sem
+// temperature, vale a média de temperature_max e temperature_min, indicada
+// no segundo retorno.
+func forecastCurrent(s *ForecastSeries, names []string, i int, period string) (WeatherCurrent, bool, error) {
+	fields := make(map[string]float64, len(names))
+	for _, name := range names {
+		if v := s.Values[name][i]; v != nil {
+			fields[name] = *v
+		}
+	}
+	mean := false
+	if _, ok := fields["temperature"]; !ok && period == PeriodDaily {
+		max, hasMax := fields["temperature_max"]
+		min, hasMin := fields["temperature_min"]
+		if hasMax && hasMin {
+			fields["temperature"] = (max + min) / 2
+			mean = true
+		}
+	}
+	for _, name := range []string{"temperature", "humidity"} {
+		if _, ok := fields[name]; !ok {
+			return WeatherCurrent{}, false, fmt.Errorf("%s ausente", name)
+		}
+	}
+
+	// Os nomes das variáveis são os do JSON de current
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return WeatherCurrent{}, false, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var current WeatherCurrent
+	if err := dec.Decode(&current); err != nil {
+		return WeatherCurrent{}, false, err
+	}
+	return current, mean, nil
+}
+
+// meanTemperatureUnits declara para a média calculada a unidade dos extremos
+// que a originaram
+func meanTemperatureUnits(units map[string]string) (map[string]string, error) {
+	unit := units["temperature_max"]
+	if units["temperature_min"] != unit {
+		return nil, fmt.Errorf("temperature_max e temperature_min em unidades diferentes (%q, %q)", unit, units["temperature_min"])
+	}
+	if units["temperature"] == unit {
+		return units, nil
+	}
+	out := make(map[string]string, len(units)+1)
+	for k, v := range units {
+		out[k] = v
+	}
+	if unit == "" {
+		delete(out, "temperature")
+	} else {
+		out["temperature"] = unit
+	}
+	return out, nil
+}
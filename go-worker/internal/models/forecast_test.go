@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWeatherMessage_Expand(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantKinds []string
+		wantTimes []string
+		wantErr   error
+	}{
+		{
+			name:      "observation only",
+			message:   `{"timestamp":"2025-06-15T14:30:00Z","current":{"temperature":25,"humidity":60}}`,
+			wantKinds: []string{""},
+			wantTimes: []string{"2025-06-15T14:30:00Z"},
+		},
+		{
+			name: "observation and hourly forecast",
+			message: `{"timestamp":"2025-06-15T14:30:00Z","location":{"timezone":"America/Sao_Paulo"},"current":{"temperature":25,"humidity":60},
+				"hourly":{"time":["2025-06-15T12:00","2025-06-15T13:00"],"temperature":[24,25],"humidity":[60,58]}}`,
+			wantKinds: []string{KindObservation, KindForecast, KindForecast},
+			wantTimes: []string{"2025-06-15T14:30:00Z", "2025-06-15T15:00:00Z", "2025-06-15T16:00:00Z"},
+		},
+		{
+			name: "daily forecast without current",
+			message: `{"issued_at":"2025-06-15T12:00:00Z","location":{"timezone":"America/Sao_Paulo"},
+				"daily":{"time":["2025-06-16"],"temperature_max":[26],"temperature_min":[16],"humidity":[70]}}`,
+			wantKinds: []string{KindForecast},
+			wantTimes: []string{"2025-06-16T03:00:00Z"},
+		},
+		{
+			name:    "arrays of different lengths",
+			message: `{"hourly":{"time":["2025-06-15T12:00","2025-06-15T13:00"],"temperature":[24,25],"humidity":[60]}}`,
+			wantErr: ErrInvalidForecast,
+		},
+		{
+			name:    "missing humidity",
+			message: `{"hourly":{"time":["2025-06-15T12:00"],"temperature":[24],"humidity":[null]}}`,
+			wantErr: ErrInvalidForecast,
+		},
+		{
+			name:    "unknown variable",
+			message: `{"hourly":{"time":["2025-06-15T12:00"],"temperature":[24],"humidity":[60],"sunshine":[1]}}`,
+			wantErr: ErrInvalidForecast,
+		},
+		{
+			name:    "invalid time",
+			message: `{"hourly":{"time":["amanhã"],"temperature":[24],"humidity":[60]}}`,
+			wantErr: ErrInvalidForecast,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg WeatherMessage
+			if err := json.Unmarshal([]byte(tt.message), &msg); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			entries, err := msg.Expand()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expand() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(entries) != len(tt.wantKinds) {
+				t.Fatalf("Expand() = %d entries, want %d", len(entries), len(tt.wantKinds))
+			}
+			for i, e := range entries {
+				observed, _ := e.ObservedAt()
+				if e.Kind != tt.wantKinds[i] || observed.Format(time.RFC3339) != tt.wantTimes[i] {
+					t.Errorf("entry %d = %s at %s, want %s at %s", i, e.Kind, observed.Format(time.RFC3339), tt.wantKinds[i], tt.wantTimes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWeatherMessage_Expand_Forecast(t *testing.T) {
+	var msg WeatherMessage
+	body := `{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"units":{"temperature":"K","temperature_max":"°F","temperature_min":"°F"},
+		"daily":{"time":["2025-06-20"],"temperature_max":[86],"temperature_min":[59],"humidity":[55],"weather_code":[61]}}`
+	if err := json.Unmarshal([]byte(body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := msg.Expand()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expand() = %v, %v", entries, err)
+	}
+
+	forecast := entries[0]
+	if _, err := forecast.NormalizeUnits(); err != nil {
+		t.Fatalf("NormalizeUnits() error = %v", err)
+	}
+	// Previsões estão no futuro e não passam pela política de horários
+	SetTimestampPolicy(TimestampPolicy{MaxAge: time.Hour, MaxFuture: time.Minute, Now: func() time.Time {
+		return time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC)
+	}})
+	t.Cleanup(func() { SetTimestampPolicy(DefaultTimestampPolicy) })
+	if err := forecast.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	log := forecast.ToWeatherLog()
+	if log.Kind != KindForecast || log.Period != PeriodDaily || log.IssuedAt != "2025-06-15T14:30:00Z" || log.Timestamp != "2025-06-20T00:00:00Z" {
+		t.Errorf("WeatherLog = %s %s issued %s at %s", log.Kind, log.Period, log.IssuedAt, log.Timestamp)
+	}
+	// A média usa a unidade dos extremos, não a declarada para temperature
+	if *log.TemperatureMax != 30 || *log.TemperatureMin != 15 || log.Temperature != 22.5 {
+		t.Errorf("temperatures = max %v, min %v, mean %v; want 30, 15, 22.5", *log.TemperatureMax, *log.TemperatureMin, log.Temperature)
+	}
+}
+
+func TestWeatherMessage_Expand_DailyCondition(t *testing.T) {
+	var msg WeatherMessage
+	body := `{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63,"timezone":"America/Sao_Paulo"},
+		"daily":{"time":["2025-06-15"],"temperature_max":[26],"temperature_min":[14],"humidity":[60],"weather_code":[0]}}`
+	if err := json.Unmarshal([]byte(body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := msg.Expand()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expand() = %v, %v", entries, err)
+	}
+
+	// O registro diário fica na meia-noite local, mas descreve o dia
+	log := entries[0].ToLocalizedWeatherLog("en")
+	if log.Timestamp != "2025-06-15T03:00:00Z" {
+		t.Errorf("Timestamp = %q, want local midnight", log.Timestamp)
+	}
+	if log.Icon != "clear-day" || log.Description != "Clear sky" {
+		t.Errorf("condition = %q (%s), want Clear sky (clear-day)", log.Description, log.Icon)
+	}
+}
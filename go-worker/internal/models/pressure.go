@@ -0,0 +1,77 @@
+package models
+
+import (
+	"math"
+
+	"go-worker/internal/geo"
+)
+
+// ElevationFromProvider indica que a pressão ao nível do mar veio pronta do
+// provedor, reduzida com a altitude que ele considera
+const ElevationFromProvider = "provider"
+
+// SeaLevelPressure reduz a pressão da estação (hPa) ao nível médio do mar pela
+// fórmula barométrica padrão, usando a temperatura do ar (°C) e a altitude (m).
+// É definida para altitudes entre -500 m e 4000 m e pressões entre 300 e 1100 hPa.
+func SeaLevelPressure(stationHPa, tempC, elevationM float64) (float64, bool) {
+	if elevationM < -500 || elevationM > 4000 || stationHPa < 300 || stationHPa > 1100 || tempC < -80 || tempC > 60 {
+		return 0, false
+	}
+	gradient := 0.0065 * elevationM
+	return stationHPa * math.Pow(1-gradient/(tempC+gradient+273.15), -5.257), true
+}
+
+// ResolveElevation escolhe a altitude da estação, nesta ordem: estação
+// configurada (STATION_ELEVATIONS_FILE), altitude informada na mensagem e grade
+// grosseira embutida
+func (w *WeatherMessage) ResolveElevation() (float64, string, bool) {
+	lat, lon := w.Location.Latitude, w.Location.Longitude
+	resolver := geo.DefaultElevations()
+
+	if elevation, ok := resolver.Station(lat, lon); ok {
+		return elevation, geo.ElevationFromStation, true
+	}
+	if w.Location.Elevation != nil {
+		return *w.Location.Elevation, geo.ElevationFromMessage, true
+	}
+	if elevation, ok := resolver.GridElevation(lat, lon); ok {
+		return elevation, geo.ElevationFromGrid, true
+	}
+	return 0, "", false
+}
+
+// PressureReading reúne a pressão da estação, a pressão ao nível do mar e a
+// altitude usada na redução
+type PressureReading struct {
+	Station         *float64 `json:"station,omitempty"`
+	SeaLevel        *float64 `json:"seaLevel,omitempty"`
+	Elevation       *float64 `json:"elevation,omitempty"`
+	ElevationSource string   `json:"elevationSource,omitempty"`
+}
+
+// Pressure reduz a pressão de superfície ao nível do mar com a altitude da
+// estação configurada ou da mensagem. Sem nenhuma delas vale a pressão ao nível
+// do mar medida pelo provedor e, só na falta dela, a redução com a altitude da
+// grade. Sem nenhuma das opções a pressão ao nível do mar fica ausente.
+func (w *WeatherMessage) Pressure() PressureReading {
+	reading := PressureReading{Station: w.Current.SurfacePressure}
+
+	elevation, source, known := w.ResolveElevation()
+	if known && source == geo.ElevationFromGrid && w.Current.PressureMSL != nil {
+		known = false
+	}
+	if station := w.Current.SurfacePressure; station != nil && known {
+		if msl, ok := SeaLevelPressure(*station, w.Current.Temperature, elevation); ok {
+			reading.SeaLevel = Float64(math.Round(msl*10) / 10)
+			reading.Elevation = Float64(elevation)
+			reading.ElevationSource = source
+			return reading
+		}
+	}
+
+	if w.Current.PressureMSL != nil {
+		reading.SeaLevel = w.Current.PressureMSL
+		reading.ElevationSource = ElevationFromProvider
+	}
+	return reading
+}
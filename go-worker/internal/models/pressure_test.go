@@ -0,0 +1,75 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"go-worker/internal/geo"
+)
+
+func TestSeaLevelPressure(t *testing.T) {
+	// Atmosfera padrão ISA: pressão e temperatura na altitude reduzem a 1013,25 hPa
+	tests := []struct {
+		station, temp, elevation, want float64
+	}{
+		{1013.25, 15, 0, 1013.25},
+		{954.61, 11.75, 500, 1013.25},
+		{898.76, 8.5, 1000, 1013.25},
+		{795.01, 2, 2000, 1013.25},
+		{925.1, 25, 760, 1008.5},
+	}
+	for _, tt := range tests {
+		got, ok := SeaLevelPressure(tt.station, tt.temp, tt.elevation)
+		if !ok || math.Abs(got-tt.want) > 0.3 {
+			t.Errorf("SeaLevelPressure(%v, %v, %v) = %.2f, %v; want %v", tt.station, tt.temp, tt.elevation, got, ok, tt.want)
+		}
+	}
+	if _, ok := SeaLevelPressure(600, 0, 5000); ok {
+		t.Error("SeaLevelPressure(5000 m) ok = true, want out of range")
+	}
+}
+
+func TestWeatherMessage_Pressure(t *testing.T) {
+	geo.SetDefaultElevations(&geo.ElevationResolver{
+		Stations: []geo.StationElevation{{Name: "Mirante de Santana", Latitude: -23.4962, Longitude: -46.6200, Elevation: 792}},
+		MatchKm:  geo.DefaultStationMatchKm,
+		Grid:     geo.NewElevationGrid([]geo.GridCell{{Latitude: -22.75, Longitude: -47.25, Elevation: 640}}),
+	})
+	t.Cleanup(func() { geo.SetDefaultElevations(nil) })
+
+	tests := []struct {
+		name       string
+		lat, lon   float64
+		elevation  *float64
+		surface    *float64
+		msl        *float64
+		wantSource string
+		wantMSL    bool
+	}{
+		{"configured station wins over message", -23.4963, -46.6201, Float64(700), Float64(922), nil, geo.ElevationFromStation, true},
+		{"message elevation", -23.5505, -46.6333, Float64(760), Float64(925.1), nil, geo.ElevationFromMessage, true},
+		{"provider MSL wins over grid", -22.9056, -47.0608, nil, Float64(933), Float64(1011), ElevationFromProvider, true},
+		{"grid without provider MSL", -22.9056, -47.0608, nil, Float64(933), nil, geo.ElevationFromGrid, true},
+		{"provider MSL without elevation", -25.0, -40.0, nil, Float64(1012), Float64(1013), ElevationFromProvider, true},
+		{"surface only, no elevation", -25.0, -40.0, nil, Float64(1012), nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := WeatherMessage{
+				Location: WeatherLocation{Latitude: tt.lat, Longitude: tt.lon, Elevation: tt.elevation},
+				Current:  WeatherCurrent{Temperature: 22, SurfacePressure: tt.surface, PressureMSL: tt.msl},
+			}
+			reading := msg.Pressure()
+			if reading.ElevationSource != tt.wantSource {
+				t.Errorf("ElevationSource = %q, want %q", reading.ElevationSource, tt.wantSource)
+			}
+			if (reading.SeaLevel != nil) != tt.wantMSL {
+				t.Errorf("SeaLevel = %v, want present = %v", reading.SeaLevel, tt.wantMSL)
+			}
+			if reading.Station != tt.surface {
+				t.Errorf("Station = %v, want %v", reading.Station, tt.surface)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	_ "time/tzdata" // a imagem alpine não traz o banco de fusos horários
+)
+
+// Formatos sem fuso aceitos, do mais preciso ao menos. O coletor Python publica
+// datetime.utcnow().isoformat(); a Open-Meteo informa current.time com minutos.
+var naiveLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+}
+
+// TimestampPolicy limita o intervalo aceito para o instante da observação
+type TimestampPolicy struct {
+	// MaxAge é a idade máxima da observação; zero desabilita a verificação
+	MaxAge time.Duration
+	// MaxFuture tolera relógios adiantados no coletor
+	MaxFuture time.Duration
+	// Now é o relógio usado nas verificações (time.Now quando nil)
+	Now func() time.Time
+}
+
+// DefaultTimestampPolicy rejeita apenas observações no futuro
+var DefaultTimestampPolicy = TimestampPolicy{MaxFuture: 5 * time.Minute}
+
+var timestampPolicy atomic.Pointer[TimestampPolicy]
+
+// SetTimestampPolicy substitui a política usada por Validate
+func SetTimestampPolicy(p TimestampPolicy) {
+	timestampPolicy.Store(&p)
+}
+
+func currentTimestampPolicy() TimestampPolicy {
+	if p := timestampPolicy.Load(); p != nil {
+		return *p
+	}
+	return DefaultTimestampPolicy
+}
+
+func (p TimestampPolicy) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+var locations sync.Map // nome do fuso -> *time.Location
+
+// loadLocation carrega um fuso IANA; vazio é UTC
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("fuso horário desconhecido %q: %w", name, err)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// ParseTimestamp interpreta o timestamp de coleta: RFC 3339 ou, sem fuso, UTC
+func ParseTimestamp(value string) (time.Time, error) {
+	return parseTime(value, time.UTC)
+}
+
+// ParseLocalTime interpreta um horário RFC 3339 ou, sem fuso, no fuso IANA
+// informado (vazio é UTC), como o current.time da Open-Meteo
+func ParseLocalTime(value, timezone string) (time.Time, error) {
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseTime(value, loc)
+}
+
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range naiveLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("horário inválido %q: esperado RFC 3339 ou AAAA-MM-DDThh:mm[:ss]", value)
+}
+
+// FormatLocalTime formata t como o current.time da Open-Meteo: hora local do
+// fuso IANA informado, sem offset. Fuso desconhecido usa UTC.
+func FormatLocalTime(t time.Time, timezone string) string {
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(naiveLayouts[1])
+}
+
+// ObservedAt retorna o instante da observação em UTC: current.time, no fuso de
+// location.timezone, ou, na falta dele, o timestamp de coleta
+func (w *WeatherMessage) ObservedAt() (time.Time, error) {
+	if w.Current.Time != "" {
+		return ParseLocalTime(w.Current.Time, w.Location.Timezone)
+	}
+	return ParseTimestamp(w.Timestamp)
+}
+
+// timestampRules verifica o formato dos horários e se a observação está dentro
+// da política em vigor. Horários vazios passam aqui; timestamp é exigido pela
+// regra required. Previsões estão no futuro por definição e só têm o formato
+// verificado.
+func (w *WeatherMessage) timestampRules() []RuleResult {
+	policy := currentTimestampPolicy()
+	_, tsErr := ParseTimestamp(w.Timestamp)
+	_, timeErr := ParseLocalTime(w.Current.Time, w.Location.Timezone)
+	observed, obsErr := w.ObservedAt()
+	now := policy.now()
+
+	rules := []RuleResult{
+		{Rule: "format[RFC3339|naive UTC]", Field: "timestamp", Value: w.Timestamp,
+			Passed: w.Timestamp == "" || tsErr == nil, Err: ErrInvalidTimestamp},
+		{Rule: "format[RFC3339|local]", Field: "current.time", Value: w.Current.Time,
+			Passed: w.Current.Time == "" || timeErr == nil, Err: ErrInvalidTimestamp},
+	}
+	if w.Kind == KindForecast {
+		return rules
+	}
+	rules = append(rules, RuleResult{Rule: fmt.Sprintf("notFuture[%v]", policy.MaxFuture), Field: "observedAt", Value: observed,
+		Passed: obsErr != nil || !observed.After(now.Add(policy.MaxFuture)), Err: ErrFutureTimestamp})
+	if policy.MaxAge > 0 {
+		rules = append(rules, RuleResult{Rule: fmt.Sprintf("maxAge[%v]", policy.MaxAge), Field: "observedAt", Value: observed,
+			Passed: obsErr != nil || now.Sub(observed) <= policy.MaxAge, Err: ErrStaleTimestamp})
+	}
+	return rules
+}
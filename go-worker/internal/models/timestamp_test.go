@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseLocalTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		timezone string
+		want     string
+		wantErr  bool
+	}{
+		{"RFC 3339 with offset", "2025-06-15T11:30:00-03:00", "", "2025-06-15T14:30:00Z", false},
+		{"RFC 3339 ignores timezone", "2025-06-15T14:30:00Z", "Asia/Tokyo", "2025-06-15T14:30:00Z", false},
+		{"Open-Meteo local time", "2025-06-15T11:30", "America/Sao_Paulo", "2025-06-15T14:30:00Z", false},
+		{"naive without timezone is UTC", "2025-06-15T11:30", "", "2025-06-15T11:30:00Z", false},
+		{"Python isoformat", "2025-06-15T14:30:00.123456", "GMT", "2025-06-15T14:30:00.123456Z", false},
+		{"DST in New York", "2025-07-01T08:00", "America/New_York", "2025-07-01T12:00:00Z", false},
+		{"unknown timezone", "2025-06-15T11:30", "America/Atlantis", "", true},
+		{"date only", "2025-06-15", "", "", true},
+		{"garbage", "ontem", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocalTime(tt.value, tt.timezone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLocalTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Format(time.RFC3339Nano) != tt.want {
+				t.Errorf("ParseLocalTime() = %v, want %v", got.Format(time.RFC3339Nano), tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherMessage_ObservedAt(t *testing.T) {
+	msg := WeatherMessage{
+		Timestamp: "2025-06-15T14:31:02.5",
+		Location:  WeatherLocation{Timezone: "America/Sao_Paulo"},
+		Current:   WeatherCurrent{Time: "2025-06-15T11:30"},
+	}
+	if got, _ := msg.ObservedAt(); !got.Equal(time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("ObservedAt() = %v, want current.time in UTC", got)
+	}
+	if log := msg.ToWeatherLog(); log.Timestamp != "2025-06-15T14:30:00Z" {
+		t.Errorf("WeatherLog.Timestamp = %q, want 2025-06-15T14:30:00Z", log.Timestamp)
+	}
+
+	msg.Current.Time = ""
+	if got, _ := msg.ObservedAt(); !got.Equal(time.Date(2025, 6, 15, 14, 31, 2, 5e8, time.UTC)) {
+		t.Errorf("ObservedAt() = %v, want collection timestamp", got)
+	}
+}
+
+func TestWeatherMessage_Validate_Timestamps(t *testing.T) {
+	now := time.Date(2025, 6, 15, 15, 0, 0, 0, time.UTC)
+	SetTimestampPolicy(TimestampPolicy{MaxAge: 6 * time.Hour, MaxFuture: 5 * time.Minute, Now: func() time.Time { return now }})
+	t.Cleanup(func() { SetTimestampPolicy(DefaultTimestampPolicy) })
+
+	tests := []struct {
+		name      string
+		timestamp string
+		time      string
+		wantErr   error
+	}{
+		{"recent observation", "2025-06-15T14:30:00", "2025-06-15T11:30", nil},
+		{"clock skew within tolerance", "2025-06-15T15:03:00Z", "", nil},
+		{"future observation", "2025-06-15T14:30:00", "2025-06-15T12:30", ErrFutureTimestamp},
+		{"stale observation", "2025-06-15T14:30:00", "2025-06-15T05:00", ErrStaleTimestamp},
+		{"malformed timestamp", "15/06/2025 14:30", "", ErrInvalidTimestamp},
+		{"malformed current.time", "2025-06-15T14:30:00", "11h30", ErrInvalidTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := WeatherMessage{
+				Timestamp: tt.timestamp,
+				Location:  WeatherLocation{Latitude: -23.5505, Longitude: -46.6333, Timezone: "America/Sao_Paulo"},
+				Current:   WeatherCurrent{Temperature: 25, Humidity: 60, Time: tt.time},
+			}
+			if err := msg.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatLocalTime(t *testing.T) {
+	observed := time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC)
+	local := FormatLocalTime(observed, "America/Sao_Paulo")
+	if local != "2025-06-15T11:30" {
+		t.Fatalf("FormatLocalTime() = %q, want 2025-06-15T11:30", local)
+	}
+	if back, err := ParseLocalTime(local, "America/Sao_Paulo"); err != nil || !back.Equal(observed) {
+		t.Errorf("ParseLocalTime(FormatLocalTime()) = %v, %v; want %v", back, err, observed)
+	}
+}
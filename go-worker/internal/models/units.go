@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"go-worker/internal/units"
+)
+
+// unitField liga uma chave do bloco units ao campo da mensagem que ela descreve
+type unitField struct {
+	key       string
+	dimension string
+	value     func(w *WeatherMessage) *float64
+}
+
+// unitFields são os campos com unidade, na ordem em que são normalizados. As
+// chaves seguem os nomes de current (e location.elevation); outras chaves do
+// bloco, como humidity ou weather_code, são adimensionais e ignoradas.
+var unitFields = []unitField{
+	{"temperature", units.DimTemperature, func(w *WeatherMessage) *float64 { return &w.Current.Temperature }},
+	{"dew_point", units.DimTemperature, func(w *WeatherMessage) *float64 { return w.Current.DewPoint }},
+	{"temperature_max", units.DimTemperature, func(w *WeatherMessage) *float64 { return w.Current.TemperatureMax }},
+	{"temperature_min", units.DimTemperature, func(w *WeatherMessage) *float64 { return w.Current.TemperatureMin }},
+	{"wind_speed", units.DimSpeed, func(w *WeatherMessage) *float64 { return &w.Current.WindSpeed }},
+	{"wind_gusts", units.DimSpeed, func(w *WeatherMessage) *float64 { return w.Current.WindGusts }},
+	{"precipitation", units.DimPrecipitation, func(w *WeatherMessage) *float64 { return w.Current.Precipitation }},
+	{"surface_pressure", units.DimPressure, func(w *WeatherMessage) *float64 { return w.Current.SurfacePressure }},
+	{"pressure_msl", units.DimPressure, func(w *WeatherMessage) *float64 { return w.Current.PressureMSL }},
+	{"visibility", units.DimLength, func(w *WeatherMessage) *float64 { return w.Current.Visibility }},
+	{"elevation", units.DimLength, func(w *WeatherMessage) *float64 { return w.Location.Elevation }},
+}
+
+// UnitConversion registra a conversão de um campo para a unidade do worker
+type UnitConversion struct {
+	Field string  `json:"field"`
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Value float64 `json:"value"`
+}
+
+// NormalizeUnits converte os campos declarados no bloco units para °C, km/h,
+// hPa, m e mm, antes da validação. Campos sem unidade declarada já estão nessas
+// unidades. Em caso de unidade desconhecida a mensagem não é alterada; após a
+// conversão o bloco é descartado, de modo que normalizar de novo não converte
+// duas vezes.
+func (w *WeatherMessage) NormalizeUnits() ([]UnitConversion, error) {
+	if len(w.Units) == 0 {
+		return nil, nil
+	}
+
+	type pending struct {
+		target *float64
+		value  float64
+	}
+	var (
+		conversions []UnitConversion
+		updates     []pending
+	)
+	for _, f := range unitFields {
+		unit, ok := w.Units[f.key]
+		if !ok || units.IsBase(f.dimension, unit) {
+			continue
+		}
+		// Unidade inválida é erro mesmo quando o campo não foi informado
+		target := f.value(w)
+		value := 0.0
+		if target != nil {
+			value = *target
+		}
+		converted, err := units.ToBase(f.dimension, value, unit)
+		if err != nil {
+			return nil, fmt.Errorf("units.%s: %w", f.key, err)
+		}
+		if target == nil {
+			continue
+		}
+		converted = math.Round(converted*1000) / 1000
+		updates = append(updates, pending{target, converted})
+		conversions = append(conversions, UnitConversion{
+			Field: f.key, From: unit, To: units.Base(f.dimension), Value: converted,
+		})
+	}
+
+	for _, u := range updates {
+		*u.target = u.value
+	}
+	w.Units = nil
+	return conversions, nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"go-worker/internal/units"
+)
+
+func TestWeatherMessage_NormalizeUnits(t *testing.T) {
+	t.Run("imperial provider", func(t *testing.T) {
+		msg := WeatherMessage{
+			Location: WeatherLocation{Elevation: Float64(2500)},
+			Current: WeatherCurrent{
+				Temperature:     86,
+				Humidity:        50,
+				WindSpeed:       20,
+				Precipitation:   Float64(0.1),
+				SurfacePressure: Float64(27.5),
+				Visibility:      Float64(10),
+			},
+			Units: map[string]string{
+				"temperature":      "°F",
+				"humidity":         "%",
+				"wind_speed":       "mph",
+				"precipitation":    "inch",
+				"surface_pressure": "inHg",
+				"pressure_msl":     "inHg", // campo ausente: nada a converter
+				"visibility":       "mi",
+				"elevation":        "ft",
+			},
+		}
+		conversions, err := msg.NormalizeUnits()
+		if err != nil {
+			t.Fatalf("NormalizeUnits() error = %v", err)
+		}
+		if len(conversions) != 6 {
+			t.Errorf("len(conversions) = %d, want 6: %+v", len(conversions), conversions)
+		}
+
+		c := msg.Current
+		checks := []struct {
+			name      string
+			got, want float64
+		}{
+			{"temperature", c.Temperature, 30},
+			{"humidity", c.Humidity, 50},
+			{"wind_speed", c.WindSpeed, 32.187},
+			{"precipitation", *c.Precipitation, 2.54},
+			{"surface_pressure", *c.SurfacePressure, 931.257},
+			{"visibility", *c.Visibility, 16093.44},
+			{"elevation", *msg.Location.Elevation, 762},
+		}
+		for _, tt := range checks {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		}
+		if msg.Units != nil {
+			t.Errorf("Units = %v, want nil after normalization", msg.Units)
+		}
+		if again, _ := msg.NormalizeUnits(); again != nil {
+			t.Errorf("second NormalizeUnits() = %+v, want no conversion", again)
+		}
+	})
+
+	t.Run("metric units are kept", func(t *testing.T) {
+		msg := WeatherMessage{
+			Current: WeatherCurrent{Temperature: 25.5, WindSpeed: 10},
+			Units:   map[string]string{"temperature": "°C", "wind_speed": "km/h", "time": "iso8601"},
+		}
+		conversions, err := msg.NormalizeUnits()
+		if err != nil || len(conversions) != 0 {
+			t.Errorf("NormalizeUnits() = %+v, %v; want no conversion", conversions, err)
+		}
+		if msg.Current.Temperature != 25.5 {
+			t.Errorf("Temperature = %v, want 25.5", msg.Current.Temperature)
+		}
+	})
+
+	t.Run("unknown unit leaves message untouched", func(t *testing.T) {
+		msg := WeatherMessage{
+			Current: WeatherCurrent{Temperature: 86, WindSpeed: 10},
+			Units:   map[string]string{"temperature": "°F", "wind_speed": "furlong/fortnight"},
+		}
+		if _, err := msg.NormalizeUnits(); !errors.Is(err, units.ErrUnknownUnit) {
+			t.Fatalf("NormalizeUnits() error = %v, want ErrUnknownUnit", err)
+		}
+		if msg.Current.Temperature != 86 {
+			t.Errorf("Temperature = %v, want 86 (unchanged)", msg.Current.Temperature)
+		}
+	})
+}
@@ -2,119 +2,323 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"time"
+
+	"go-worker/internal/geo"
 )
 
 // WeatherLocation representa dados de localização
 type WeatherLocation struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	Timezone  string  `json:"timezone"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Timezone  string   `json:"timezone"`
+	Elevation *float64 `json:"elevation,omitempty"` // m, quando o provedor informa
 }
 
-// WeatherCurrent representa dados climáticos atuais
+// WeatherCurrent representa dados climáticos atuais. Os campos ponteiro são
+// opcionais: nil significa que o provedor não informou o valor.
 type WeatherCurrent struct {
-	Temperature   float64 `json:"temperature"`
-	Humidity      float64 `json:"humidity"`
-	Precipitation float64 `json:"precipitation"`
-	WindSpeed     float64 `json:"wind_speed"`
-	WeatherCode   int     `json:"weather_code"`
-	Time          string  `json:"time"`
+	Temperature float64 `json:"temperature"`
+	Humidity    float64 `json:"humidity"`
+	WindSpeed   float64 `json:"wind_speed"`
+	WeatherCode int     `json:"weather_code"`
+	Time        string  `json:"time"`
+
+	Precipitation   *float64 `json:"precipitation,omitempty"`    // mm
+	SurfacePressure *float64 `json:"surface_pressure,omitempty"` // hPa, na altitude da estação
+	PressureMSL     *float64 `json:"pressure_msl,omitempty"`     // hPa, reduzida ao nível do mar
+	Visibility      *float64 `json:"visibility,omitempty"`       // m
+	UVIndex         *float64 `json:"uv_index,omitempty"`
+	WindDirection   *float64 `json:"wind_direction,omitempty"` // graus, de onde o vento sopra
+	WindGusts       *float64 `json:"wind_gusts,omitempty"`     // km/h
+	CloudCover      *float64 `json:"cloud_cover,omitempty"`    // %
+	DewPoint        *float64 `json:"dew_point,omitempty"`      // °C, quando o provedor informa
+
+	// Extremos do dia, presentes nas previsões diárias
+	TemperatureMax *float64 `json:"temperature_max,omitempty"` // °C
+	TemperatureMin *float64 `json:"temperature_min,omitempty"` // °C
+}
+
+// RegionRef é uma região que contém o ponto, com as propriedades GeoJSON
+// repassadas ao backend
+type RegionRef struct {
+	Name       string                 `json:"name"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Float64 retorna um ponteiro para v, para preencher campos opcionais
+func Float64(v float64) *float64 {
+	return &v
 }
 
 // WeatherMessage representa a mensagem recebida do RabbitMQ (formato Python)
 type WeatherMessage struct {
+	// SchemaVersion é a versão do schema da mensagem canônica, já convertida
+	// para a atual (schema.Current) pelo adaptador canonical
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	Timestamp string          `json:"timestamp"`
 	Location  WeatherLocation `json:"location"`
 	Current   WeatherCurrent  `json:"current"`
+
+	// Source é o provedor que originou a observação (open-meteo, openweathermap, ...)
+	Source string `json:"source,omitempty"`
+
+	// Units declara a unidade dos campos de current e de location.elevation
+	// (como o current_units da Open-Meteo). Campos ausentes estão em °C, km/h,
+	// hPa, m e mm.
+	Units map[string]string `json:"units,omitempty"`
+
+	// Hourly e Daily trazem previsões em arrays paralelos; Expand gera um
+	// registro por horário
+	Hourly *ForecastSeries `json:"hourly,omitempty"`
+	Daily  *ForecastSeries `json:"daily,omitempty"`
+	// IssuedAt é a emissão da previsão; ausente, vale timestamp
+	IssuedAt string `json:"issued_at,omitempty"`
+
+	// Kind e Period são preenchidos por Expand: observation ou forecast e, nas
+	// previsões, a resolução da série (hourly ou daily)
+	Kind   string `json:"kind,omitempty"`
+	Period string `json:"period,omitempty"`
+
+	// Repairs são as marcações da normalização tolerante ("tipo:campo"),
+	// preenchidas pelo worker e repassadas a cada registro expandido
+	Repairs []string `json:"-"`
 }
 
-// WeatherLog representa o payload enviado para a API NestJS
+// WeatherLog representa o payload enviado para a API NestJS. Valores que o
+// provedor não informou ficam nil e não são enviados.
 type WeatherLog struct {
+	// Timestamp é o instante da observação ou, nas previsões, o horário
+	// previsto, em UTC (RFC 3339)
+	Timestamp   string  `json:"timestamp,omitempty"`
 	Location    string  `json:"location"`
 	Temperature float64 `json:"temperature"`
 	Humidity    float64 `json:"humidity"`
-	Pressure    float64 `json:"pressure"`
 	Description string  `json:"description,omitempty"`
-	WindSpeed   float64 `json:"windSpeed,omitempty"`
-	Visibility  float64 `json:"visibility,omitempty"`
-	UvIndex     float64 `json:"uvIndex,omitempty"`
+	Icon        string  `json:"icon,omitempty"`
 	Source      string  `json:"source"`
+
+	Pressure             *float64 `json:"pressure,omitempty"` // ao nível do mar
+	StationPressure      *float64 `json:"stationPressure,omitempty"`
+	Elevation            *float64 `json:"elevation,omitempty"`
+	ElevationSource      string   `json:"elevationSource,omitempty"`
+	WindSpeed            *float64 `json:"windSpeed,omitempty"`
+	WindDirection        string   `json:"windDirection,omitempty"` // rosa de 16 pontos, no idioma da descrição
+	WindDirectionDegrees *float64 `json:"windDirectionDegrees,omitempty"`
+	WindGust             *float64 `json:"windGust,omitempty"`
+	Visibility           *float64 `json:"visibility,omitempty"`
+	UvIndex              *float64 `json:"uvIndex,omitempty"`
+	Precipitation        *float64 `json:"precipitation,omitempty"`
+	CloudCover           *float64 `json:"cloudCover,omitempty"`
+
+	// Métricas de conforto, serializadas como campos do próprio payload
+	DerivedQuantities
+
+	// Regions lista as regiões definidas pelo usuário que contêm o ponto, da
+	// mais prioritária para a menos
+	Regions []string `json:"regions,omitempty"`
+	// RegionDetails repete as regiões de Regions, na mesma ordem, com as
+	// propriedades GeoJSON configuradas em REGION_PROPERTIES; vazio sem elas
+	RegionDetails []RegionRef `json:"regionDetails,omitempty"`
+
+	// QualityFlags lista as regras de qualidade configuradas que marcaram a
+	// observação (nome da regra) ou corrigiram um valor ("nome:clamped") e os
+	// testes temporais reprovados ("qc:step:temperature")
+	QualityFlags []string `json:"qualityFlags,omitempty"`
+	// QualityScore é a fração dos testes de qualidade temporal aprovados (0 a
+	// 1); ausente quando a estação ainda não tem histórico
+	QualityScore *float64 `json:"qualityScore,omitempty"`
+	// Repairs lista os defeitos do payload corrigidos pela normalização
+	// tolerante ("decimal-comma:current.temperature")
+	Repairs []string `json:"repairs,omitempty"`
+
+	// Kind separa observações de previsões, que vão para endpoints distintos
+	Kind string `json:"kind,omitempty"`
+	// IssuedAt e Period identificam a previsão: emissão e resolução da série
+	IssuedAt string `json:"issuedAt,omitempty"`
+	Period   string `json:"period,omitempty"`
+	// Extremos previstos para o dia, nas previsões diárias
+	TemperatureMax *float64 `json:"temperatureMax,omitempty"`
+	TemperatureMin *float64 `json:"temperatureMin,omitempty"`
 }
 
-// Validate valida os dados da mensagem meteorológica
-func (w *WeatherMessage) Validate() error {
-	if w.Timestamp == "" {
-		return ErrInvalidTimestamp
-	}
-	if w.Location.Latitude < -90 || w.Location.Latitude > 90 {
-		return ErrInvalidLocation
-	}
-	if w.Location.Longitude < -180 || w.Location.Longitude > 180 {
-		return ErrInvalidLocation
+// RuleResult é o resultado de uma regra de validação
+type RuleResult struct {
+	Rule   string      `json:"rule"`
+	Field  string      `json:"field"`
+	Value  interface{} `json:"value"`
+	Passed bool        `json:"passed"`
+	Err    error       `json:"-"`
+}
+
+// CheckRules avalia todas as regras de validação, na ordem de Validate
+func (w *WeatherMessage) CheckRules() []RuleResult {
+	rules := []RuleResult{
+		{Rule: "required", Field: "timestamp", Value: w.Timestamp,
+			Passed: w.Timestamp != "", Err: ErrInvalidTimestamp},
 	}
-	if w.Current.Temperature < -100 || w.Current.Temperature > 100 {
-		return ErrInvalidTemperature
+	rules = append(rules, w.timestampRules()...)
+	return append(rules, []RuleResult{
+		{Rule: "range[-90,90]", Field: "location.latitude", Value: w.Location.Latitude,
+			Passed: w.Location.Latitude >= -90 && w.Location.Latitude <= 90, Err: ErrInvalidLocation},
+		{Rule: "range[-180,180]", Field: "location.longitude", Value: w.Location.Longitude,
+			Passed: w.Location.Longitude >= -180 && w.Location.Longitude <= 180, Err: ErrInvalidLocation},
+		{Rule: "range[-100,100]", Field: "current.temperature", Value: w.Current.Temperature,
+			Passed: w.Current.Temperature >= -100 && w.Current.Temperature <= 100, Err: ErrInvalidTemperature},
+		{Rule: "range[-100,100]", Field: "current.temperature_max", Value: w.Current.TemperatureMax,
+			Passed: optionalInRange(w.Current.TemperatureMax, -100, 100), Err: ErrInvalidTemperature},
+		{Rule: "range[-100,100]", Field: "current.temperature_min", Value: w.Current.TemperatureMin,
+			Passed: optionalInRange(w.Current.TemperatureMin, -100, 100), Err: ErrInvalidTemperature},
+		{Rule: "range[0,100]", Field: "current.humidity", Value: w.Current.Humidity,
+			Passed: w.Current.Humidity >= 0 && w.Current.Humidity <= 100, Err: ErrInvalidHumidity},
+		{Rule: "min[0]", Field: "current.wind_speed", Value: w.Current.WindSpeed,
+			Passed: w.Current.WindSpeed >= 0, Err: ErrInvalidWindSpeed},
+		{Rule: "min[0]", Field: "current.wind_gusts", Value: w.Current.WindGusts,
+			Passed: w.Current.WindGusts == nil || *w.Current.WindGusts >= 0, Err: ErrInvalidWindSpeed},
+		{Rule: "range[0,360]", Field: "current.wind_direction", Value: w.Current.WindDirection,
+			Passed: w.Current.WindDirection == nil || ValidWindDirection(*w.Current.WindDirection), Err: ErrInvalidWindDirection},
+		{Rule: "range[0,99]", Field: "current.weather_code", Value: w.Current.WeatherCode,
+			Passed: w.Current.WeatherCode >= 0 && w.Current.WeatherCode <= 99, Err: ErrInvalidWeatherCode},
+		{Rule: "min[0]", Field: "current.precipitation", Value: w.Current.Precipitation,
+			Passed: optionalInRange(w.Current.Precipitation, 0, math.Inf(1)), Err: ErrInvalidPrecipitation},
+		{Rule: "range[300,1100]", Field: "current.surface_pressure", Value: w.Current.SurfacePressure,
+			Passed: optionalInRange(w.Current.SurfacePressure, 300, 1100), Err: ErrInvalidPressure},
+		{Rule: "range[300,1100]", Field: "current.pressure_msl", Value: w.Current.PressureMSL,
+			Passed: optionalInRange(w.Current.PressureMSL, 300, 1100), Err: ErrInvalidPressure},
+		{Rule: "min[0]", Field: "current.visibility", Value: w.Current.Visibility,
+			Passed: optionalInRange(w.Current.Visibility, 0, math.Inf(1)), Err: ErrInvalidVisibility},
+		{Rule: "min[0]", Field: "current.uv_index", Value: w.Current.UVIndex,
+			Passed: optionalInRange(w.Current.UVIndex, 0, math.Inf(1)), Err: ErrInvalidUVIndex},
+		{Rule: "range[0,100]", Field: "current.cloud_cover", Value: w.Current.CloudCover,
+			Passed: optionalInRange(w.Current.CloudCover, 0, 100), Err: ErrInvalidCloudCover},
+	}...)
+}
+
+// optionalInRange aceita campos opcionais ausentes
+func optionalInRange(v *float64, lo, hi float64) bool {
+	return v == nil || (*v >= lo && *v <= hi)
+}
+
+// Validate valida os dados da mensagem meteorológica. O erro é um
+// *ValidationError com todas as regras violadas.
+func (w *WeatherMessage) Validate() error {
+	var violations []Violation
+	for _, r := range w.CheckRules() {
+		if !r.Passed {
+			violations = append(violations, r.violation())
+		}
 	}
-	if w.Current.Humidity < 0 || w.Current.Humidity > 100 {
-		return ErrInvalidHumidity
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
 	}
 	return nil
 }
 
+// Métodos de resolução de localização retornados por ResolveLocation
+const (
+	LocationGazetteer   = "gazetteer"
+	LocationCoordinates = "coordinates"
+)
+
 // GetLocationString cria string de localização baseada nas coordenadas
 func (w *WeatherMessage) GetLocationString() string {
-	// Converte coordenadas para nome aproximado da cidade
+	name, _ := w.ResolveLocation()
+	return name
+}
+
+// ResolveLocation retorna o nome da localização e o método usado para obtê-lo
+func (w *WeatherMessage) ResolveLocation() (string, string) {
 	lat := w.Location.Latitude
 	lon := w.Location.Longitude
 
-	// São Paulo aproximado
-	if lat >= -23.8 && lat <= -23.3 && lon >= -46.9 && lon <= -46.3 {
-		return "São Paulo, SP"
+	// Município mais próximo no gazetteer, dentro da distância máxima
+	if place, _, ok := geo.DefaultResolver().Resolve(lat, lon); ok {
+		return place.Label(), LocationGazetteer
 	}
 
 	// Para outras coordenadas, retorna coordenadas formatadas
-	return fmt.Sprintf("%.4f,%.4f", lat, lon)
+	return fmt.Sprintf("%.4f,%.4f", lat, lon), LocationCoordinates
 }
 
-// GetWeatherDescription converte código do tempo em descrição
-func (w *WeatherMessage) GetWeatherDescription() string {
-	code := w.Current.WeatherCode
-
-	// Códigos WMO Weather interpretation
-	switch {
-	case code == 0:
-		return "Céu limpo"
-	case code >= 1 && code <= 3:
-		return "Parcialmente nublado"
-	case code >= 45 && code <= 48:
-		return "Neblina"
-	case code >= 51 && code <= 57:
-		return "Garoa"
-	case code >= 61 && code <= 67:
-		return "Chuva"
-	case code >= 71 && code <= 77:
-		return "Neve"
-	case code >= 80 && code <= 82:
-		return "Chuva forte"
-	case code >= 95 && code <= 99:
-		return "Tempestade"
-	default:
-		return "Condição desconhecida"
+// DefaultSource identifica o worker no payload quando a mensagem não informa o provedor
+const DefaultSource = "go-worker"
+
+// StationKey identifica a estação nos históricos (controle de qualidade,
+// anomalias) pela fonte e pelas coordenadas arredondadas a 0,001° (~100 m)
+func (w *WeatherMessage) StationKey() string {
+	return fmt.Sprintf("%s@%.3f,%.3f", w.source(), w.Location.Latitude, w.Location.Longitude)
+}
+
+func (w *WeatherMessage) source() string {
+	if w.Source != "" {
+		return w.Source
+	}
+	return DefaultSource
+}
+
+// windDirectionLabel converte a direção opcional em graus para o ponto cardeal
+func windDirectionLabel(degrees *float64, lang string) string {
+	if degrees == nil {
+		return ""
 	}
+	return CardinalDirection(*degrees, lang)
+}
+
+// GetWeatherDescription converte código do tempo em descrição no idioma padrão
+func (w *WeatherMessage) GetWeatherDescription() string {
+	return w.Condition(DefaultLanguage).Description
 }
 
 // ToWeatherLog converte WeatherMessage para WeatherLog
 func (w *WeatherMessage) ToWeatherLog() WeatherLog {
+	return w.ToLocalizedWeatherLog(DefaultLanguage)
+}
+
+// ToLocalizedWeatherLog converte WeatherMessage para WeatherLog com a descrição
+// do tempo no idioma informado
+func (w *WeatherMessage) ToLocalizedWeatherLog(lang string) WeatherLog {
+	condition := w.Condition(lang)
+	pressure := w.Pressure()
+	var timestamp string
+	if observed, err := w.ObservedAt(); err == nil {
+		timestamp = observed.Format(time.RFC3339)
+	}
+	kind := w.Kind
+	if kind == "" {
+		kind = KindObservation
+	}
 	return WeatherLog{
+		Timestamp:   timestamp,
 		Location:    w.GetLocationString(),
 		Temperature: w.Current.Temperature,
 		Humidity:    w.Current.Humidity,
-		Pressure:    1013.25, // Pressão padrão (Open-Meteo não fornece diretamente)
-		Description: w.GetWeatherDescription(),
-		WindSpeed:   w.Current.WindSpeed,
-		Visibility:  10000, // Visibilidade padrão em metros
-		UvIndex:     0,     // Open-Meteo não fornece UV index no endpoint atual
-		Source:      "go-worker",
+		Description: condition.Description,
+		Icon:        condition.Icon,
+		Source:      w.source(),
+
+		Pressure:             pressure.SeaLevel,
+		StationPressure:      pressure.Station,
+		Elevation:            pressure.Elevation,
+		ElevationSource:      pressure.ElevationSource,
+		WindSpeed:            Float64(w.Current.WindSpeed),
+		WindDirection:        windDirectionLabel(w.Current.WindDirection, lang),
+		WindDirectionDegrees: w.Current.WindDirection,
+		WindGust:             w.Current.WindGusts,
+		Visibility:           w.Current.Visibility,
+		UvIndex:              w.Current.UVIndex,
+		Precipitation:        w.Current.Precipitation,
+		CloudCover:           w.Current.CloudCover,
+
+		DerivedQuantities: w.Current.Derive(),
+
+		Kind:           kind,
+		IssuedAt:       w.IssuedAt,
+		Period:         w.Period,
+		TemperatureMax: w.Current.TemperatureMax,
+		TemperatureMin: w.Current.TemperatureMin,
+
+		Repairs: w.Repairs,
 	}
 }
-
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -100,13 +103,65 @@ func TestWeatherMessage_Validate(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if tt.wantErr && err != tt.errType {
+			if tt.wantErr && !errors.Is(err, tt.errType) {
 				t.Errorf("Validate() error type = %v, want %v", err, tt.errType)
 			}
 		})
 	}
 }
 
+func TestValidationError(t *testing.T) {
+	msg := WeatherMessage{
+		Timestamp: "2025-06-15T14:30:00Z",
+		Location:  WeatherLocation{Latitude: -123.5, Longitude: -46.6},
+		Current: WeatherCurrent{
+			Temperature:   25.5,
+			Humidity:      65,
+			WeatherCode:   150,
+			Precipitation: Float64(-1),
+			CloudCover:    Float64(40),
+		},
+	}
+
+	err := msg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Field: "location.latitude", Value: -123.5, Rule: "range[-90,90]", Message: ErrInvalidLocation.Error(), Err: ErrInvalidLocation},
+		{Field: "current.weather_code", Value: 150, Rule: "range[0,99]", Message: ErrInvalidWeatherCode.Error(), Err: ErrInvalidWeatherCode},
+		{Field: "current.precipitation", Value: -1.0, Rule: "min[0]", Message: ErrInvalidPrecipitation.Error(), Err: ErrInvalidPrecipitation},
+	}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Errorf("Violations = %+v, want %+v", verr.Violations, want)
+	}
+	for _, sentinel := range []error{ErrInvalidLocation, ErrInvalidWeatherCode, ErrInvalidPrecipitation} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("errors.Is(err, %v) = false", sentinel)
+		}
+	}
+	if errors.Is(err, ErrInvalidTemperature) {
+		t.Error("errors.Is(err, ErrInvalidTemperature) = true for a valid temperature")
+	}
+
+	wantMsg := "3 violações: location.latitude=-123.5 (range[-90,90]): invalid location coordinates; " +
+		"current.weather_code=150 (range[0,99]): weather code must be a WMO 4677 code (0 to 99); " +
+		"current.precipitation=-1 (min[0]): precipitation must not be negative"
+	if err.Error() != wantMsg {
+		t.Errorf("Error() = %q, want %q", err.Error(), wantMsg)
+	}
+
+	data, _ := json.Marshal(verr)
+	wantJSON := `{"violations":[{"field":"location.latitude","value":-123.5,"rule":"range[-90,90]","message":"invalid location coordinates"},` +
+		`{"field":"current.weather_code","value":150,"rule":"range[0,99]","message":"weather code must be a WMO 4677 code (0 to 99)"},` +
+		`{"field":"current.precipitation","value":-1,"rule":"min[0]","message":"precipitation must not be negative"}]}`
+	if string(data) != wantJSON {
+		t.Errorf("json = %s, want %s", data, wantJSON)
+	}
+}
+
 func TestWeatherMessage_ToWeatherLog(t *testing.T) {
 	msg := WeatherMessage{
 		Timestamp: "2025-06-15T14:30:00Z",
@@ -120,7 +175,7 @@ func TestWeatherMessage_ToWeatherLog(t *testing.T) {
 			Humidity:      65.0,
 			WindSpeed:     10.5,
 			WeatherCode:   0,
-			Precipitation: 0.0,
+			Precipitation: Float64(0),
 		},
 	}
 
@@ -141,6 +196,67 @@ func TestWeatherMessage_ToWeatherLog(t *testing.T) {
 	if log.Description != "Céu limpo" {
 		t.Errorf("Description = %v, want %v", log.Description, "Céu limpo")
 	}
+	if log.Icon != "clear-day" {
+		t.Errorf("Icon = %v, want %v", log.Icon, "clear-day")
+	}
+}
+
+func TestWeatherMessage_ToWeatherLog_OptionalValues(t *testing.T) {
+	t.Run("missing values are not sent", func(t *testing.T) {
+		msg := WeatherMessage{Current: WeatherCurrent{Temperature: 20, Humidity: 50}}
+		data, err := json.Marshal(msg.ToWeatherLog())
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		for _, name := range []string{"pressure", "visibility", "uvIndex", "windDirectionDegrees", "precipitation", "cloudCover"} {
+			if v, ok := fields[name]; ok {
+				t.Errorf("%s = %v, want absent", name, v)
+			}
+		}
+	})
+
+	t.Run("real values are forwarded, including zero", func(t *testing.T) {
+		var msg WeatherMessage
+		body := `{"current":{"temperature":20,"humidity":50,"wind_speed":0,"surface_pressure":925.1,"pressure_msl":1015.3,"visibility":24140,"uv_index":0,"wind_direction":135,"cloud_cover":75,"precipitation":0.4}}`
+		if err := json.Unmarshal([]byte(body), &msg); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		log := msg.ToWeatherLog()
+
+		checks := []struct {
+			name string
+			got  *float64
+			want float64
+		}{
+			{"Pressure (MSL preferred)", log.Pressure, 1015.3},
+			{"WindSpeed", log.WindSpeed, 0},
+			{"WindDirectionDegrees", log.WindDirectionDegrees, 135},
+			{"Visibility", log.Visibility, 24140},
+			{"UvIndex", log.UvIndex, 0},
+			{"Precipitation", log.Precipitation, 0.4},
+			{"CloudCover", log.CloudCover, 75},
+		}
+		for _, c := range checks {
+			if c.got == nil || *c.got != c.want {
+				t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+			}
+		}
+	})
+
+	t.Run("surface pressure without elevation is not reduced", func(t *testing.T) {
+		msg := WeatherMessage{Current: WeatherCurrent{SurfacePressure: Float64(925.1)}}
+		log := msg.ToWeatherLog()
+		if log.Pressure != nil {
+			t.Errorf("Pressure = %v, want absent", *log.Pressure)
+		}
+		if log.StationPressure == nil || *log.StationPressure != 925.1 {
+			t.Errorf("StationPressure = %v, want 925.1", log.StationPressure)
+		}
+	})
 }
 
 func TestWeatherMessage_GetLocationString(t *testing.T) {
@@ -157,10 +273,22 @@ func TestWeatherMessage_GetLocationString(t *testing.T) {
 			expected: "São Paulo, SP",
 		},
 		{
-			name:     "Other coordinates",
+			name:     "Rio de Janeiro coordinates",
 			lat:      -22.9068,
 			lon:      -43.1729,
-			expected: "-22.9068,-43.1729",
+			expected: "Rio de Janeiro, RJ",
+		},
+		{
+			name:     "Campinas coordinates",
+			lat:      -22.9056,
+			lon:      -47.0608,
+			expected: "Campinas, SP",
+		},
+		{
+			name:     "Coordinates far from any municipality",
+			lat:      -25.0,
+			lon:      -40.0,
+			expected: "-25.0000,-40.0000",
 		},
 	}
 
@@ -204,7 +332,17 @@ func TestWeatherMessage_GetWeatherDescription(t *testing.T) {
 		{
 			name:     "rain",
 			code:     61,
-			expected: "Chuva",
+			expected: "Chuva fraca",
+		},
+		{
+			name:     "freezing drizzle",
+			code:     56,
+			expected: "Garoa congelante fraca",
+		},
+		{
+			name:     "snow showers",
+			code:     85,
+			expected: "Pancadas de neve fracas",
 		},
 		{
 			name:     "unknown code",
@@ -227,81 +365,3 @@ func TestWeatherMessage_GetWeatherDescription(t *testing.T) {
 		})
 	}
 }
-				Source:      "OpenWeatherMap",
-				Humidity:    65.0,
-				Temperature: 25.5,
-				Location:    "São Paulo, BR",
-			msg: WeatherMessage{
-			name: "zero timestamp",
-		{
-		},
-			errType: ErrInvalidHumidity,
-			wantErr: true,
-			},
-				Source:      "OpenWeatherMap",
-				Timestamp:   time.Now(),
-				Humidity:    -10.0,
-				Temperature: 25.5,
-				Location:    "São Paulo, BR",
-			msg: WeatherMessage{
-			name: "invalid humidity - negative",
-		{
-		},
-			errType: ErrInvalidTemperature,
-			wantErr: true,
-			},
-				Source:      "OpenWeatherMap",
-				Timestamp:   time.Now(),
-				Humidity:    65.0,
-				Temperature: 150.0,
-				Location:    "São Paulo, BR",
-			msg: WeatherMessage{
-			name: "invalid temperature - too high",
-		{
-		},
-			errType: ErrInvalidSource,
-			wantErr: true,
-			},
-				Timestamp:   time.Now(),
-				Humidity:    65.0,
-				Temperature: 25.5,
-				Location:    "São Paulo, BR",
-			msg: WeatherMessage{
-			name: "missing source",
-		{
-		},
-			errType: ErrInvalidLocation,
-			wantErr: true,
-			},
-				Source:      "OpenWeatherMap",
-				Timestamp:   time.Now(),
-				Humidity:    65.0,
-				Temperature: 25.5,
-			msg: WeatherMessage{
-			name: "missing location",
-		{
-		},
-			wantErr: false,
-			},
-				Source:      "OpenWeatherMap",
-				Timestamp:   time.Now(),
-				Humidity:    65.0,
-				Temperature: 25.5,
-				Location:    "São Paulo, BR",
-			msg: WeatherMessage{
-			name: "valid message",
-		{
-	}{
-		errType error
-		wantErr bool
-		msg     WeatherMessage
-		name    string
-	tests := []struct {
-func TestWeatherMessage_Validate(t *testing.T) {
-
-)
-	"time"
-	"testing"
-import (
-
-
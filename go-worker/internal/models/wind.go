@@ -0,0 +1,27 @@
+package models
+
+import "math"
+
+// Rosa dos ventos de 16 pontos, a partir do norte no sentido horário
+var cardinalPoints = map[string][16]string{
+	LanguagePtBR: {"N", "NNE", "NE", "ENE", "L", "ESE", "SE", "SSE", "S", "SSO", "SO", "OSO", "O", "ONO", "NO", "NNO"},
+	LanguageEn:   {"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"},
+	LanguageEs:   {"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSO", "SO", "OSO", "O", "ONO", "NO", "NNO"},
+}
+
+// ValidWindDirection indica se a direção está em graus de 0 a 360
+func ValidWindDirection(degrees float64) bool {
+	return degrees >= 0 && degrees <= 360
+}
+
+// CardinalDirection converte a direção de onde o vento sopra (graus) no ponto
+// mais próximo da rosa de 16 pontos. Cada ponto cobre 22,5°, centrado no seu
+// rumo: 348,75° a 11,25° é norte.
+func CardinalDirection(degrees float64, lang string) string {
+	points, ok := cardinalPoints[lang]
+	if !ok {
+		points = cardinalPoints[DefaultLanguage]
+	}
+	normalized := math.Mod(math.Mod(degrees, 360)+360, 360)
+	return points[int((normalized+11.25)/22.5)%16]
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCardinalDirection(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		lang    string
+		want    string
+	}{
+		{0, LanguagePtBR, "N"},
+		{11.2, LanguagePtBR, "N"},
+		{11.25, LanguagePtBR, "NNE"},
+		{90, LanguagePtBR, "L"},
+		{90, LanguageEn, "E"},
+		{135, LanguagePtBR, "SE"},
+		{202.5, LanguageEn, "SSW"},
+		{202.5, LanguagePtBR, "SSO"},
+		{270, LanguageEs, "O"},
+		{348.7, LanguageEn, "NNW"},
+		{348.75, LanguageEn, "N"},
+		{360, LanguagePtBR, "N"},
+		{-90, LanguageEn, "W"},
+		{135, "fr", "SE"},
+	}
+	for _, tt := range tests {
+		if got := CardinalDirection(tt.degrees, tt.lang); got != tt.want {
+			t.Errorf("CardinalDirection(%v, %s) = %q, want %q", tt.degrees, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherMessage_Wind(t *testing.T) {
+	msg := WeatherMessage{
+		Timestamp: "2025-06-15T14:30:00Z",
+		Current: WeatherCurrent{
+			Temperature:   22,
+			Humidity:      60,
+			WindSpeed:     18,
+			WindDirection: Float64(225),
+			WindGusts:     Float64(41),
+		},
+	}
+	log := msg.ToLocalizedWeatherLog(LanguageEn)
+	if log.WindDirection != "SW" || *log.WindDirectionDegrees != 225 || *log.WindGust != 41 {
+		t.Errorf("wind = %q, %v, %v; want SW, 225, 41", log.WindDirection, *log.WindDirectionDegrees, *log.WindGust)
+	}
+	if log := msg.ToWeatherLog(); log.WindDirection != "SO" {
+		t.Errorf("pt-BR WindDirection = %q, want SO", log.WindDirection)
+	}
+
+	invalid := []struct {
+		name    string
+		modify  func(c *WeatherCurrent)
+		wantErr error
+	}{
+		{"direction above 360", func(c *WeatherCurrent) { c.WindDirection = Float64(361) }, ErrInvalidWindDirection},
+		{"negative direction", func(c *WeatherCurrent) { c.WindDirection = Float64(-5) }, ErrInvalidWindDirection},
+		{"negative speed", func(c *WeatherCurrent) { c.WindSpeed = -1 }, ErrInvalidWindSpeed},
+		{"negative gusts", func(c *WeatherCurrent) { c.WindGusts = Float64(-3) }, ErrInvalidWindSpeed},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			m := msg
+			tt.modify(&m.Current)
+			if err := m.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"strings"
+
+	"go-worker/internal/geo"
+)
+
+// Idiomas suportados nas descrições do tempo
+const (
+	LanguagePtBR = "pt-BR"
+	LanguageEn   = "en"
+	LanguageEs   = "es"
+)
+
+// DefaultLanguage é o idioma usado quando nenhum outro é configurado
+const DefaultLanguage = LanguagePtBR
+
+// NormalizeLanguage converte variações como "pt", "pt_BR" ou "en-US" para um
+// idioma suportado
+func NormalizeLanguage(lang string) (string, bool) {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	primary, _, _ := strings.Cut(tag, "-")
+	switch primary {
+	case "pt":
+		return LanguagePtBR, true
+	case "en":
+		return LanguageEn, true
+	case "es":
+		return LanguageEs, true
+	default:
+		return "", false
+	}
+}
+
+// text guarda uma descrição nos idiomas suportados
+type text struct {
+	ptBR, en, es string
+}
+
+func (t text) in(lang string) string {
+	switch lang {
+	case LanguageEn:
+		return t.en
+	case LanguageEs:
+		return t.es
+	default:
+		return t.ptBR
+	}
+}
+
+// wmoCode é uma entrada da tabela de códigos WMO 4677 usada pela Open-Meteo.
+// night é opcional; quando vazio a descrição diurna vale para a noite.
+// Ícones com solar=true recebem o sufixo "-day" ou "-night".
+type wmoCode struct {
+	day   text
+	night text
+	icon  string
+	solar bool
+}
+
+var wmoCodes = map[int]wmoCode{
+	0: {
+		day:   text{"Céu limpo", "Clear sky", "Cielo despejado"},
+		night: text{"Noite de céu limpo", "Clear night", "Noche despejada"},
+		icon:  "clear",
+		solar: true,
+	},
+	1: {
+		day:   text{"Predominantemente ensolarado", "Mainly sunny", "Mayormente soleado"},
+		night: text{"Predominantemente limpo", "Mainly clear", "Mayormente despejado"},
+		icon:  "mostly-clear",
+		solar: true,
+	},
+	2:  {day: text{"Parcialmente nublado", "Partly cloudy", "Parcialmente nublado"}, icon: "partly-cloudy", solar: true},
+	3:  {day: text{"Encoberto", "Overcast", "Cubierto"}, icon: "overcast"},
+	45: {day: text{"Neblina", "Fog", "Niebla"}, icon: "fog"},
+	48: {day: text{"Neblina com deposição de geada", "Depositing rime fog", "Niebla con escarcha"}, icon: "rime-fog"},
+	51: {day: text{"Garoa fraca", "Light drizzle", "Llovizna ligera"}, icon: "drizzle"},
+	53: {day: text{"Garoa moderada", "Moderate drizzle", "Llovizna moderada"}, icon: "drizzle"},
+	55: {day: text{"Garoa intensa", "Dense drizzle", "Llovizna intensa"}, icon: "drizzle"},
+	56: {day: text{"Garoa congelante fraca", "Light freezing drizzle", "Llovizna helada ligera"}, icon: "freezing-drizzle"},
+	57: {day: text{"Garoa congelante intensa", "Dense freezing drizzle", "Llovizna helada intensa"}, icon: "freezing-drizzle"},
+	61: {day: text{"Chuva fraca", "Slight rain", "Lluvia ligera"}, icon: "rain"},
+	63: {day: text{"Chuva moderada", "Moderate rain", "Lluvia moderada"}, icon: "rain"},
+	65: {day: text{"Chuva forte", "Heavy rain", "Lluvia intensa"}, icon: "heavy-rain"},
+	66: {day: text{"Chuva congelante fraca", "Light freezing rain", "Lluvia helada ligera"}, icon: "freezing-rain"},
+	67: {day: text{"Chuva congelante forte", "Heavy freezing rain", "Lluvia helada intensa"}, icon: "freezing-rain"},
+	71: {day: text{"Neve fraca", "Slight snowfall", "Nevada ligera"}, icon: "snow"},
+	73: {day: text{"Neve moderada", "Moderate snowfall", "Nevada moderada"}, icon: "snow"},
+	75: {day: text{"Neve forte", "Heavy snowfall", "Nevada intensa"}, icon: "heavy-snow"},
+	77: {day: text{"Grãos de neve", "Snow grains", "Granos de nieve"}, icon: "snow-grains"},
+	80: {day: text{"Pancadas de chuva fracas", "Slight rain showers", "Chubascos ligeros"}, icon: "showers", solar: true},
+	81: {day: text{"Pancadas de chuva moderadas", "Moderate rain showers", "Chubascos moderados"}, icon: "showers", solar: true},
+	82: {day: text{"Pancadas de chuva violentas", "Violent rain showers", "Chubascos violentos"}, icon: "heavy-showers", solar: true},
+	85: {day: text{"Pancadas de neve fracas", "Slight snow showers", "Chubascos de nieve ligeros"}, icon: "snow-showers", solar: true},
+	86: {day: text{"Pancadas de neve fortes", "Heavy snow showers", "Chubascos de nieve intensos"}, icon: "snow-showers", solar: true},
+	95: {day: text{"Trovoada fraca ou moderada", "Slight or moderate thunderstorm", "Tormenta leve o moderada"}, icon: "thunderstorm"},
+	96: {day: text{"Trovoada com granizo fraco", "Thunderstorm with slight hail", "Tormenta con granizo leve"}, icon: "thunderstorm-hail"},
+	99: {day: text{"Trovoada com granizo forte", "Thunderstorm with heavy hail", "Tormenta con granizo fuerte"}, icon: "thunderstorm-hail"},
+}
+
+var unknownCondition = text{"Condição desconhecida", "Unknown conditions", "Condición desconocida"}
+
+// WeatherCondition é a interpretação de um código WMO
+type WeatherCondition struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	IsDay       bool   `json:"isDay"`
+	Known       bool   `json:"known"`
+}
+
+// DescribeWeatherCode interpreta um código WMO no idioma e período informados
+func DescribeWeatherCode(code int, lang string, isDay bool) WeatherCondition {
+	entry, ok := wmoCodes[code]
+	if !ok {
+		return WeatherCondition{Code: code, Description: unknownCondition.in(lang), Icon: "unknown", IsDay: isDay}
+	}
+
+	desc := entry.day
+	if !isDay && entry.night != (text{}) {
+		desc = entry.night
+	}
+	icon := entry.icon
+	if entry.solar {
+		if isDay {
+			icon += "-day"
+		} else {
+			icon += "-night"
+		}
+	}
+	return WeatherCondition{Code: code, Description: desc.in(lang), Icon: icon, IsDay: isDay, Known: true}
+}
+
+// Condition interpreta o código do tempo da mensagem, escolhendo a variante
+// diurna ou noturna pela posição do Sol no momento da observação
+func (w *WeatherMessage) Condition(lang string) WeatherCondition {
+	return DescribeWeatherCode(w.Current.WeatherCode, lang, w.IsDaytime())
+}
+
+// IsDaytime indica se o Sol estava acima do horizonte na observação. Sem um
+// horário utilizável, assume dia. As previsões diárias descrevem o dia todo e
+// ficam com a variante diurna, embora o timestamp seja a meia-noite local.
+func (w *WeatherMessage) IsDaytime() bool {
+	if w.Period == PeriodDaily {
+		return true
+	}
+	observed, err := w.ObservedAt()
+	if err != nil {
+		return true
+	}
+	return geo.IsDaylight(w.Location.Latitude, w.Location.Longitude, observed)
+}
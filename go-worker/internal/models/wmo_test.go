@@ -0,0 +1,87 @@
+package models
+
+import "testing"
+
+func TestDescribeWeatherCode(t *testing.T) {
+	tests := []struct {
+		code     int
+		lang     string
+		isDay    bool
+		wantDesc string
+		wantIcon string
+	}{
+		{0, LanguagePtBR, true, "Céu limpo", "clear-day"},
+		{0, LanguagePtBR, false, "Noite de céu limpo", "clear-night"},
+		{0, LanguageEn, false, "Clear night", "clear-night"},
+		{1, LanguageEs, true, "Mayormente soleado", "mostly-clear-day"},
+		{3, LanguageEn, false, "Overcast", "overcast"},
+		{48, LanguagePtBR, true, "Neblina com deposição de geada", "rime-fog"},
+		{53, LanguagePtBR, true, "Garoa moderada", "drizzle"},
+		{57, LanguageEn, true, "Dense freezing drizzle", "freezing-drizzle"},
+		{67, LanguageEs, true, "Lluvia helada intensa", "freezing-rain"},
+		{82, LanguageEn, false, "Violent rain showers", "heavy-showers-night"},
+		{86, LanguagePtBR, true, "Pancadas de neve fortes", "snow-showers-day"},
+		{99, LanguageEs, true, "Tormenta con granizo fuerte", "thunderstorm-hail"},
+		{4, LanguageEn, true, "Unknown conditions", "unknown"},
+	}
+
+	for _, tt := range tests {
+		got := DescribeWeatherCode(tt.code, tt.lang, tt.isDay)
+		if got.Description != tt.wantDesc || got.Icon != tt.wantIcon {
+			t.Errorf("DescribeWeatherCode(%d, %s, %v) = %q/%q, want %q/%q",
+				tt.code, tt.lang, tt.isDay, got.Description, got.Icon, tt.wantDesc, tt.wantIcon)
+		}
+	}
+}
+
+func TestWMOTable_Complete(t *testing.T) {
+	// Códigos publicados pela Open-Meteo (WMO 4677, subconjunto WW)
+	codes := []int{0, 1, 2, 3, 45, 48, 51, 53, 55, 56, 57, 61, 63, 65, 66, 67, 71, 73, 75, 77, 80, 81, 82, 85, 86, 95, 96, 99}
+	for _, code := range codes {
+		entry, ok := wmoCodes[code]
+		if !ok {
+			t.Errorf("code %d missing", code)
+			continue
+		}
+		for _, tx := range []text{entry.day, entry.night} {
+			if tx != (text{}) && (tx.ptBR == "" || tx.en == "" || tx.es == "") {
+				t.Errorf("code %d has a missing translation: %+v", code, tx)
+			}
+		}
+	}
+}
+
+func TestWeatherMessage_IsDaytime(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp string
+		want      bool
+	}{
+		{"afternoon in São Paulo (naive UTC)", "2025-06-15T17:00:00.123456", true},
+		{"night in São Paulo", "2025-06-15T02:00:00", false},
+		{"explicit offset", "2025-06-15T21:30:00-03:00", false},
+		{"unparseable timestamp assumes day", "ontem", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := WeatherMessage{
+				Timestamp: tt.timestamp,
+				Location:  WeatherLocation{Latitude: -23.5505, Longitude: -46.6333},
+			}
+			if got := msg.IsDaytime(); got != tt.want {
+				t.Errorf("IsDaytime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{"pt": LanguagePtBR, "pt_BR": LanguagePtBR, "EN-us": LanguageEn, "es-AR": LanguageEs, "fr": ""}
+	for in, want := range tests {
+		got, _ := NormalizeLanguage(in)
+		if got != want {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package normalize corrige, no modo tolerante, defeitos conhecidos dos
+// produtores antes da validação: números em texto (inclusive com vírgula
+// decimal), umidade em fração, latitude e longitude trocadas e timestamp vazio
+// com current.time presente. Cada reparo vira uma marcação no registro
+// ("tipo:campo") e é contado nas métricas, para que o produtor seja corrigido.
+package normalize
+
+import (
+	"expvar"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-worker/internal/geo"
+	"go-worker/internal/models"
+)
+
+// Tipos de reparo
+const (
+	KindDecimalComma       = "decimal-comma"
+	KindNumericString      = "numeric-string"
+	KindHumidityFraction   = "humidity-fraction"
+	KindSwappedCoordinates = "swapped-coordinates"
+	KindTimestampFromTime  = "timestamp-from-current-time"
+)
+
+// Métricas publicadas em /debug/vars
+var (
+	repairsByKind    = expvar.NewMap("normalize_repairs")
+	repairsBySource  = expvar.NewMap("normalize_repairs_by_source")
+	repairedMessages = expvar.NewInt("normalize_repaired_messages")
+)
+
+// Vars lista os nomes das métricas do pacote, na ordem de publicação
+func Vars() []string {
+	return []string{"normalize_repairs", "normalize_repairs_by_source", "normalize_repaired_messages"}
+}
+
+// Repair descreve uma correção
+type Repair struct {
+	Kind  string `json:"kind"`
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Flag é a marcação do reparo no registro, como "decimal-comma:current.temperature"
+func (r Repair) Flag() string {
+	return r.Kind + ":" + r.Field
+}
+
+// ParseFlag separa tipo e campo de uma marcação
+func ParseFlag(flag string) (kind, field string) {
+	kind, field, _ = strings.Cut(flag, ":")
+	return kind, field
+}
+
+// Flags lista as marcações dos reparos
+func Flags(repairs []Repair) []string {
+	if len(repairs) == 0 {
+		return nil
+	}
+	flags := make([]string, len(repairs))
+	for i, r := range repairs {
+		flags[i] = r.Flag()
+	}
+	return flags
+}
+
+// numericLocation são os campos numéricos de location; em current, todos
+// exceto time são numéricos
+var numericLocation = []string{"latitude", "longitude", "elevation"}
+
+// Document converte os números enviados como texto em location e current do
+// documento JSON (decodificado com UseNumber), antes da conversão para
+// WeatherMessage. Textos que não são números ficam para o decode.
+func Document(doc map[string]any) []Repair {
+	var repairs []Repair
+	if location, ok := doc["location"].(map[string]any); ok {
+		for _, key := range numericLocation {
+			if r, ok := repairNumber(location, key, "location."); ok {
+				repairs = append(repairs, r)
+			}
+		}
+	}
+	if current, ok := doc["current"].(map[string]any); ok {
+		for _, key := range sortedKeys(current) {
+			if key == "time" {
+				continue
+			}
+			if r, ok := repairNumber(current, key, "current."); ok {
+				repairs = append(repairs, r)
+			}
+		}
+	}
+	return repairs
+}
+
+func repairNumber(obj map[string]any, key, prefix string) (Repair, bool) {
+	text, ok := obj[key].(string)
+	if !ok {
+		return Repair{}, false
+	}
+	v, kind, ok := ParseNumber(text)
+	if !ok {
+		return Repair{}, false
+	}
+	obj[key] = v
+	return Repair{Kind: kind, Field: prefix + key, From: text, To: v}, true
+}
+
+// ParseNumber lê um número em texto, com ponto ou vírgula decimal ("25,3" e
+// "1.013,2"), indicando o tipo de reparo
+func ParseNumber(text string) (float64, string, bool) {
+	t := strings.TrimSpace(text)
+	kind := KindNumericString
+	if comma := strings.Index(t, ","); comma >= 0 {
+		kind = KindDecimalComma
+		if strings.Count(t, ",") > 1 || strings.LastIndex(t, ".") > comma {
+			return 0, "", false
+		}
+		// Pontos antes da vírgula separam milhares
+		t = strings.Replace(strings.ReplaceAll(t, ".", ""), ",", ".", 1)
+	}
+	if t == "" || strings.ContainsAny(t, "_xXpP") {
+		return 0, "", false
+	}
+	v, err := strconv.ParseFloat(t, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, "", false
+	}
+	return v, kind, true
+}
+
+// Message corrige a mensagem decodificada, antes da expansão das previsões:
+// coordenadas trocadas e timestamp vazio com current.time presente. Os reparos
+// são acrescentados a Repairs.
+func Message(w *models.WeatherMessage) []Repair {
+	var repairs []Repair
+	if swappedCoordinates(w.Location.Latitude, w.Location.Longitude) {
+		from := []float64{w.Location.Latitude, w.Location.Longitude}
+		w.Location.Latitude, w.Location.Longitude = w.Location.Longitude, w.Location.Latitude
+		repairs = append(repairs, Repair{Kind: KindSwappedCoordinates, Field: "location", From: from,
+			To: []float64{w.Location.Latitude, w.Location.Longitude}})
+	}
+	if w.Timestamp == "" && w.Current.Time != "" {
+		if observed, err := models.ParseLocalTime(w.Current.Time, w.Location.Timezone); err == nil {
+			w.Timestamp = observed.UTC().Format(time.RFC3339)
+			repairs = append(repairs, Repair{Kind: KindTimestampFromTime, Field: "timestamp", From: "", To: w.Timestamp})
+		}
+	}
+	w.Repairs = appendFlags(w.Repairs, repairs)
+	return repairs
+}
+
+// swappedCoordinates reconhece latitude e longitude trocadas: latitude fora de
+// ±90 com longitude dentro ou, com as duas dentro, um ponto sem município
+// próximo cujo inverso tem um. O segundo caso só funciona dentro da cobertura
+// de geo.DefaultResolver (municípios do gazetteer embutido a até
+// GEO_MAX_DISTANCE_KM, 10 km por padrão): fora dela, coordenadas trocadas
+// dentro de ±90 não são detectadas e seguem para a validação.
+func swappedCoordinates(lat, lon float64) bool {
+	if math.Abs(lat) > 90 {
+		return math.Abs(lon) <= 90
+	}
+	if math.Abs(lon) > 90 || lat == lon {
+		return false
+	}
+	resolver := geo.DefaultResolver()
+	if _, _, ok := resolver.Resolve(lat, lon); ok {
+		return false
+	}
+	_, _, ok := resolver.Resolve(lon, lat)
+	return ok
+}
+
+// Entries corrige os registros já expandidos de uma mensagem (observação e
+// previsões): umidade enviada como fração de 0 a 1. Um valor 1 só é tratado
+// como fração (100%) quando outro registro da mensagem está entre 0 e 1 e
+// nenhum passa de 1; sozinho, é ambíguo e continua 1%. Os reparos de cada
+// registro são acrescentados ao seu Repairs.
+func Entries(entries []models.WeatherMessage) []Repair {
+	fractional := false
+	for _, w := range entries {
+		if h := w.Current.Humidity; h > 1 {
+			fractional = false
+			break
+		} else if h > 0 && h < 1 {
+			fractional = true
+		}
+	}
+
+	var all []Repair
+	for i := range entries {
+		w := &entries[i]
+		var repairs []Repair
+		if h := w.Current.Humidity; h > 0 && (h < 1 || h == 1 && fractional) {
+			w.Current.Humidity = math.Round(h*1e6) / 1e4
+			repairs = append(repairs, Repair{Kind: KindHumidityFraction, Field: "current.humidity", From: h, To: w.Current.Humidity})
+		}
+		w.Repairs = appendFlags(w.Repairs, repairs)
+		all = append(all, repairs...)
+	}
+	return all
+}
+
+// appendFlags copia as marcações antes de acrescentar: os registros expandidos
+// compartilham as da mensagem
+func appendFlags(flags []string, repairs []Repair) []string {
+	if len(repairs) == 0 {
+		return flags
+	}
+	return append(flags[:len(flags):len(flags)], Flags(repairs)...)
+}
+
+// Count registra nas métricas os reparos de uma mensagem, por tipo e por
+// source (provedor); deve ser chamado uma vez por mensagem
+func Count(source string, flags []string) {
+	if len(flags) == 0 {
+		return
+	}
+	if source == "" {
+		source = "desconhecido"
+	}
+	repairedMessages.Add(1)
+	for _, flag := range flags {
+		kind, _ := ParseFlag(flag)
+		repairsByKind.Add(kind, 1)
+		repairsBySource.Add(source, 1)
+	}
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
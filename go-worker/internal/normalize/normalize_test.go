@@ -0,0 +1,179 @@
+package normalize
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"go-worker/internal/models"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     float64
+		wantKind string
+		wantOK   bool
+	}{
+		{"25,3", 25.3, KindDecimalComma, true},
+		{" -46,6333 ", -46.6333, KindDecimalComma, true},
+		{"1.013,2", 1013.2, KindDecimalComma, true},
+		{"25.3", 25.3, KindNumericString, true},
+		{"65", 65, KindNumericString, true},
+		{"1,013.2", 0, "", false},
+		{"1,2,3", 0, "", false},
+		{"NaN", 0, "", false},
+		{"0x1p-2", 0, "", false},
+		{"vinte", 0, "", false},
+		{"", 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, kind, ok := ParseNumber(tt.text)
+			if ok != tt.wantOK || got != tt.want || kind != tt.wantKind {
+				t.Errorf("ParseNumber(%q) = %v, %q, %v, want %v, %q, %v", tt.text, got, kind, ok, tt.want, tt.wantKind, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDocument(t *testing.T) {
+	var doc map[string]any
+	body := `{"location":{"latitude":"-23,5505","longitude":-46.6333,"timezone":"America/Sao_Paulo"},"current":{"temperature":"25,3","humidity":"65","time":"2025-06-15T11:30","weather_code":"chuva"}}`
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	repairs := Document(doc)
+	wantFlags := []string{"decimal-comma:location.latitude", "numeric-string:current.humidity", "decimal-comma:current.temperature"}
+	if got := Flags(repairs); !reflect.DeepEqual(got, wantFlags) {
+		t.Errorf("Flags() = %v, want %v", got, wantFlags)
+	}
+	current := doc["current"].(map[string]any)
+	if current["temperature"] != 25.3 || current["time"] != "2025-06-15T11:30" || current["weather_code"] != "chuva" {
+		t.Errorf("current = %v", current)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       models.WeatherMessage
+		wantFlags []string
+		wantLat   float64
+		wantTS    string
+	}{
+		{
+			name:    "well formed",
+			msg:     models.WeatherMessage{Timestamp: "2025-06-15T14:30:00Z", Location: models.WeatherLocation{Latitude: -23.5505, Longitude: -46.6333}},
+			wantLat: -23.5505, wantTS: "2025-06-15T14:30:00Z",
+		},
+		{
+			name:      "latitude out of range",
+			msg:       models.WeatherMessage{Timestamp: "2025-06-15T14:30:00Z", Location: models.WeatherLocation{Latitude: -122.4, Longitude: 37.8}},
+			wantFlags: []string{"swapped-coordinates:location"},
+			wantLat:   37.8, wantTS: "2025-06-15T14:30:00Z",
+		},
+		{
+			name:      "swapped within range resolved by gazetteer",
+			msg:       models.WeatherMessage{Timestamp: "2025-06-15T14:30:00Z", Location: models.WeatherLocation{Latitude: -46.6333, Longitude: -23.5505}},
+			wantFlags: []string{"swapped-coordinates:location"},
+			wantLat:   -23.5505, wantTS: "2025-06-15T14:30:00Z",
+		},
+		{
+			name:    "unknown place is kept",
+			msg:     models.WeatherMessage{Timestamp: "2025-06-15T14:30:00Z", Location: models.WeatherLocation{Latitude: 10, Longitude: 20}},
+			wantLat: 10, wantTS: "2025-06-15T14:30:00Z",
+		},
+		{
+			name: "timestamp from current.time",
+			msg: models.WeatherMessage{Location: models.WeatherLocation{Latitude: -23.5505, Longitude: -46.6333, Timezone: "America/Sao_Paulo"},
+				Current: models.WeatherCurrent{Time: "2025-06-15T11:30"}},
+			wantFlags: []string{"timestamp-from-current-time:timestamp"},
+			wantLat:   -23.5505, wantTS: "2025-06-15T14:30:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.msg
+			Message(&msg)
+			if !reflect.DeepEqual(msg.Repairs, tt.wantFlags) {
+				t.Errorf("Repairs = %v, want %v", msg.Repairs, tt.wantFlags)
+			}
+			if msg.Location.Latitude != tt.wantLat || msg.Timestamp != tt.wantTS {
+				t.Errorf("Latitude = %v, Timestamp = %q, want %v, %q", msg.Location.Latitude, msg.Timestamp, tt.wantLat, tt.wantTS)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	shared := []string{"decimal-comma:current.temperature"}
+	fraction := []models.WeatherMessage{{Current: models.WeatherCurrent{Humidity: 0.65}, Repairs: shared}}
+	Entries(fraction)
+	if fraction[0].Current.Humidity != 65 {
+		t.Errorf("Humidity = %v, want 65", fraction[0].Current.Humidity)
+	}
+	if want := []string{"decimal-comma:current.temperature", "humidity-fraction:current.humidity"}; !reflect.DeepEqual(fraction[0].Repairs, want) {
+		t.Errorf("Repairs = %v, want %v", fraction[0].Repairs, want)
+	}
+	if len(shared) != 1 {
+		t.Errorf("shared repairs changed: %v", shared)
+	}
+
+	tests := []struct {
+		name      string
+		humidity  []float64
+		want      []float64
+		wantFixes int
+	}{
+		{"percent", []float64{0, 1, 65}, []float64{0, 1, 65}, 0},
+		{"lone 1 is ambiguous", []float64{1}, []float64{1}, 0},
+		{"saturation among fractions", []float64{0.92, 1, 0.97}, []float64{92, 100, 97}, 3},
+		{"1 among percents", []float64{0.5, 1, 65}, []float64{50, 1, 65}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := make([]models.WeatherMessage, len(tt.humidity))
+			for i, h := range tt.humidity {
+				entries[i].Current.Humidity = h
+			}
+			repairs := Entries(entries)
+			for i := range entries {
+				if entries[i].Current.Humidity != tt.want[i] {
+					t.Errorf("entries[%d].Humidity = %v, want %v", i, entries[i].Current.Humidity, tt.want[i])
+				}
+			}
+			if len(repairs) != tt.wantFixes {
+				t.Errorf("repairs = %v, want %d", repairs, tt.wantFixes)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	before := repairedMessages.Value()
+	kind := func(k string) int64 {
+		if v, ok := repairsByKind.Get(k).(interface{ Value() int64 }); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	commas := kind(KindDecimalComma)
+
+	Count("", []string{"decimal-comma:current.temperature", "decimal-comma:location.latitude"})
+	Count("station", nil)
+
+	if got := repairedMessages.Value() - before; got != 1 {
+		t.Errorf("repaired messages += %d, want 1", got)
+	}
+	if got := kind(KindDecimalComma) - commas; got != 2 {
+		t.Errorf("decimal-comma += %d, want 2", got)
+	}
+	if v := repairsBySource.Get("desconhecido"); v == nil {
+		t.Error("repairs by source without the unknown source")
+	} else if n, _ := strconv.Atoi(v.String()); n < 2 {
+		t.Errorf("repairs by source = %s, want at least 2", v)
+	}
+}
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"go-worker/internal/adapters"
+	"go-worker/internal/models"
+)
+
+// AirQualitySender é implementado pelos destinos que aceitam registros de
+// qualidade do ar
+type AirQualitySender interface {
+	SendAirQualityLog(models.AirQualityLog) error
+}
+
+// ErrAirQualityUnsupported indica um destino sem suporte a qualidade do ar
+var ErrAirQualityUnsupported = errors.New("destino não aceita registros de qualidade do ar")
+
+// isAirQuality reconhece as mensagens de qualidade do ar pelo content type ou
+// pelos poluentes em current
+func isAirQuality(msg Message) bool {
+	return adapters.IsAirQuality(msg.Body, msg.Type, msg.ContentType)
+}
+
+// TransformAirQuality deserializa, valida e calcula os índices de uma
+// mensagem de qualidade do ar sem enviá-la
+func (p *Processor) TransformAirQuality(msg Message) (models.AirQualityLog, error) {
+	aqMsg, err := p.decodeAirQuality(msg, nil)
+	if err != nil {
+		return models.AirQualityLog{}, err
+	}
+	if err := p.validateAirQuality(&aqMsg, nil); err != nil {
+		return models.AirQualityLog{}, err
+	}
+	logs, err := p.transformAirQualityLog(&aqMsg, msg, nil)
+	if err != nil {
+		return models.AirQualityLog{}, err
+	}
+	return logs[0], nil
+}
+
+// decodeAirQuality abre o pipeline de qualidade do ar: adaptador, unidades,
+// validação e índices. Regras configuradas, QC, anomalias e enriquecimentos
+// tratam de observações meteorológicas e não se aplicam.
+func (p *Processor) decodeAirQuality(msg Message, trace *Trace) (models.AirQualityMessage, error) {
+	aqMsg, selection, err := adapters.DecodeAirQuality(msg.Body)
+	trace.add(StageDecode, "adapter", selection.Adapter, selection)
+	if err != nil {
+		trace.add(StageDecode, "json", "error", err.Error())
+		return models.AirQualityMessage{}, &StageError{Stage: StageDecode, Err: fmt.Errorf("erro ao deserializar mensagem: %w", err)}
+	}
+	if trace != nil {
+		trace.DecodedAirQuality = &aqMsg
+	}
+	trace.add(StageDecode, "json", "ok", nil)
+	return aqMsg, nil
+}
+
+// validateAirQuality converte os poluentes para µg/m³ e valida a mensagem
+func (p *Processor) validateAirQuality(aqMsg *models.AirQualityMessage, trace *Trace) error {
+	conversions, err := aqMsg.NormalizeUnits()
+	if err != nil {
+		trace.add(StageValidate, "units", "error", err.Error())
+		return &StageError{Stage: StageValidate, Err: fmt.Errorf("unidade inválida: %w", err)}
+	}
+	if len(conversions) > 0 {
+		trace.add(StageValidate, "units", "converted", conversions)
+	}
+
+	log.Printf("[INFO] Mensagem de qualidade do ar recebida: location=%.2f,%.2f, poluentes=%d",
+		aqMsg.Location.Latitude, aqMsg.Location.Longitude, len(aqMsg.Current.Concentrations()))
+
+	if trace != nil {
+		for _, r := range aqMsg.CheckRules() {
+			decision := "pass"
+			if !r.Passed {
+				decision = "fail"
+			}
+			trace.add(StageValidate, r.Field, decision, r)
+		}
+	}
+	if err := aqMsg.Validate(); err != nil {
+		return &StageError{Stage: StageValidate, Err: fmt.Errorf("validação falhou: %w", err)}
+	}
+	return nil
+}
+
+// transformAirQualityLog gera o registro com os índices da EPA e da CONAMA
+func (p *Processor) transformAirQualityLog(aqMsg *models.AirQualityMessage, _ Message, trace *Trace) ([]models.AirQualityLog, error) {
+	airQualityLog := aqMsg.ToAirQualityLog()
+	if trace != nil {
+		name, method := aqMsg.ResolveLocation()
+		trace.add(StageTransform, "location", method, name)
+		if airQualityLog.USAQI != nil {
+			trace.add(StageTransform, "aqi.epa", airQualityLog.USAQI.Category, airQualityLog.USAQI)
+		}
+		if airQualityLog.IQAr != nil {
+			trace.add(StageTransform, "aqi.conama", airQualityLog.IQAr.Category, airQualityLog.IQAr)
+		}
+	}
+	return []models.AirQualityLog{airQualityLog}, nil
+}
+
+// airQualitySummary resume o registro de qualidade do ar no log
+func airQualitySummary(logs []models.AirQualityLog) string {
+	return fmt.Sprintf("location=%s, %s", logs[0].Location, indexSummary(logs[0]))
+}
+
+// indexSummary resume os índices calculados para o log
+func indexSummary(l models.AirQualityLog) string {
+	summary := "aqi=n/a"
+	if l.USAQI != nil {
+		summary = fmt.Sprintf("aqi=%d (%s)", l.USAQI.Index, l.USAQI.Dominant)
+	}
+	if l.IQAr != nil {
+		summary += fmt.Sprintf(", iqar=%d (%s)", l.IQAr.Index, l.IQAr.Dominant)
+	}
+	return summary
+}
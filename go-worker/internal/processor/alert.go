@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-worker/internal/models"
+)
+
+// AlertSender é implementado pelos destinos que aceitam alertas meteorológicos
+type AlertSender interface {
+	SendAlertLog(models.AlertLog) error
+}
+
+// ErrAlertUnsupported indica um destino sem suporte a alertas
+var ErrAlertUnsupported = errors.New("destino não aceita alertas")
+
+// decodeAlert deserializa o alerta. Alertas não passam por adaptadores,
+// normalização, QC, anomalias nem enriquecimentos.
+func (p *Processor) decodeAlert(msg Message, trace *Trace) (models.AlertMessage, error) {
+	var alert models.AlertMessage
+	if err := json.Unmarshal(msg.Body, &alert); err != nil {
+		trace.add(StageDecode, "json", "error", err.Error())
+		return models.AlertMessage{}, &StageError{Stage: StageDecode, Err: fmt.Errorf("erro ao deserializar alerta: %w", err)}
+	}
+	trace.add(StageDecode, "json", "ok", nil)
+	return alert, nil
+}
+
+// validateAlert valida evento, severidade, período e coordenadas
+func (p *Processor) validateAlert(alert *models.AlertMessage, trace *Trace) error {
+	log.Printf("[INFO] Alerta recebido: event=%q, severity=%s, location=%.2f,%.2f",
+		alert.Event, alert.Severity, alert.Location.Latitude, alert.Location.Longitude)
+
+	if trace != nil {
+		for _, r := range alert.CheckRules() {
+			decision := "pass"
+			if !r.Passed {
+				decision = "fail"
+			}
+			trace.add(StageValidate, r.Field, decision, r)
+		}
+	}
+	if err := alert.Validate(); err != nil {
+		return &StageError{Stage: StageValidate, Err: fmt.Errorf("validação falhou: %w", err)}
+	}
+	return nil
+}
+
+// transformAlert gera o registro do alerta com a localização resolvida
+func (p *Processor) transformAlert(alert *models.AlertMessage, _ Message, trace *Trace) ([]models.AlertLog, error) {
+	alertLog := alert.ToAlertLog()
+	if trace != nil {
+		name, method := alert.ResolveLocation()
+		trace.add(StageTransform, "location", method, name)
+	}
+	return []models.AlertLog{alertLog}, nil
+}
+
+// alertSummary resume o alerta no log
+func alertSummary(logs []models.AlertLog) string {
+	return fmt.Sprintf("event=%s, severity=%s, location=%s", logs[0].Event, logs[0].Severity, logs[0].Location)
+}
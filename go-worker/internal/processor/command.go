@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go-worker/internal/logging"
+)
+
+// Comandos operacionais aceitos pelo tipo command
+const (
+	// CommandReload recarrega a configuração, como o SIGHUP
+	CommandReload = "reload"
+	// CommandLogLevel altera o nível de log (args.level)
+	CommandLogLevel = "log-level"
+)
+
+// Command é um comando operacional enviado ao worker pela fila de comandos
+// (COMMAND_QUEUE). O destino da rota command o executa; sem destino
+// configurado a mensagem falha no envio.
+type Command struct {
+	Name string            `json:"command"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// commandArgs são os argumentos obrigatórios de cada comando, com a validação
+// do valor
+var commandArgs = map[string]map[string]func(string) error{
+	CommandReload: {},
+	CommandLogLevel: {
+		"level": func(v string) error {
+			_, err := logging.ParseLevel(v)
+			return err
+		},
+	},
+}
+
+// commandHandler monta o pipeline dos comandos. Ele não é registrado no
+// despacho: um comando na fila de mensagens é um tipo desconhecido e vai para
+// a quarentena sem ser executado.
+func (p *Processor) commandHandler() Handler {
+	return NewHandler(TypeCommand, RouteCommand, Pipeline[Command, Command]{
+		Decode:    p.decodeCommand,
+		Validate:  p.validateCommand,
+		Transform: p.transformCommand,
+		Summary:   commandSummary,
+	})
+}
+
+// ProcessCommand valida um comando recebido na fila de comandos e o entrega ao
+// destino da rota command, que o executa. O tipo declarado na mensagem é
+// ignorado.
+func (p *Processor) ProcessCommand(msg Message) error {
+	result, err := p.command.run(msg, nil)
+	if err != nil {
+		return err
+	}
+	sink, ok := p.sinks[RouteCommand]
+	if !ok {
+		return &StageError{Stage: StageSend, Err: fmt.Errorf("%w: %s", ErrNoSink, RouteCommand)}
+	}
+	for _, record := range result.records {
+		if err := sink.Send(record); err != nil {
+			return &StageError{Stage: StageSend, Err: fmt.Errorf("erro ao executar comando: %w", err)}
+		}
+	}
+	log.Printf("[INFO] Comando executado: %s", result.summary)
+	return nil
+}
+
+// decodeCommand deserializa o comando
+func (p *Processor) decodeCommand(msg Message, trace *Trace) (Command, error) {
+	var cmd Command
+	if err := json.Unmarshal(msg.Body, &cmd); err != nil {
+		trace.add(StageDecode, "json", "error", err.Error())
+		return Command{}, &StageError{Stage: StageDecode, Err: fmt.Errorf("erro ao deserializar comando: %w", err)}
+	}
+	cmd.Name = strings.ToLower(strings.TrimSpace(cmd.Name))
+	trace.add(StageDecode, "json", "ok", nil)
+	return cmd, nil
+}
+
+// validateCommand rejeita comandos desconhecidos e argumentos ausentes ou
+// inválidos, antes de qualquer execução
+func (p *Processor) validateCommand(cmd *Command, trace *Trace) error {
+	args, ok := commandArgs[cmd.Name]
+	if !ok {
+		names := make([]string, 0, len(commandArgs))
+		for name := range commandArgs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		trace.add(StageValidate, "command", "fail", cmd.Name)
+		return &StageError{Stage: StageValidate, Err: fmt.Errorf("comando desconhecido %q (use %s)", cmd.Name, strings.Join(names, ", "))}
+	}
+	trace.add(StageValidate, "command", "pass", cmd.Name)
+
+	for name, check := range args {
+		value, ok := cmd.Args[name]
+		if !ok {
+			trace.add(StageValidate, "args."+name, "fail", nil)
+			return &StageError{Stage: StageValidate, Err: fmt.Errorf("comando %s: argumento %s ausente", cmd.Name, name)}
+		}
+		if err := check(value); err != nil {
+			trace.add(StageValidate, "args."+name, "fail", value)
+			return &StageError{Stage: StageValidate, Err: fmt.Errorf("comando %s: %w", cmd.Name, err)}
+		}
+		trace.add(StageValidate, "args."+name, "pass", value)
+	}
+	log.Printf("[INFO] Comando recebido: %s", cmd.Name)
+	return nil
+}
+
+// transformCommand entrega o comando validado ao destino da rota
+func (p *Processor) transformCommand(cmd *Command, _ Message, _ *Trace) ([]Command, error) {
+	return []Command{*cmd}, nil
+}
+
+// commandSummary resume o comando no log
+func commandSummary(cmds []Command) string {
+	return "command=" + cmds[0].Name
+}
@@ -0,0 +1,402 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-worker/internal/models"
+)
+
+// Tipos de mensagem registrados por NewProcessor
+const (
+	TypeObservation = "observation"
+	TypeForecast    = "forecast"
+	TypeAirQuality  = "air-quality"
+	TypeAlert       = "alert"
+	TypeCommand     = "command"
+)
+
+// Rotas dos destinos registrados por NewProcessor
+const (
+	RouteWeather    = "weather"
+	RouteAirQuality = "air-quality"
+	RouteAlert      = "alert"
+	RouteCommand    = "command"
+)
+
+// Formas de escolha do tipo da mensagem, registradas no trace
+const (
+	ResolvedByProperty = "type"
+	ResolvedByField    = "field"
+	ResolvedByBinding  = "binding"
+	ResolvedBySniffing = "sniff"
+	ResolvedByDefault  = "default"
+)
+
+var (
+	// ErrUnknownMessageType indica um tipo declarado sem handler registrado;
+	// a mensagem vai para a quarentena
+	ErrUnknownMessageType = errors.New("tipo de mensagem desconhecido")
+	// ErrNoSink indica uma rota sem destino configurado
+	ErrNoSink = errors.New("rota sem destino configurado")
+	// ErrNoForecast indica uma mensagem do tipo forecast sem previsões
+	ErrNoForecast = errors.New("mensagem do tipo forecast sem previsões")
+)
+
+// Pipeline são as etapas de um tipo de mensagem: Decode converte o corpo,
+// Validate rejeita valores inválidos antes de qualquer envio e Transform gera
+// os registros enviados à rota do handler. Erros sem etapa são marcados com a
+// etapa em que ocorreram.
+type Pipeline[T, R any] struct {
+	Decode func(msg Message, trace *Trace) (T, error)
+	// Validate é opcional
+	Validate  func(v *T, trace *Trace) error
+	Transform func(v *T, msg Message, trace *Trace) ([]R, error)
+	// Summary resume os registros no log de sucesso (opcional)
+	Summary func(records []R) string
+	// Commit registra o estado produzido por Transform para o registro i,
+	// como o histórico do QC; é chamado só depois do envio do registro
+	// (opcional)
+	Commit func(v *T, i int)
+}
+
+// Handler trata um tipo de mensagem. É criado por NewHandler e registrado com
+// Processor.Register.
+type Handler struct {
+	// Type é o nome do tipo na propriedade AMQP type, no campo type do corpo
+	// e nos bindings
+	Type string
+	// Aliases são outros nomes aceitos para o tipo
+	Aliases []string
+	// Route é a rota cujo destino recebe os registros
+	Route string
+	// Detect reconhece o tipo pelo corpo quando a mensagem não o declara
+	// (opcional)
+	Detect func(msg Message) bool
+
+	run func(msg Message, trace *Trace) (handled, error)
+}
+
+// handled é o resultado do pipeline de um handler
+type handled struct {
+	records []any
+	summary string
+	commit  func(i int)
+}
+
+// NewHandler cria o handler de um tipo de mensagem a partir das etapas tipadas
+func NewHandler[T, R any](messageType, route string, p Pipeline[T, R]) Handler {
+	return Handler{
+		Type:  messageType,
+		Route: route,
+		run: func(msg Message, trace *Trace) (handled, error) {
+			v, err := p.Decode(msg, trace)
+			if err != nil {
+				return handled{}, withStage(StageDecode, err)
+			}
+			if p.Validate != nil {
+				if err := p.Validate(&v, trace); err != nil {
+					return handled{}, withStage(StageValidate, err)
+				}
+			}
+			records, err := p.Transform(&v, msg, trace)
+			if err != nil {
+				return handled{}, withStage(StageTransform, err)
+			}
+
+			summary := fmt.Sprintf("registros=%d", len(records))
+			if p.Summary != nil && len(records) > 0 {
+				summary = p.Summary(records)
+			}
+			out := make([]any, len(records))
+			for i, r := range records {
+				out[i] = r
+			}
+			result := handled{records: out, summary: summary}
+			if p.Commit != nil {
+				result.commit = func(i int) { p.Commit(&v, i) }
+			}
+			return result, nil
+		},
+	}
+}
+
+// withStage marca a etapa de erros que ainda não a informam
+func withStage(stage string, err error) error {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return err
+	}
+	return &StageError{Stage: stage, Err: err}
+}
+
+// Sink recebe os registros de uma rota
+type Sink interface {
+	Send(record any) error
+}
+
+// SinkFunc adapta uma função para a interface Sink
+type SinkFunc func(record any) error
+
+func (f SinkFunc) Send(record any) error {
+	return f(record)
+}
+
+// DefaultSinks monta as rotas padrão sobre o destino: weather recebe os
+// WeatherLogs (observações e previsões) e air-quality e alert os registros de
+// qualidade do ar e os alertas, se o destino os aceitar. A rota command não
+// tem destino padrão: quem executa os comandos de ProcessCommand é o worker
+// (SetSink).
+func DefaultSinks(sender Sender) map[string]Sink {
+	return map[string]Sink{
+		RouteWeather: SinkFunc(func(record any) error {
+			weatherLog, ok := record.(models.WeatherLog)
+			if !ok {
+				return fmt.Errorf("rota %s: registro %T não suportado", RouteWeather, record)
+			}
+			return sender.SendWeatherLog(weatherLog)
+		}),
+		RouteAirQuality: SinkFunc(func(record any) error {
+			airQualityLog, ok := record.(models.AirQualityLog)
+			if !ok {
+				return fmt.Errorf("rota %s: registro %T não suportado", RouteAirQuality, record)
+			}
+			aqSender, ok := sender.(AirQualitySender)
+			if !ok {
+				return ErrAirQualityUnsupported
+			}
+			return aqSender.SendAirQualityLog(airQualityLog)
+		}),
+		RouteAlert: SinkFunc(func(record any) error {
+			alertLog, ok := record.(models.AlertLog)
+			if !ok {
+				return fmt.Errorf("rota %s: registro %T não suportado", RouteAlert, record)
+			}
+			alertSender, ok := sender.(AlertSender)
+			if !ok {
+				return ErrAlertUnsupported
+			}
+			return alertSender.SendAlertLog(alertLog)
+		}),
+	}
+}
+
+// Resolution descreve como o tipo da mensagem foi escolhido
+type Resolution struct {
+	Type string `json:"type,omitempty"`
+	By   string `json:"by"`
+	// Declared é o valor informado na propriedade, no campo ou no binding
+	Declared string `json:"declared,omitempty"`
+}
+
+// Dispatched é o resultado do pipeline do handler, ainda não enviado
+type Dispatched struct {
+	Resolution
+	Route   string
+	Records []any
+	summary string
+	commit  func(i int)
+}
+
+// Commit registra o estado das estações produzido pelo registro i (histórico
+// do QC, linhas de base e eventos de anomalia). Deve ser chamado depois do
+// envio bem-sucedido do registro, para que um registro não entregue não entre
+// no histórico; sem envio, logo após o Dispatch.
+func (d Dispatched) Commit(i int) {
+	if d.commit != nil && i >= 0 && i < len(d.Records) {
+		d.commit(i)
+	}
+}
+
+// CommitAll é Commit para todos os registros
+func (d Dispatched) CommitAll() {
+	for i := range d.Records {
+		d.Commit(i)
+	}
+}
+
+// Register adiciona o handler de um tipo de mensagem. Handlers com Detect são
+// testados na ordem de registro. Deve ser chamado antes do consumo.
+func (p *Processor) Register(h Handler) error {
+	if h.Type == "" || h.run == nil {
+		return fmt.Errorf("handler inválido: use NewHandler com o tipo da mensagem")
+	}
+	for _, name := range append([]string{h.Type}, h.Aliases...) {
+		if existing := p.lookup(name); existing != nil {
+			return fmt.Errorf("tipo %q já registrado por %s", name, existing.Type)
+		}
+	}
+	p.handlers = append(p.handlers, h)
+	return nil
+}
+
+// SetSink define o destino de uma rota
+func (p *Processor) SetSink(route string, s Sink) {
+	p.sinks[route] = s
+}
+
+// Types lista os tipos registrados
+func (p *Processor) Types() []string {
+	types := make([]string, 0, len(p.handlers))
+	for _, h := range p.handlers {
+		types = append(types, h.Type)
+	}
+	return types
+}
+
+// CheckBindings verifica se todos os tipos dos bindings estão registrados
+func (p *Processor) CheckBindings(bindings map[string]string) error {
+	keys := make([]string, 0, len(bindings))
+	for key := range bindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if p.lookup(bindings[key]) == nil {
+			return fmt.Errorf("binding %s: %w: %q", key, ErrUnknownMessageType, bindings[key])
+		}
+	}
+	return nil
+}
+
+// SetBindings associa routing keys a tipos de mensagem, usados quando a
+// mensagem não declara o tipo (nil remove os bindings)
+func (p *Processor) SetBindings(bindings map[string]string) {
+	p.bindings.Store(&bindings)
+}
+
+// lookup encontra o handler pelo tipo ou por um alias, sem diferenciar caixa
+// nem _ e -
+func (p *Processor) lookup(name string) *Handler {
+	name = normalizeType(name)
+	for i := range p.handlers {
+		h := &p.handlers[i]
+		if normalizeType(h.Type) == name {
+			return h
+		}
+		for _, alias := range h.Aliases {
+			if normalizeType(alias) == name {
+				return h
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeType(t string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(t)), "_", "-")
+}
+
+// resolve escolhe o handler: propriedade AMQP type, campo type do corpo,
+// binding da routing key, detecção pelo corpo e, por fim, observação. Um tipo
+// declarado sem handler é um erro da etapa dispatch.
+func (p *Processor) resolve(msg Message) (*Handler, Resolution, error) {
+	declared := func(by, value string) (*Handler, Resolution, error) {
+		res := Resolution{By: by, Declared: value}
+		h := p.lookup(value)
+		if h == nil {
+			return nil, res, &StageError{Stage: StageDispatch, Err: fmt.Errorf("%w: %q", ErrUnknownMessageType, value)}
+		}
+		res.Type = h.Type
+		return h, res, nil
+	}
+
+	if msg.Type != "" {
+		return declared(ResolvedByProperty, msg.Type)
+	}
+	if t := typeField(msg.Body); t != "" {
+		return declared(ResolvedByField, t)
+	}
+	if bindings := p.bindings.Load(); bindings != nil && msg.RoutingKey != "" {
+		if t, ok := (*bindings)[msg.RoutingKey]; ok {
+			return declared(ResolvedByBinding, t)
+		}
+	}
+	for i := range p.handlers {
+		if h := &p.handlers[i]; h.Detect != nil && h.Detect(msg) {
+			return h, Resolution{Type: h.Type, By: ResolvedBySniffing}, nil
+		}
+	}
+	if h := p.lookup(TypeObservation); h != nil {
+		return h, Resolution{Type: h.Type, By: ResolvedByDefault}, nil
+	}
+	return nil, Resolution{By: ResolvedByDefault}, &StageError{Stage: StageDispatch, Err: fmt.Errorf("%w: mensagem sem tipo", ErrUnknownMessageType)}
+}
+
+// typeField lê o campo type de primeiro nível de um corpo JSON
+func typeField(body []byte) string {
+	var fields struct {
+		Type json.RawMessage `json:"type"`
+	}
+	if json.Unmarshal(body, &fields) != nil || len(fields.Type) == 0 {
+		return ""
+	}
+	var t string
+	if json.Unmarshal(fields.Type, &t) != nil {
+		return ""
+	}
+	return t
+}
+
+// Dispatch escolhe o handler pelo tipo da mensagem e executa o seu pipeline
+// sem enviar os registros nem registrar o estado das estações (Dispatched.Commit)
+func (p *Processor) Dispatch(msg Message) (Dispatched, error) {
+	return p.dispatch(msg, nil)
+}
+
+func (p *Processor) dispatch(msg Message, trace *Trace) (Dispatched, error) {
+	h, res, err := p.resolve(msg)
+	if err != nil {
+		trace.add(StageDispatch, "type", "unknown", res)
+		return Dispatched{Resolution: res}, err
+	}
+	trace.add(StageDispatch, "type", res.Type, res)
+	if trace != nil {
+		trace.Type = res.Type
+	}
+
+	result, err := h.run(msg, trace)
+	if err != nil {
+		return Dispatched{Resolution: res, Route: h.Route}, err
+	}
+	return Dispatched{Resolution: res, Route: h.Route, Records: result.records, summary: result.summary, commit: result.commit}, nil
+}
+
+// defaultHandlers são os tipos suportados pelo worker. Observações aceitam
+// também os nomes dos adaptadores, que escolhem o provedor pela propriedade
+// type. Alertas não são detectados pelo corpo: precisam ser declarados.
+// Comandos não passam pelo despacho (ProcessCommand).
+func (p *Processor) defaultHandlers() []Handler {
+	weather := Pipeline[weatherBatch, models.WeatherLog]{
+		Decode:    p.decodeWeather,
+		Validate:  p.validateWeather,
+		Transform: p.transformWeather,
+		Summary:   weatherSummary,
+		Commit:    p.commitWeather,
+	}
+	observation := NewHandler(TypeObservation, RouteWeather, weather)
+	observation.Aliases = append([]string{"weather"}, p.adapters.Load().Names()...)
+
+	weather.Decode = p.decodeForecast
+	forecast := NewHandler(TypeForecast, RouteWeather, weather)
+
+	airQuality := NewHandler(TypeAirQuality, RouteAirQuality, Pipeline[models.AirQualityMessage, models.AirQualityLog]{
+		Decode:    p.decodeAirQuality,
+		Validate:  p.validateAirQuality,
+		Transform: p.transformAirQualityLog,
+		Summary:   airQualitySummary,
+	})
+	airQuality.Detect = isAirQuality
+
+	alert := NewHandler(TypeAlert, RouteAlert, Pipeline[models.AlertMessage, models.AlertLog]{
+		Decode:    p.decodeAlert,
+		Validate:  p.validateAlert,
+		Transform: p.transformAlert,
+		Summary:   alertSummary,
+	})
+
+	return []Handler{observation, forecast, airQuality, alert}
+}
@@ -2,50 +2,608 @@ package processor
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"go-worker/internal/client"
+	"go-worker/internal/adapters"
+	"go-worker/internal/anomaly"
 	"go-worker/internal/models"
+	"go-worker/internal/normalize"
+	"go-worker/internal/qc"
+	"go-worker/internal/rules"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-// Processor processa mensagens meteorológicas
+// Etapas do pipeline, usadas para identificar onde uma mensagem falhou
+const (
+	StageDispatch  = "dispatch"
+	StageDecode    = "decode"
+	StageNormalize = "normalize"
+	StageValidate  = "validate"
+	StageTransform = "transform"
+	StageEnrich    = "enrich"
+	StageSend      = "send"
+)
+
+// HeaderLanguage é o header da mensagem que escolhe o idioma da descrição do
+// tempo, sobrepondo o idioma configurado
+const HeaderLanguage = "x-language"
+
+// HeaderSchemaVersion é o header com a versão do schema da mensagem canônica,
+// alternativo ao campo schema_version
+const HeaderSchemaVersion = "x-schema-version"
+
+// Message é uma mensagem recebida com os headers do transporte. Type escolhe
+// o tipo da mensagem ou, nas observações, o adaptador do provedor, assim como
+// ContentType; RoutingKey escolhe o tipo pelos bindings configurados.
+type Message struct {
+	Body        []byte
+	Headers     map[string]string
+	Type        string
+	ContentType string
+	RoutingKey  string
+}
+
+// Sender é o destino dos dados processados (API NestJS, stdout, ...)
+type Sender interface {
+	SendWeatherLog(models.WeatherLog) error
+}
+
+// Enricher complementa o WeatherLog gerado a partir da mensagem
+type Enricher interface {
+	Enrich(msg *models.WeatherMessage, weatherLog *models.WeatherLog) error
+}
+
+// AnomalyPublisher publica os eventos de anomalia detectados
+type AnomalyPublisher interface {
+	PublishAnomaly(anomaly.Event) error
+}
+
+// StageError indica em qual etapa do pipeline a mensagem falhou
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Headers com o detalhe da falha nas mensagens enviadas à dead-letter queue
+const (
+	HeaderErrorStage      = "x-error-stage"
+	HeaderValidationError = "x-validation-errors"
+)
+
+// DeadLetterHeaders descreve a etapa e, na validação, cada violação em JSON
+// (field, value, rule, message)
+func (e *StageError) DeadLetterHeaders() map[string]string {
+	headers := map[string]string{HeaderErrorStage: e.Stage}
+	var verr *models.ValidationError
+	if errors.As(e.Err, &verr) {
+		if data, err := json.Marshal(verr.Violations); err == nil {
+			headers[HeaderValidationError] = string(data)
+		}
+	}
+	return headers
+}
+
+// Quarantine indica as mensagens que o worker não sabe tratar (tipo sem
+// handler), enviadas à quarentena em vez da dead-letter queue
+func (e *StageError) Quarantine() bool {
+	return e.Stage == StageDispatch
+}
+
+// Processor processa mensagens meteorológicas e os demais tipos registrados
 type Processor struct {
-	apiClient *client.APIClient
+	adapters  atomic.Pointer[adapters.Registry]
+	canonical adapters.Canonical // protegido por canonMu
+	canonMu   sync.Mutex
+	lenient   atomic.Bool
+	handlers  []Handler
+	command   Handler
+	bindings  atomic.Pointer[map[string]string]
+	sinks     map[string]Sink
+	enrichers []Enricher
+	language  atomic.Value
+	rules     atomic.Pointer[rules.RuleSet]
+	qc        atomic.Pointer[qc.Checker]
+	anomalies atomic.Pointer[anomaly.Detector]
+	publisher AnomalyPublisher
+}
+
+// NewProcessor cria uma nova instância do processador, com os tipos
+// observation, forecast e air-quality e as rotas padrão sobre apiClient
+func NewProcessor(apiClient Sender) *Processor {
+	p := &Processor{
+		sinks: DefaultSinks(apiClient),
+	}
+	p.adapters.Store(adapters.Default())
+	p.language.Store(models.DefaultLanguage)
+	p.handlers = p.defaultHandlers()
+	p.command = p.commandHandler()
+	return p
+}
+
+// SetLanguage altera o idioma padrão das descrições do tempo
+func (p *Processor) SetLanguage(lang string) error {
+	normalized, ok := models.NormalizeLanguage(lang)
+	if !ok {
+		return fmt.Errorf("idioma não suportado: %q", lang)
+	}
+	p.language.Store(normalized)
+	return nil
+}
+
+// SetRules substitui as regras de qualidade configuradas (nil desativa)
+func (p *Processor) SetRules(rs *rules.RuleSet) {
+	p.rules.Store(rs)
+}
+
+// SetQualityControl liga o controle de qualidade temporal (nil desliga)
+func (p *Processor) SetQualityControl(checker *qc.Checker) {
+	p.qc.Store(checker)
+}
+
+// SetStrictSchema liga o modo estrito da mensagem canônica: campos
+// desconhecidos e campos obrigatórios ausentes falham no decode, em vez de
+// chegarem à validação com o valor zero
+func (p *Processor) SetStrictSchema(strict bool) {
+	p.canonMu.Lock()
+	defer p.canonMu.Unlock()
+	p.canonical.Strict = strict
+	p.adapters.Store(adapters.DefaultWith(p.canonical))
+}
+
+// SetLenientNormalization liga a normalização tolerante: defeitos conhecidos
+// dos produtores são corrigidos antes da validação e marcados em repairs
+func (p *Processor) SetLenientNormalization(lenient bool) {
+	p.canonMu.Lock()
+	defer p.canonMu.Unlock()
+	p.canonical.Lenient = lenient
+	p.lenient.Store(lenient)
+	p.adapters.Store(adapters.DefaultWith(p.canonical))
+}
+
+// SetAnomalyDetector liga a detecção de anomalias (nil desliga)
+func (p *Processor) SetAnomalyDetector(d *anomaly.Detector) {
+	p.anomalies.Store(d)
+}
+
+// SetAnomalyPublisher define para onde vão os eventos de anomalia; sem
+// publisher eles são apenas registrados no log
+func (p *Processor) SetAnomalyPublisher(pub AnomalyPublisher) {
+	p.publisher = pub
 }
 
-// NewProcessor cria uma nova instância do processador
-func NewProcessor(apiClient *client.APIClient) *Processor {
-	return &Processor{
-		apiClient: apiClient,
+// languageFor escolhe o idioma da mensagem: header válido ou o configurado
+func (p *Processor) languageFor(msg Message) string {
+	if lang, ok := models.NormalizeLanguage(msg.Headers[HeaderLanguage]); ok {
+		return lang
 	}
+	return p.language.Load().(string)
+}
+
+// AddEnricher adiciona um enriquecimento, executado na ordem de registro
+func (p *Processor) AddEnricher(e Enricher) {
+	p.enrichers = append(p.enrichers, e)
 }
 
 // Process processa uma mensagem do RabbitMQ
 func (p *Processor) Process(messageBody []byte) error {
-	// Deserializa a mensagem
-	var weatherMsg models.WeatherMessage
-	if err := json.Unmarshal(messageBody, &weatherMsg); err != nil {
-		return fmt.Errorf("erro ao deserializar mensagem: %w", err)
+	return p.ProcessMessage(Message{Body: messageBody})
+}
+
+// ProcessMessage processa uma mensagem considerando os seus headers. O tipo
+// da mensagem escolhe o handler (ver Register) e a rota do destino. Uma
+// mensagem com previsões gera vários registros; todos são validados antes do
+// primeiro envio. Os registros são enviados em ordem e o primeiro erro de
+// envio interrompe a mensagem: os anteriores já foram entregues e serão
+// reenviados se ela for reprocessada (entrega pelo menos uma vez).
+func (p *Processor) ProcessMessage(msg Message) error {
+	dispatched, err := p.dispatch(msg, nil)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("[INFO] Mensagem recebida: location=%.2f,%.2f, temperature=%.1f, humidity=%.1f",
-		weatherMsg.Location.Latitude, weatherMsg.Location.Longitude,
-		weatherMsg.Current.Temperature, weatherMsg.Current.Humidity)
+	sink, ok := p.sinks[dispatched.Route]
+	if !ok {
+		return &StageError{Stage: StageSend, Err: fmt.Errorf("%w: %s", ErrNoSink, dispatched.Route)}
+	}
+	for i, record := range dispatched.Records {
+		if err := sink.Send(record); err != nil {
+			return &StageError{Stage: StageSend, Err: fmt.Errorf("erro ao enviar para API: %w", err)}
+		}
+		dispatched.Commit(i)
+	}
+
+	log.Printf("[INFO] Mensagem processada com sucesso: tipo=%s, %s", dispatched.Type, dispatched.summary)
+	return nil
+}
+
+// Transform deserializa, valida, transforma e enriquece uma mensagem sem
+// enviá-la, permitindo executar o pipeline sem broker. Mensagens com
+// previsões geram vários registros; use TransformAll.
+func (p *Processor) Transform(messageBody []byte) (models.WeatherLog, error) {
+	return p.TransformMessage(Message{Body: messageBody})
+}
+
+// TransformMessage é Transform com as propriedades do transporte
+func (p *Processor) TransformMessage(msg Message) (models.WeatherLog, error) {
+	weatherLogs, err := p.TransformAll(msg)
+	if err != nil {
+		return models.WeatherLog{}, err
+	}
+	if len(weatherLogs) != 1 {
+		return models.WeatherLog{}, fmt.Errorf("a mensagem gera %d registros; use TransformAll", len(weatherLogs))
+	}
+	return weatherLogs[0], nil
+}
+
+// TransformAll é TransformMessage para mensagens que geram vários registros
+// (observação e previsões), na ordem de WeatherMessage.Expand. A mensagem é
+// tratada como meteorológica, qualquer que seja o tipo declarado.
+func (p *Processor) TransformAll(msg Message) ([]models.WeatherLog, error) {
+	batch, err := p.decodeWeather(msg, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validateWeather(&batch, nil); err != nil {
+		return nil, err
+	}
+	weatherLogs, err := p.transformWeather(&batch, msg, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range weatherLogs {
+		p.commitWeather(&batch, i)
+	}
+	return weatherLogs, nil
+}
+
+// weatherBatch são os registros de uma mensagem meteorológica (observação e
+// previsões) com o resultado das regras configuradas de cada um
+type weatherBatch struct {
+	entries []models.WeatherMessage
+	quality []rules.Result
+	// repairs são as marcações da normalização da mensagem, antes da expansão
+	repairs []string
+	// pending é o estado das estações de cada registro, registrado depois do
+	// envio por commitWeather
+	pending []pendingState
+}
+
+// pendingState é o estado das estações produzido pela transformação de um
+// registro, guardado com o controle e o detector que o produziram
+type pendingState struct {
+	checker *qc.Checker
+	qc      qc.Pending
+
+	detector *anomaly.Detector
+	anomaly  anomaly.Pending
+	// events são publicados quando a leitura entra na linha de base
+	events []anomaly.Event
+}
+
+// decodeWeather deserializa a mensagem com o adaptador do provedor e separa
+// observação e previsões em registros independentes; com trace não nil,
+// registra cada decisão
+func (p *Processor) decodeWeather(msg Message, trace *Trace) (weatherBatch, error) {
+	params := map[string]string{adapters.ParamSchemaVersion: msg.Headers[HeaderSchemaVersion]}
+	decoded, selection, err := p.adapters.Load().DecodeWith(msg.Body, msg.Type, msg.ContentType, params)
+	trace.add(StageDecode, "adapter", selection.Adapter, selection)
+	if err != nil {
+		trace.add(StageDecode, "json", "error", err.Error())
+		return weatherBatch{}, &StageError{Stage: StageDecode, Err: fmt.Errorf("erro ao deserializar mensagem: %w", err)}
+	}
+	if decoded.SchemaVersion != 0 {
+		trace.add(StageDecode, "schema", fmt.Sprintf("v%d", decoded.SchemaVersion), nil)
+	}
+	if p.lenient.Load() {
+		normalizeMessage(&decoded, trace)
+	}
+	if trace != nil {
+		trace.Decoded = &decoded
+	}
+	trace.add(StageDecode, "json", "ok", nil)
+
+	entries, err := decoded.Expand()
+	if err != nil {
+		trace.add(StageValidate, "forecast", "error", err.Error())
+		return weatherBatch{}, &StageError{Stage: StageValidate, Err: err}
+	}
+	if decoded.HasForecast() {
+		trace.add(StageDecode, "expand", fmt.Sprintf("%d", len(entries)), expansionSummary(entries))
+		log.Printf("[INFO] Mensagem com previsões recebida: location=%.2f,%.2f, registros=%d",
+			decoded.Location.Latitude, decoded.Location.Longitude, len(entries))
+	}
+	return weatherBatch{entries: entries, repairs: decoded.Repairs}, nil
+}
+
+// decodeForecast é decodeWeather para o tipo forecast, que exige previsões
+func (p *Processor) decodeForecast(msg Message, trace *Trace) (weatherBatch, error) {
+	batch, err := p.decodeWeather(msg, trace)
+	if err != nil {
+		return weatherBatch{}, err
+	}
+	for _, e := range batch.entries {
+		if e.Kind == models.KindForecast {
+			return batch, nil
+		}
+	}
+	trace.add(StageValidate, "forecast", "missing", nil)
+	return weatherBatch{}, &StageError{Stage: StageValidate, Err: ErrNoForecast}
+}
+
+// expansionSummary conta os registros por tipo e período para o trace
+func expansionSummary(entries []models.WeatherMessage) map[string]int {
+	summary := make(map[string]int)
+	for _, e := range entries {
+		key := e.Kind
+		if e.Period != "" {
+			key = e.Period
+		}
+		summary[key]++
+	}
+	return summary
+}
+
+// validateWeather normaliza as unidades, aplica as regras e valida cada
+// registro; um registro inválido rejeita a mensagem inteira
+func (p *Processor) validateWeather(batch *weatherBatch, trace *Trace) error {
+	if p.lenient.Load() {
+		normalizeEntries(batch, trace)
+	}
+	batch.quality = make([]rules.Result, len(batch.entries))
+	for i := range batch.entries {
+		quality, err := p.validateEntry(&batch.entries[i], trace)
+		if err != nil {
+			return forecastContext(&batch.entries[i], err)
+		}
+		batch.quality[i] = quality
+	}
+	return nil
+}
+
+// normalizeMessage registra os reparos do documento, feitos pelo adaptador
+// canonical, e corrige a mensagem antes da expansão
+func normalizeMessage(w *models.WeatherMessage, trace *Trace) {
+	for _, flag := range w.Repairs {
+		kind, field := normalize.ParseFlag(flag)
+		trace.add(StageNormalize, field, kind, nil)
+	}
+	traceRepairs(trace, normalize.Message(w))
+}
+
+// normalizeEntries corrige cada registro e contabiliza os reparos da
+// mensagem; o explain não altera as métricas
+func normalizeEntries(batch *weatherBatch, trace *Trace) {
+	repairs := normalize.Entries(batch.entries)
+	traceRepairs(trace, repairs)
+	flags := append(append([]string(nil), batch.repairs...), normalize.Flags(repairs)...)
+	if len(flags) == 0 || len(batch.entries) == 0 {
+		return
+	}
+	source := batch.entries[0].Source
+	log.Printf("[WARN] Payload corrigido pela normalização: source=%s, reparos=%s", source, strings.Join(flags, ", "))
+	if trace == nil {
+		normalize.Count(source, flags)
+	}
+}
+
+func traceRepairs(trace *Trace, repairs []normalize.Repair) {
+	for _, r := range repairs {
+		trace.add(StageNormalize, r.Field, r.Kind, r)
+	}
+}
+
+// transformWeather gera o WeatherLog de cada registro validado
+func (p *Processor) transformWeather(batch *weatherBatch, msg Message, trace *Trace) ([]models.WeatherLog, error) {
+	lang := p.languageFor(msg)
+	weatherLogs := make([]models.WeatherLog, 0, len(batch.entries))
+	batch.pending = make([]pendingState, 0, len(batch.entries))
+	for i := range batch.entries {
+		weatherLog, pending, err := p.transformEntry(&batch.entries[i], batch.quality[i], lang, trace)
+		if err != nil {
+			return nil, forecastContext(&batch.entries[i], err)
+		}
+		weatherLogs = append(weatherLogs, weatherLog)
+		batch.pending = append(batch.pending, pending)
+	}
+	return weatherLogs, nil
+}
+
+// commitWeather registra o estado das estações do registro i, depois do seu
+// envio
+func (p *Processor) commitWeather(batch *weatherBatch, i int) {
+	if i >= len(batch.pending) {
+		return
+	}
+	s := batch.pending[i]
+	if s.checker != nil {
+		s.checker.Commit(s.qc)
+	}
+	if s.detector != nil && s.detector.Commit(s.anomaly) {
+		p.publishAnomalies(s.events)
+	}
+}
+
+// forecastContext identifica a previsão no erro de um registro
+func forecastContext(entry *models.WeatherMessage, err error) error {
+	if stageErr, ok := err.(*StageError); ok && entry.Kind == models.KindForecast {
+		stageErr.Err = fmt.Errorf("previsão %s de %s: %w", entry.Period, entry.Timestamp, stageErr.Err)
+	}
+	return err
+}
+
+// weatherSummary resume os registros meteorológicos no log
+func weatherSummary(weatherLogs []models.WeatherLog) string {
+	return fmt.Sprintf("location=%s, registros=%d", weatherLogs[0].Location, len(weatherLogs))
+}
+
+// validateEntry normaliza as unidades de um registro, aplica as regras
+// configuradas e as embutidas
+func (p *Processor) validateEntry(weatherMsg *models.WeatherMessage, trace *Trace) (rules.Result, error) {
+	// Converte para as unidades do worker antes de validar
+	conversions, err := weatherMsg.NormalizeUnits()
+	if err != nil {
+		trace.add(StageValidate, "units", "error", err.Error())
+		return rules.Result{}, &StageError{Stage: StageValidate, Err: fmt.Errorf("unidade inválida: %w", err)}
+	}
+	if len(conversions) > 0 {
+		trace.add(StageValidate, "units", "converted", conversions)
+	}
+
+	if weatherMsg.Kind != models.KindForecast {
+		log.Printf("[INFO] Mensagem recebida: location=%.2f,%.2f, temperature=%.1f, humidity=%.1f",
+			weatherMsg.Location.Latitude, weatherMsg.Location.Longitude,
+			weatherMsg.Current.Temperature, weatherMsg.Current.Humidity)
+	}
+
+	// Aplica as regras configuradas antes das embutidas, para que um clamp
+	// possa trazer o valor de volta à faixa aceita
+	quality := p.rules.Load().Apply(weatherMsg)
+	if trace != nil {
+		for _, c := range quality.Checks {
+			trace.add(StageValidate, c.Field, ruleDecision(c), c)
+		}
+	}
 
 	// Valida os dados
-	if err := weatherMsg.Validate(); err != nil {
-		return fmt.Errorf("validação falhou: %w", err)
+	if trace != nil {
+		for _, r := range weatherMsg.CheckRules() {
+			decision := "pass"
+			if !r.Passed {
+				decision = "fail"
+			}
+			trace.add(StageValidate, r.Field, decision, r)
+		}
+	}
+	if err := validate(weatherMsg, quality.Violations); err != nil {
+		return rules.Result{}, &StageError{Stage: StageValidate, Err: fmt.Errorf("validação falhou: %w", err)}
 	}
+	return quality, nil
+}
+
+// transformEntry leva um registro validado ao WeatherLog enriquecido e
+// retorna o estado das estações a registrar depois do envio
+func (p *Processor) transformEntry(weatherMsg *models.WeatherMessage, quality rules.Result, lang string, trace *Trace) (models.WeatherLog, pendingState, error) {
+	forecast := weatherMsg.Kind == models.KindForecast
+	var pending pendingState
 
 	// Transforma para WeatherLog
-	weatherLog := weatherMsg.ToWeatherLog()
+	weatherLog := weatherMsg.ToLocalizedWeatherLog(lang)
+	weatherLog.QualityFlags = quality.Flags
 
-	// Envia para a API NestJS
-	if err := p.apiClient.SendWeatherLog(weatherLog); err != nil {
-		return fmt.Errorf("erro ao enviar para API: %w", err)
+	// Controle de qualidade temporal; a observação só entra no histórico da
+	// estação depois do envio, e as previsões não entram
+	if checker := p.qc.Load(); checker != nil && !forecast {
+		if observed, err := weatherMsg.ObservedAt(); err == nil {
+			result, qcPending := checker.Check(weatherMsg, observed)
+			pending.checker, pending.qc = checker, qcPending
+			if trace != nil {
+				for _, c := range result.Checks {
+					decision := "pass"
+					if !c.Passed {
+						decision = "flag"
+					}
+					trace.add(StageTransform, "qc."+c.Test+"."+c.Variable, decision, c)
+				}
+			}
+			weatherLog.QualityFlags = append(weatherLog.QualityFlags, result.Flags...)
+			weatherLog.QualityScore = result.Score
+		}
 	}
 
-	log.Printf("[INFO] Mensagem processada com sucesso: location=%s", weatherMsg.Location)
-	return nil
+	// Anomalias em relação à linha de base da estação; a leitura só entra na
+	// linha de base, e os eventos só são publicados, depois do envio
+	if detector := p.anomalies.Load(); detector != nil && !forecast {
+		if observed, err := weatherMsg.ObservedAt(); err == nil {
+			result, anomalyPending := detector.Score(weatherMsg, observed)
+			pending.detector, pending.anomaly, pending.events = detector, anomalyPending, result.Events
+			if trace != nil {
+				for _, s := range result.Scores {
+					decision := "normal"
+					switch {
+					case s.Anomalous:
+						decision = "flag"
+					case s.WarmingUp:
+						decision = "warming-up"
+					}
+					trace.add(StageTransform, "anomaly."+s.Variable, decision, s)
+				}
+			}
+			weatherLog.QualityFlags = append(weatherLog.QualityFlags, result.Flags...)
+		}
+	}
+	if trace != nil {
+		name, method := weatherMsg.ResolveLocation()
+		trace.add(StageTransform, "location", method, name)
+		trace.add(StageTransform, "description", weatherLog.Description, weatherMsg.Condition(lang))
+		pressure := weatherMsg.Pressure()
+		decision := pressure.ElevationSource
+		if pressure.SeaLevel == nil {
+			decision = "unavailable"
+		}
+		trace.add(StageTransform, "pressure", decision, pressure)
+	}
+
+	// Aplica os enriquecimentos configurados
+	for _, e := range p.enrichers {
+		if err := e.Enrich(weatherMsg, &weatherLog); err != nil {
+			trace.add(StageEnrich, fmt.Sprintf("%T", e), "error", err.Error())
+			return models.WeatherLog{}, pendingState{}, &StageError{Stage: StageEnrich, Err: fmt.Errorf("erro ao enriquecer mensagem: %w", err)}
+		}
+		trace.add(StageEnrich, fmt.Sprintf("%T", e), "applied", nil)
+	}
+
+	return weatherLog, pending, nil
 }
 
+// publishAnomalies registra e publica os eventos; falhas na publicação não
+// impedem o processamento da mensagem
+func (p *Processor) publishAnomalies(events []anomaly.Event) {
+	for _, e := range events {
+		log.Printf("[WARN] Anomalia em %s: %s=%.2f (esperado %.2f a %.2f, z=%.2f, hora %d)",
+			e.Station, e.Variable, e.Value, e.Expected[0], e.Expected[1], e.ZScore, e.Hour)
+		if p.publisher == nil {
+			continue
+		}
+		if err := p.publisher.PublishAnomaly(e); err != nil {
+			log.Printf("[ERROR] Erro ao publicar anomalia de %s: %v", e.Station, err)
+		}
+	}
+}
+
+// validate junta as violações das regras configuradas às das regras embutidas
+func validate(msg *models.WeatherMessage, violations []models.Violation) error {
+	err := msg.Validate()
+	if len(violations) == 0 {
+		return err
+	}
+	verr := &models.ValidationError{}
+	if err != nil && !errors.As(err, &verr) {
+		return err
+	}
+	verr.Violations = append(verr.Violations, violations...)
+	return verr
+}
+
+// ruleDecision resume uma regra configurada para o trace
+func ruleDecision(c rules.Check) string {
+	switch {
+	case c.Skipped:
+		return "skip"
+	case c.Passed:
+		return "pass"
+	case c.Action == rules.ActionReject:
+		return "fail"
+	}
+	return c.Action
+}
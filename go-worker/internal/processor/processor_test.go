@@ -2,10 +2,20 @@ package processor
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"go-worker/internal/anomaly"
+	"go-worker/internal/client"
+	"go-worker/internal/geo"
 	"go-worker/internal/models"
+	"go-worker/internal/qc"
+	"go-worker/internal/rules"
+	"go-worker/internal/schema"
 )
 
 // MockAPIClient simula o cliente API para testes
@@ -32,11 +42,16 @@ func TestProcessor_Process(t *testing.T) {
 			name: "valid message",
 			message: func() []byte {
 				msg := models.WeatherMessage{
-					Location:    "São Paulo, BR",
-					Temperature: 25.5,
-					Humidity:    65.0,
-					Timestamp:   time.Now(),
-					Source:      "OpenWeatherMap",
+					Timestamp: time.Now().UTC().Format(time.RFC3339),
+					Location: models.WeatherLocation{
+						Latitude:  -23.5505,
+						Longitude: -46.6333,
+						Timezone:  "America/Sao_Paulo",
+					},
+					Current: models.WeatherCurrent{
+						Temperature: 25.5,
+						Humidity:    65.0,
+					},
 				}
 				b, _ := json.Marshal(msg)
 				return b
@@ -53,13 +68,18 @@ func TestProcessor_Process(t *testing.T) {
 			errString: "erro ao deserializar mensagem",
 		},
 		{
-			name: "validation failed - missing location",
+			name: "validation failed - invalid location",
 			message: func() []byte {
 				msg := models.WeatherMessage{
-					Temperature: 25.5,
-					Humidity:    65.0,
-					Timestamp:   time.Now(),
-					Source:      "OpenWeatherMap",
+					Timestamp: time.Now().UTC().Format(time.RFC3339),
+					Location: models.WeatherLocation{
+						Latitude:  -123.5505,
+						Longitude: -46.6333,
+					},
+					Current: models.WeatherCurrent{
+						Temperature: 25.5,
+						Humidity:    65.0,
+					},
 				}
 				b, _ := json.Marshal(msg)
 				return b
@@ -67,6 +87,22 @@ func TestProcessor_Process(t *testing.T) {
 			wantErr:   true,
 			errString: "validação falhou",
 		},
+		{
+			name:    "fahrenheit normalized before validation",
+			message: []byte(`{"timestamp":"2025-06-15T14:30:00","location":{"latitude":-23.5505,"longitude":-46.6333},"current":{"temperature":104,"humidity":40,"wind_speed":10},"units":{"temperature":"°F","wind_speed":"mph"}}`),
+			sendFunc: func(log models.WeatherLog) error {
+				if log.Temperature != 40 || log.WindSpeed == nil || *log.WindSpeed != 16.093 {
+					return errors.New("unexpected units in payload")
+				}
+				return nil
+			},
+		},
+		{
+			name:      "unknown unit",
+			message:   []byte(`{"timestamp":"2025-06-15T14:30:00","location":{"latitude":-23.5505,"longitude":-46.6333},"current":{"temperature":25,"humidity":40},"units":{"temperature":"rankine"}}`),
+			wantErr:   true,
+			errString: "unidade inválida",
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,7 +114,849 @@ func TestProcessor_Process(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errString) {
+				t.Errorf("Process() error = %v, want containing %q", err, tt.errString)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessMessage_Adapters(t *testing.T) {
+	owm := []byte(`{"coord":{"lon":-46.6333,"lat":-23.5505},"weather":[{"id":802}],"main":{"temp":298.65,"pressure":1015,"humidity":65},"wind":{"speed":3,"deg":135},"dt":1749997800}`)
+
+	tests := []struct {
+		name        string
+		msg         Message
+		wantSource  string
+		wantTemp    float64
+		wantErrPart string
+	}{
+		{
+			name:       "sniffed openweathermap in kelvin",
+			msg:        Message{Body: owm},
+			wantSource: "openweathermap",
+			wantTemp:   25.5,
+		},
+		{
+			name:       "content type selects units",
+			msg:        Message{Body: []byte(strings.Replace(string(owm), "298.65", "25.5", 1)), ContentType: "application/vnd.openweathermap+json; units=metric"},
+			wantSource: "openweathermap",
+			wantTemp:   25.5,
+		},
+		{
+			name:        "type forces adapter",
+			msg:         Message{Body: owm, Type: "weatherapi"},
+			wantErrPart: "weatherapi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.WeatherLog
+			proc := NewProcessor(&MockAPIClient{SendFunc: func(l models.WeatherLog) error {
+				got = l
+				return nil
+			}})
+
+			err := proc.ProcessMessage(tt.msg)
+			if tt.wantErrPart != "" {
+				var stageErr *StageError
+				if !errors.As(err, &stageErr) || stageErr.Stage != StageDecode || !strings.Contains(err.Error(), tt.wantErrPart) {
+					t.Fatalf("ProcessMessage() error = %v, want decode error containing %q", err, tt.wantErrPart)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessMessage() error = %v", err)
+			}
+			if got.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", got.Source, tt.wantSource)
+			}
+			if got.Temperature != tt.wantTemp {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.wantTemp)
+			}
+			if got.WindSpeed == nil || *got.WindSpeed != 10.8 {
+				t.Errorf("WindSpeed = %v, want 10.8", got.WindSpeed)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessMessage_Schema(t *testing.T) {
+	legacy := []byte(`{"timestamp":"` + time.Now().UTC().Format("2006-01-02T15:04:05") + `","extra":1,"location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":25.3,"humidity":65}}`)
+
+	tests := []struct {
+		name      string
+		strict    bool
+		headers   map[string]string
+		wantStage string
+		wantErr   error
+	}{
+		{name: "legacy upcast", headers: nil},
+		{name: "header version", headers: map[string]string{HeaderSchemaVersion: "1"}},
+		{name: "future version in header", headers: map[string]string{HeaderSchemaVersion: "3"}, wantStage: StageDecode, wantErr: schema.ErrUnsupportedVersion},
+		{name: "strict rejects unknown field", strict: true, wantStage: StageDecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := NewProcessor(&MockAPIClient{})
+			proc.SetStrictSchema(tt.strict)
+
+			err := proc.ProcessMessage(Message{Body: legacy, Headers: tt.headers})
+			if tt.wantStage == "" {
+				if err != nil {
+					t.Fatalf("ProcessMessage() error = %v", err)
+				}
+				return
+			}
+			var stageErr *StageError
+			if !errors.As(err, &stageErr) || stageErr.Stage != tt.wantStage {
+				t.Fatalf("ProcessMessage() error = %v, want %s error", err, tt.wantStage)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ProcessMessage() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessor_LenientNormalization(t *testing.T) {
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+	localTime := time.Now().In(saoPaulo).Format("2006-01-02T15:04")
+	body := []byte(`{"schema_version":2,"timestamp":"","source":"station",` +
+		`"location":{"latitude":"-46,6333","longitude":"-23,5505","timezone":"America/Sao_Paulo"},` +
+		`"current":{"temperature":"25,3","humidity":0.65,"wind_speed":"10.5","weather_code":2,"time":"` + localTime + `"}}`)
+
+	var got models.WeatherLog
+	proc := NewProcessor(&MockAPIClient{SendFunc: func(l models.WeatherLog) error {
+		got = l
+		return nil
+	}})
+
+	// Sem a normalização, o número com vírgula não é decodificado
+	var stageErr *StageError
+	if err := proc.ProcessMessage(Message{Body: body}); !errors.As(err, &stageErr) || stageErr.Stage != StageDecode {
+		t.Fatalf("ProcessMessage() error = %v, want decode error", err)
+	}
+
+	proc.SetLenientNormalization(true)
+	if err := proc.ProcessMessage(Message{Body: body}); err != nil {
+		t.Fatalf("ProcessMessage() error = %v", err)
+	}
+	wantRepairs := []string{
+		"decimal-comma:location.latitude",
+		"decimal-comma:location.longitude",
+		"decimal-comma:current.temperature",
+		"numeric-string:current.wind_speed",
+		"swapped-coordinates:location",
+		"timestamp-from-current-time:timestamp",
+		"humidity-fraction:current.humidity",
+	}
+	if !reflect.DeepEqual(got.Repairs, wantRepairs) {
+		t.Errorf("Repairs = %v, want %v", got.Repairs, wantRepairs)
+	}
+	if got.Temperature != 25.3 || got.Humidity != 65 || !strings.Contains(got.Location, "São Paulo") {
+		t.Errorf("WeatherLog = %+v", got)
+	}
+
+	trace := proc.ExplainMessage(Message{Body: body}, client.Contract{})
+	normalized := 0
+	for _, step := range trace.Steps {
+		if step.Stage == StageNormalize {
+			normalized++
+		}
+	}
+	if normalized != len(wantRepairs) {
+		t.Errorf("normalize steps = %d, want %d", normalized, len(wantRepairs))
+	}
+}
+
+// airQualityClient aceita também registros de qualidade do ar
+type airQualityClient struct {
+	MockAPIClient
+	sent []models.AirQualityLog
+}
+
+func (c *airQualityClient) SendAirQualityLog(l models.AirQualityLog) error {
+	c.sent = append(c.sent, l)
+	return nil
+}
+
+func TestProcessor_ProcessMessage_AirQuality(t *testing.T) {
+	body := []byte(`{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"pm2_5":12,"pm10":75,"nitrogen_dioxide":100},"units":{"nitrogen_dioxide":"ppb"}}`)
+
+	t.Run("indexes are computed and sent", func(t *testing.T) {
+		c := &airQualityClient{MockAPIClient: MockAPIClient{SendFunc: func(models.WeatherLog) error {
+			return errors.New("air quality sent as weather")
+		}}}
+		if err := NewProcessor(c).ProcessMessage(Message{Body: body}); err != nil {
+			t.Fatalf("ProcessMessage() error = %v", err)
+		}
+		if len(c.sent) != 1 {
+			t.Fatalf("sent = %d, want 1", len(c.sent))
+		}
+		got := c.sent[0]
+		if got.USAQI == nil || got.USAQI.Index != 100 || got.IQAr == nil || got.IQAr.Dominant != "pm10" {
+			t.Errorf("USAQI = %+v, IQAr = %+v", got.USAQI, got.IQAr)
+		}
+	})
+
+	t.Run("type property selects the pipeline", func(t *testing.T) {
+		c := &airQualityClient{}
+		err := NewProcessor(c).ProcessMessage(Message{Body: []byte(`{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{}}`), Type: "air-quality"})
+		var stageErr *StageError
+		if !errors.As(err, &stageErr) || stageErr.Stage != StageValidate || !errors.Is(err, models.ErrNoPollutants) {
+			t.Errorf("ProcessMessage() error = %v, want validation error without pollutants", err)
+		}
+	})
+
+	t.Run("sender without air quality support", func(t *testing.T) {
+		err := NewProcessor(&MockAPIClient{}).ProcessMessage(Message{Body: body})
+		if !errors.Is(err, ErrAirQualityUnsupported) {
+			t.Errorf("ProcessMessage() error = %v, want ErrAirQualityUnsupported", err)
+		}
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		trace := NewProcessor(&MockAPIClient{}).ExplainMessage(Message{Body: body}, client.Contract{})
+		if trace.Outcome != OutcomeAccepted || trace.AirQuality == nil || trace.Payload != nil {
+			t.Fatalf("trace = %+v", trace)
+		}
+		var names []string
+		for _, s := range trace.Steps {
+			if strings.HasPrefix(s.Name, "aqi.") {
+				names = append(names, s.Name+"="+s.Decision)
+			}
+		}
+		if want := []string{"aqi.epa=Moderate", "aqi.conama=Moderada"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("steps = %v, want %v", names, want)
+		}
+	})
+}
+
+func TestProcessor_Dispatch(t *testing.T) {
+	observation := []byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":25,"humidity":60}}`)
+	forecast := []byte(`{"location":{"latitude":-23.55,"longitude":-46.63},"hourly":{"time":["2099-06-15T12:00"],"temperature":[24],"humidity":[60]}}`)
+	airQuality := []byte(`{"timestamp":"2025-06-15T14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"pm2_5":12}}`)
+
+	proc := NewProcessor(&MockAPIClient{})
+	proc.SetBindings(map[string]string{"weather.aq": "air_quality"})
+
+	tests := []struct {
+		name     string
+		msg      Message
+		wantType string
+		wantBy   string
+	}{
+		{"undeclared observation", Message{Body: observation}, TypeObservation, ResolvedByDefault},
+		{"adapter name in type property", Message{Body: observation, Type: "canonical"}, TypeObservation, ResolvedByProperty},
+		{"forecast type property", Message{Body: forecast, Type: "Forecast"}, TypeForecast, ResolvedByProperty},
+		{"type field", Message{Body: []byte(`{"type":"air-quality",` + string(airQuality[1:]))}, TypeAirQuality, ResolvedByField},
+		{"routing key binding", Message{Body: airQuality, RoutingKey: "weather.aq"}, TypeAirQuality, ResolvedByBinding},
+		{"unbound routing key", Message{Body: observation, RoutingKey: "weather.other"}, TypeObservation, ResolvedByDefault},
+		{"sniffed air quality", Message{Body: airQuality}, TypeAirQuality, ResolvedBySniffing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := proc.Dispatch(tt.msg)
+			if err != nil {
+				t.Fatalf("Dispatch() error = %v", err)
+			}
+			if got.Type != tt.wantType || got.By != tt.wantBy || len(got.Records) == 0 {
+				t.Errorf("Dispatch() = %+v, want %s by %s", got.Resolution, tt.wantType, tt.wantBy)
+			}
+		})
+	}
+
+	t.Run("unknown type is quarantined", func(t *testing.T) {
+		for _, msg := range []Message{
+			{Body: observation, Type: "weather.current"},
+			{Body: []byte(`{"type":"radar","event":"Tempestade"}`)},
+		} {
+			err := proc.ProcessMessage(msg)
+			var stageErr *StageError
+			if !errors.As(err, &stageErr) || stageErr.Stage != StageDispatch || !stageErr.Quarantine() || !errors.Is(err, ErrUnknownMessageType) {
+				t.Errorf("ProcessMessage(%s) error = %v, want quarantined dispatch error", msg.Body, err)
+			}
+		}
+		trace := proc.ExplainMessage(Message{Body: observation, Type: "radar"}, client.Contract{})
+		if trace.Outcome != OutcomeRejected || trace.Stage != StageDispatch || len(trace.Steps) != 1 {
+			t.Errorf("Explain() = %+v, want rejection at dispatch", trace)
+		}
+	})
+
+	t.Run("forecast type requires forecasts", func(t *testing.T) {
+		if _, err := proc.Dispatch(Message{Body: observation, Type: TypeForecast}); !errors.Is(err, ErrNoForecast) {
+			t.Errorf("Dispatch() error = %v, want ErrNoForecast", err)
+		}
+	})
+
+	t.Run("registered handler and sink", func(t *testing.T) {
+		type lightning struct {
+			Type  string `json:"type"`
+			Event string `json:"event"`
+		}
+		proc := NewProcessor(&MockAPIClient{})
+		err := proc.Register(NewHandler("lightning", "lightnings", Pipeline[lightning, string]{
+			Decode: func(msg Message, _ *Trace) (lightning, error) {
+				var a lightning
+				return a, json.Unmarshal(msg.Body, &a)
+			},
+			Validate: func(a *lightning, _ *Trace) error {
+				if a.Event == "" {
+					return errors.New("evento ausente")
+				}
+				return nil
+			},
+			Transform: func(a *lightning, _ Message, trace *Trace) ([]string, error) {
+				trace.Add(StageTransform, "event", a.Event, nil)
+				return []string{a.Event}, nil
+			},
+		}))
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		if err := proc.Register(NewHandler(TypeForecast, "lightnings", Pipeline[lightning, string]{})); err == nil {
+			t.Error("Register(forecast) error = nil, want duplicate type")
+		}
+		if err := proc.CheckBindings(map[string]string{"weather.lightning": "lightning", "weather.radar": "radar"}); !errors.Is(err, ErrUnknownMessageType) {
+			t.Errorf("CheckBindings() error = %v, want unknown radar", err)
+		}
+
+		body := []byte(`{"type":"lightning","event":"Descarga"}`)
+		if err := proc.ProcessMessage(Message{Body: body}); !errors.Is(err, ErrNoSink) {
+			t.Errorf("ProcessMessage() without sink error = %v, want ErrNoSink", err)
+		}
+		var sent []any
+		proc.SetSink("lightnings", SinkFunc(func(record any) error {
+			sent = append(sent, record)
+			return nil
+		}))
+		if err := proc.ProcessMessage(Message{Body: body}); err != nil || len(sent) != 1 || sent[0] != "Descarga" {
+			t.Errorf("ProcessMessage() = %v, sent = %v", err, sent)
+		}
+
+		var stageErr *StageError
+		if err := proc.ProcessMessage(Message{Body: []byte(`{"type":"lightning"}`)}); !errors.As(err, &stageErr) || stageErr.Stage != StageValidate {
+			t.Errorf("ProcessMessage(no event) error = %v, want validate stage", err)
+		}
+
+		trace := proc.ExplainMessage(Message{Body: body}, client.Contract{})
+		if trace.Outcome != OutcomeAccepted || trace.Type != "lightning" || len(trace.Records) != 1 || trace.Contract != nil {
+			t.Errorf("Explain() = %+v", trace)
+		}
+	})
+}
+
+func TestProcessor_AlertAndCommand(t *testing.T) {
+	alert := []byte(`{"type":"alert","event":"Tempestade","severity":"severe","onset":"2025-06-15T18:00:00Z","expires":"2025-06-16T06:00:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`)
+
+	t.Run("alert", func(t *testing.T) {
+		proc := NewProcessor(&MockAPIClient{})
+		if err := proc.ProcessMessage(Message{Body: alert}); !errors.Is(err, ErrAlertUnsupported) {
+			t.Errorf("ProcessMessage() error = %v, want ErrAlertUnsupported", err)
+		}
+
+		var sent []any
+		proc.SetSink(RouteAlert, SinkFunc(func(record any) error {
+			sent = append(sent, record)
+			return nil
+		}))
+		if err := proc.ProcessMessage(Message{Body: alert}); err != nil || len(sent) != 1 {
+			t.Fatalf("ProcessMessage() = %v, sent = %v", err, sent)
+		}
+		if got, ok := sent[0].(models.AlertLog); !ok || got.Event != "Tempestade" || got.Expires != "2025-06-16T06:00:00Z" {
+			t.Errorf("sent = %+v", sent[0])
+		}
+
+		var stageErr *StageError
+		invalid := Message{Body: []byte(`{"event":"Tempestade","severity":"alta"}`), Type: TypeAlert}
+		if err := proc.ProcessMessage(invalid); !errors.As(err, &stageErr) || stageErr.Stage != StageValidate || len(sent) != 1 {
+			t.Errorf("ProcessMessage(invalid) error = %v, want validate stage", err)
+		}
+		if _, err := proc.Dispatch(Message{Body: []byte(`{"event":"Tempestade"}`)}); err == nil {
+			t.Error("Dispatch(undeclared alert) error = nil, want observation validation")
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		proc := NewProcessor(&MockAPIClient{})
+		body := []byte(`{"type":"command","command":"Log-Level","args":{"level":"debug"}}`)
+		if err := proc.ProcessCommand(Message{Body: body}); !errors.Is(err, ErrNoSink) {
+			t.Errorf("ProcessCommand() without sink error = %v, want ErrNoSink", err)
+		}
+
+		var executed []Command
+		proc.SetSink(RouteCommand, SinkFunc(func(record any) error {
+			executed = append(executed, record.(Command))
+			return nil
+		}))
+		if err := proc.ProcessCommand(Message{Body: body}); err != nil || len(executed) != 1 {
+			t.Fatalf("ProcessCommand() = %v, executed = %v", err, executed)
+		}
+		if got := executed[0]; got.Name != CommandLogLevel || got.Args["level"] != "debug" {
+			t.Errorf("executed = %+v", got)
+		}
+
+		for _, invalid := range []string{
+			`{"command":"shutdown"}`,
+			`{"command":"log-level"}`,
+			`{"command":"log-level","args":{"level":"verbose"}}`,
+		} {
+			var stageErr *StageError
+			err := proc.ProcessCommand(Message{Body: []byte(invalid)})
+			if !errors.As(err, &stageErr) || stageErr.Stage != StageValidate {
+				t.Errorf("ProcessCommand(%s) error = %v, want validate stage", invalid, err)
+			}
+		}
+
+		// Na fila de mensagens o comando é um tipo desconhecido, nunca executado
+		for _, msg := range []Message{{Body: body}, {Body: []byte(`{"command":"reload"}`), Type: TypeCommand}} {
+			if err := proc.ProcessMessage(msg); !errors.Is(err, ErrUnknownMessageType) {
+				t.Errorf("ProcessMessage(%s) error = %v, want ErrUnknownMessageType", msg.Body, err)
+			}
+		}
+		trace := proc.ExplainMessage(Message{Body: []byte(`{"command":"reload"}`), Type: TypeCommand}, client.Contract{})
+		if trace.Outcome == OutcomeAccepted {
+			t.Errorf("Explain() = %+v, want rejected", trace)
+		}
+		if len(executed) != 1 {
+			t.Errorf("executed = %v, want only the valid command", executed)
+		}
+	})
+}
+
+// enricherFunc adapta uma função para a interface Enricher
+type enricherFunc func(*models.WeatherMessage, *models.WeatherLog) error
+
+func (f enricherFunc) Enrich(msg *models.WeatherMessage, weatherLog *models.WeatherLog) error {
+	return f(msg, weatherLog)
+}
+
+func TestProcessor_Transform(t *testing.T) {
+	valid := []byte(`{"timestamp":"2025-06-15T14:30:00","location":{"latitude":-23.5505,"longitude":-46.6333},"current":{"temperature":25.5,"humidity":65}}`)
+
+	t.Run("does not send", func(t *testing.T) {
+		sent := false
+		proc := NewProcessor(&MockAPIClient{SendFunc: func(models.WeatherLog) error {
+			sent = true
+			return nil
+		}})
+
+		weatherLog, err := proc.Transform(valid)
+		if err != nil {
+			t.Fatalf("Transform() error = %v", err)
+		}
+		if sent {
+			t.Error("Transform() sent the log")
+		}
+		if weatherLog.Temperature != 25.5 {
+			t.Errorf("Temperature = %v, want 25.5", weatherLog.Temperature)
+		}
+	})
+
+	t.Run("applies enrichers in order", func(t *testing.T) {
+		proc := NewProcessor(&MockAPIClient{})
+		proc.AddEnricher(enricherFunc(func(_ *models.WeatherMessage, l *models.WeatherLog) error {
+			l.Location = "Fazenda"
+			return nil
+		}))
+		proc.AddEnricher(enricherFunc(func(_ *models.WeatherMessage, l *models.WeatherLog) error {
+			l.Location += ", SP"
+			return nil
+		}))
+
+		weatherLog, err := proc.Transform(valid)
+		if err != nil {
+			t.Fatalf("Transform() error = %v", err)
+		}
+		if weatherLog.Location != "Fazenda, SP" {
+			t.Errorf("Location = %v, want %v", weatherLog.Location, "Fazenda, SP")
+		}
+	})
+
+	t.Run("applies configured rules", func(t *testing.T) {
+		spec, err := rules.Parse([]byte(`{"rules": [
+			{"name": "umidade", "field": "current.humidity", "max": 100, "action": "clamp"},
+			{"name": "calor", "field": "current.temperature", "max": 35},
+			{"name": "vento", "field": "current.wind_speed", "max": 60, "action": "flag"}
+		]}`))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		rs, err := rules.Compile(spec, nil)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		proc := NewProcessor(&MockAPIClient{})
+		proc.SetRules(rs)
+
+		weatherLog, err := proc.Transform([]byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":25,"humidity":100.5,"wind_speed":72}}`))
+		if err != nil {
+			t.Fatalf("Transform() error = %v", err)
+		}
+		if weatherLog.Humidity != 100 || !reflect.DeepEqual(weatherLog.QualityFlags, []string{"umidade:clamped", "vento"}) {
+			t.Errorf("Humidity = %v, QualityFlags = %v", weatherLog.Humidity, weatherLog.QualityFlags)
+		}
+
+		// Violações das regras configuradas somam-se às embutidas
+		_, err = proc.Transform([]byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":38,"humidity":60,"wind_speed":-1}}`))
+		var verr *models.ValidationError
+		if !errors.As(err, &verr) || len(verr.Violations) != 2 || verr.Violations[1].Rule != "calor" {
+			t.Fatalf("Transform() error = %v, want wind speed and calor violations", err)
+		}
+		if !errors.Is(err, models.ErrInvalidTemperature) || !errors.Is(err, rules.ErrRuleViolation) {
+			t.Errorf("errors.Is(%v) does not match the rule sentinels", err)
+		}
+
+		proc.SetRules(nil)
+		if _, err := proc.Transform([]byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":38,"humidity":60}}`)); err != nil {
+			t.Errorf("Transform() without rules error = %v", err)
+		}
+	})
+
+	t.Run("temporal quality control", func(t *testing.T) {
+		proc := NewProcessor(&MockAPIClient{})
+		proc.SetQualityControl(qc.NewChecker(qc.DefaultConfig()))
+		reading := func(minute int, temperature float64) []byte {
+			return []byte(fmt.Sprintf(`{"timestamp":"2025-06-15T14:%02d:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":%v,"humidity":65}}`, minute, temperature))
+		}
+
+		first, err := proc.Transform(reading(0, 20))
+		if err != nil || first.QualityScore != nil {
+			t.Fatalf("Transform(first) = %+v, %v; want no score", first, err)
+		}
+		// O explain avalia sem gravar no histórico
+		if trace := proc.Explain(reading(10, 35), client.Contract{}); trace.Payload == nil || trace.Payload.QualityFlags[0] != "qc:step:temperature" {
+			t.Fatalf("Explain() = %+v, want step flag", trace)
+		}
+		jump, err := proc.Transform(reading(10, 35))
+		if err != nil {
+			t.Fatalf("Transform() error = %v", err)
+		}
+		if !reflect.DeepEqual(jump.QualityFlags, []string{"qc:step:temperature"}) || jump.QualityScore == nil || *jump.QualityScore != 0.67 {
+			t.Errorf("QualityFlags = %v, QualityScore = %v", jump.QualityFlags, jump.QualityScore)
+		}
+	})
+
+	t.Run("quality control records only sent observations", func(t *testing.T) {
+		sendErr := errors.New("backend indisponível")
+		failing := false
+		proc := NewProcessor(&MockAPIClient{SendFunc: func(models.WeatherLog) error {
+			if failing {
+				return sendErr
+			}
+			return nil
+		}})
+		proc.SetQualityControl(qc.NewChecker(qc.DefaultConfig()))
+		reading := func(minute int, temperature float64) []byte {
+			return []byte(fmt.Sprintf(`{"timestamp":"2025-06-15T14:%02d:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":%v,"humidity":65}}`, minute, temperature))
+		}
+
+		if err := proc.Process(reading(0, 20)); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		failing = true
+		if err := proc.Process(reading(10, 35)); !errors.Is(err, sendErr) {
+			t.Fatalf("Process() error = %v, want %v", err, sendErr)
+		}
+		// O pico não enviado fica fora do histórico: a leitura seguinte é
+		// comparada com a primeira
+		failing = false
+		weatherLog, err := proc.Transform(reading(20, 20.4))
+		if err != nil || len(weatherLog.QualityFlags) != 0 {
+			t.Errorf("Transform() = %v, %v; want no flags", weatherLog.QualityFlags, err)
+		}
+	})
+
+	t.Run("anomaly detection", func(t *testing.T) {
+		cfg := anomaly.DefaultConfig()
+		cfg.WarmUp = 3
+		proc := NewProcessor(&MockAPIClient{})
+		proc.SetAnomalyDetector(anomaly.NewDetector(cfg))
+		publisher := &anomalyRecorder{}
+		proc.SetAnomalyPublisher(publisher)
+		reading := func(day int, temperature float64) []byte {
+			return []byte(fmt.Sprintf(`{"timestamp":"2025-06-%02dT14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":%v,"humidity":65}}`, day, temperature))
+		}
+
+		for day, temperature := range []float64{25, 26, 25} {
+			if weatherLog, err := proc.Transform(reading(day+1, temperature)); err != nil || len(weatherLog.QualityFlags) != 0 {
+				t.Fatalf("Transform(warm-up) = %v, %v", weatherLog.QualityFlags, err)
+			}
+		}
+		// O explain pontua sem aprender nem publicar
+		if trace := proc.Explain(reading(4, 40), client.Contract{}); trace.Payload == nil || !reflect.DeepEqual(trace.Payload.QualityFlags, []string{"anomaly:temperature"}) {
+			t.Fatalf("Explain() = %+v, want anomaly flag", trace)
+		}
+		if len(publisher.events) != 0 {
+			t.Fatalf("Explain() published %d events", len(publisher.events))
+		}
+
+		hot, err := proc.Transform(reading(4, 40))
+		if err != nil || !reflect.DeepEqual(hot.QualityFlags, []string{"anomaly:temperature"}) {
+			t.Fatalf("Transform() = %v, %v; want anomaly flag", hot.QualityFlags, err)
+		}
+		// A reentrega mantém a marcação sem repetir o evento
+		if _, err := proc.Transform(reading(4, 40)); err != nil {
+			t.Fatalf("Transform(redelivery) error = %v", err)
+		}
+		if len(publisher.events) != 1 || publisher.events[0].Variable != "temperature" || publisher.events[0].Value != 40 {
+			t.Errorf("published = %+v, want one temperature event", publisher.events)
+		}
+	})
+
+	t.Run("anomaly events follow the send", func(t *testing.T) {
+		cfg := anomaly.DefaultConfig()
+		cfg.WarmUp = 3
+		sendErr := errors.New("backend indisponível")
+		failing := false
+		proc := NewProcessor(&MockAPIClient{SendFunc: func(models.WeatherLog) error {
+			if failing {
+				return sendErr
+			}
+			return nil
+		}})
+		proc.SetAnomalyDetector(anomaly.NewDetector(cfg))
+		publisher := &anomalyRecorder{}
+		proc.SetAnomalyPublisher(publisher)
+		reading := func(day int, temperature float64) []byte {
+			return []byte(fmt.Sprintf(`{"timestamp":"2025-06-%02dT14:00:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":%v,"humidity":65}}`, day, temperature))
+		}
+
+		for day, temperature := range []float64{25, 26, 25} {
+			if err := proc.Process(reading(day+1, temperature)); err != nil {
+				t.Fatalf("Process(warm-up) error = %v", err)
+			}
+		}
+		// Sem envio, a leitura não entra na linha de base nem gera evento; a
+		// reentrega bem-sucedida publica o evento uma vez
+		failing = true
+		if err := proc.Process(reading(4, 40)); !errors.Is(err, sendErr) || len(publisher.events) != 0 {
+			t.Fatalf("Process() error = %v, published %d events", err, len(publisher.events))
+		}
+		failing = false
+		if err := proc.Process(reading(4, 40)); err != nil || len(publisher.events) != 1 {
+			t.Errorf("Process(redelivery) error = %v, published %d events, want 1", err, len(publisher.events))
+		}
+	})
+
+	t.Run("forecast arrays expand into one log per timestamp", func(t *testing.T) {
+		var sent []models.WeatherLog
+		proc := NewProcessor(&MockAPIClient{SendFunc: func(l models.WeatherLog) error {
+			sent = append(sent, l)
+			return nil
+		}})
+		proc.SetQualityControl(qc.NewChecker(qc.DefaultConfig()))
+		body := []byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63,"timezone":"America/Sao_Paulo"},
+			"current":{"temperature":25,"humidity":60},
+			"hourly":{"time":["2099-06-15T12:00","2099-06-15T13:00"],"temperature":[24,40],"humidity":[60,58]},
+			"daily":{"time":["2099-06-16"],"temperature_max":[26],"temperature_min":[16],"humidity":[70]}}`)
+
+		if _, err := proc.Transform(body); err == nil {
+			t.Error("Transform() error = nil, want error for a message with several logs")
+		}
+		if err := proc.Process(body); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		var got []string
+		for _, l := range sent {
+			got = append(got, l.Kind+"/"+l.Period+"@"+l.Timestamp)
+		}
+		want := []string{"observation/@2025-06-15T14:30:00Z", "forecast/hourly@2099-06-15T15:00:00Z",
+			"forecast/hourly@2099-06-15T16:00:00Z", "forecast/daily@2099-06-16T03:00:00Z"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("sent = %v, want %v", got, want)
+		}
+		// Previsões não passam pelo controle de qualidade temporal
+		if forecast := sent[2]; forecast.IssuedAt != "2025-06-15T14:30:00Z" || len(forecast.QualityFlags) != 0 || forecast.QualityScore != nil {
+			t.Errorf("forecast = %+v", forecast)
+		}
+
+		trace := proc.Explain(body, client.Contract{})
+		if trace.Outcome != OutcomeAccepted || len(trace.Payloads) != 4 {
+			t.Errorf("Explain() outcome = %s, payloads = %d; want 4", trace.Outcome, len(trace.Payloads))
+		}
+
+		// Um registro inválido rejeita a mensagem inteira antes de qualquer envio
+		sent = nil
+		invalid := []byte(`{"location":{"latitude":-23.55,"longitude":-46.63},"hourly":{"time":["2099-06-15T12:00"],"temperature":[140],"humidity":[60]}}`)
+		var stageErr *StageError
+		if err := proc.Process(invalid); !errors.As(err, &stageErr) || stageErr.Stage != StageValidate || len(sent) != 0 {
+			t.Errorf("Process(invalid) error = %v, sent = %d", err, len(sent))
+		}
+	})
+
+	t.Run("send failure after earlier records is at-least-once", func(t *testing.T) {
+		var sent []string
+		failures := 1
+		proc := NewProcessor(&MockAPIClient{SendFunc: func(l models.WeatherLog) error {
+			if l.Kind == models.KindForecast && failures > 0 {
+				failures--
+				return errors.New("backend indisponível")
+			}
+			sent = append(sent, l.Kind+"@"+l.Timestamp)
+			return nil
+		}})
+		body := []byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63,"timezone":"America/Sao_Paulo"},
+			"current":{"temperature":25,"humidity":60},
+			"hourly":{"time":["2099-06-15T12:00"],"temperature":[24],"humidity":[60]}}`)
+
+		var stageErr *StageError
+		if err := proc.Process(body); !errors.As(err, &stageErr) || stageErr.Stage != StageSend {
+			t.Fatalf("Process() error = %v, want send error", err)
+		}
+		if want := []string{"observation@2025-06-15T14:30:00Z"}; !reflect.DeepEqual(sent, want) {
+			t.Fatalf("sent = %v, want %v", sent, want)
+		}
+
+		// O reprocessamento reenvia a observação já entregue
+		if err := proc.Process(body); err != nil {
+			t.Fatalf("Process(redelivery) error = %v", err)
+		}
+		want := []string{"observation@2025-06-15T14:30:00Z", "observation@2025-06-15T14:30:00Z", "forecast@2099-06-15T15:00:00Z"}
+		if !reflect.DeepEqual(sent, want) {
+			t.Errorf("sent = %v, want %v", sent, want)
+		}
+	})
+
+	t.Run("reports failing stage", func(t *testing.T) {
+		proc := NewProcessor(&MockAPIClient{})
+		proc.AddEnricher(enricherFunc(func(*models.WeatherMessage, *models.WeatherLog) error {
+			return errors.New("boom")
+		}))
+
+		tests := []struct {
+			body  []byte
+			stage string
+		}{
+			{[]byte(`{invalid`), StageDecode},
+			{[]byte(`{"location":{"latitude":-23.5,"longitude":-46.6}}`), StageValidate},
+			{valid, StageEnrich},
+		}
+		for _, tt := range tests {
+			_, err := proc.Transform(tt.body)
+			var stageErr *StageError
+			if !errors.As(err, &stageErr) || stageErr.Stage != tt.stage {
+				t.Errorf("Transform(%s) error = %v, want stage %s", tt.body, err, tt.stage)
+			}
+		}
+	})
+}
+
+func TestRegionEnricher(t *testing.T) {
+	regions, err := geo.ParseRegions([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"name": "Bairro Centro", "location": "enrich"},
+		 "geometry": {"type": "Polygon", "coordinates": [[[-46.70, -23.60], [-46.60, -23.60], [-46.60, -23.50], [-46.70, -23.50], [-46.70, -23.60]]]}},
+		{"type": "Feature", "properties": {"name": "Planta 3", "priority": 10, "id": "PL-03", "owner": {"name": "Agro SA"}, "area_ha": 12.5},
+		 "geometry": {"type": "Polygon", "coordinates": [[[-46.64, -23.56], [-46.62, -23.56], [-46.62, -23.54], [-46.64, -23.54], [-46.64, -23.56]]]}},
+		{"type": "Feature", "properties": {"name": "Área de Risco", "priority": 20, "location": "tag"},
+		 "geometry": {"type": "Polygon", "coordinates": [[[-46.69, -23.59], [-46.68, -23.59], [-46.68, -23.58], [-46.69, -23.58], [-46.69, -23.59]]]}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseRegions() error = %v", err)
+	}
+	enricher := NewRegionEnricher(geo.NewRegionIndex(regions))
+
+	tests := []struct {
+		name         string
+		lat, lon     float64
+		wantLocation string
+		wantRegions  []string
+	}{
+		{"override by higher priority", -23.55, -46.63, "Planta 3", []string{"Planta 3", "Bairro Centro"}},
+		{"enrich", -23.52, -46.68, "Bairro Centro - São Paulo, SP", []string{"Bairro Centro"}},
+		{"tag only keeps location", -23.585, -46.685, "São Paulo, SP", []string{"Área de Risco", "Bairro Centro"}},
+		{"outside regions", -23.40, -46.63, "São Paulo, SP", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &models.WeatherMessage{Location: models.WeatherLocation{Latitude: tt.lat, Longitude: tt.lon}}
+			weatherLog := &models.WeatherLog{Location: "São Paulo, SP"}
+			if err := enricher.Enrich(msg, weatherLog); err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+			if weatherLog.Location != tt.wantLocation {
+				t.Errorf("Location = %v, want %v", weatherLog.Location, tt.wantLocation)
+			}
+			if !reflect.DeepEqual(weatherLog.Regions, tt.wantRegions) {
+				t.Errorf("Regions = %v, want %v", weatherLog.Regions, tt.wantRegions)
+			}
 		})
 	}
+
+	t.Run("configured properties", func(t *testing.T) {
+		enricher := NewRegionEnricher(geo.NewRegionIndex(regions))
+		enricher.SetProperties([]string{"id", "owner", "crop"})
+		msg := &models.WeatherMessage{Location: models.WeatherLocation{Latitude: -23.55, Longitude: -46.63}}
+		weatherLog := &models.WeatherLog{Location: "São Paulo, SP"}
+		if err := enricher.Enrich(msg, weatherLog); err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+		want := []models.RegionRef{
+			{Name: "Planta 3", Properties: map[string]interface{}{"id": "PL-03", "owner": map[string]interface{}{"name": "Agro SA"}}},
+			{Name: "Bairro Centro"},
+		}
+		if !reflect.DeepEqual(weatherLog.RegionDetails, want) {
+			t.Errorf("RegionDetails = %+v, want %+v", weatherLog.RegionDetails, want)
+		}
+
+		body, err := json.Marshal(weatherLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), `"regionDetails":[{"name":"Planta 3","properties":{"id":"PL-03","owner":{"name":"Agro SA"}}},{"name":"Bairro Centro"}]`) {
+			t.Errorf("payload = %s", body)
+		}
+	})
+
+	t.Run("nil index is a no-op", func(t *testing.T) {
+		weatherLog := &models.WeatherLog{Location: "São Paulo, SP"}
+		if err := NewRegionEnricher(nil).Enrich(&models.WeatherMessage{}, weatherLog); err != nil || weatherLog.Regions != nil || weatherLog.RegionDetails != nil {
+			t.Errorf("Enrich() = %v, %v; want no change", err, weatherLog.Regions)
+		}
+	})
+}
+
+func TestStageError_DeadLetterHeaders(t *testing.T) {
+	proc := NewProcessor(&MockAPIClient{})
+	_, err := proc.Transform([]byte(`{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-123.5,"longitude":-46.6},"current":{"temperature":25,"humidity":150}}`))
+
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) {
+		t.Fatalf("Transform() error = %v, want *StageError", err)
+	}
+	for _, sentinel := range []error{models.ErrInvalidLocation, models.ErrInvalidHumidity} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("errors.Is(err, %v) = false", sentinel)
+		}
+	}
+
+	headers := stageErr.DeadLetterHeaders()
+	if headers[HeaderErrorStage] != StageValidate {
+		t.Errorf("%s = %q, want %q", HeaderErrorStage, headers[HeaderErrorStage], StageValidate)
+	}
+	var violations []models.Violation
+	if err := json.Unmarshal([]byte(headers[HeaderValidationError]), &violations); err != nil {
+		t.Fatalf("%s is not JSON: %v", HeaderValidationError, err)
+	}
+	if len(violations) != 2 || violations[0].Field != "location.latitude" || violations[1].Field != "current.humidity" {
+		t.Errorf("violations = %+v, want latitude and humidity", violations)
+	}
+
+	decodeErr := &StageError{Stage: StageDecode, Err: errors.New("boom")}
+	if _, ok := decodeErr.DeadLetterHeaders()[HeaderValidationError]; ok {
+		t.Errorf("decode error has %s", HeaderValidationError)
+	}
+}
+
+// anomalyRecorder guarda os eventos de anomalia publicados
+type anomalyRecorder struct {
+	events []anomaly.Event
 }
 
+func (r *anomalyRecorder) PublishAnomaly(e anomaly.Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"sync/atomic"
+
+	"go-worker/internal/geo"
+	"go-worker/internal/models"
+)
+
+// RegionEnricher aplica as regiões definidas pelo usuário (fazendas, plantas,
+// bairros) ao log: a região mais prioritária pode substituir ou complementar o
+// nome da localização e todas as regiões que contêm o ponto viram tags, com as
+// propriedades configuradas em SetProperties
+type RegionEnricher struct {
+	index      atomic.Pointer[geo.RegionIndex]
+	properties atomic.Pointer[[]string]
+}
+
+// NewRegionEnricher cria o enriquecimento a partir de um índice de regiões
+func NewRegionEnricher(index *geo.RegionIndex) *RegionEnricher {
+	e := &RegionEnricher{}
+	e.index.Store(index)
+	return e
+}
+
+// SetIndex substitui o índice usado nas próximas mensagens (recarga de configuração)
+func (e *RegionEnricher) SetIndex(index *geo.RegionIndex) {
+	e.index.Store(index)
+}
+
+// SetProperties define as propriedades GeoJSON repassadas em RegionDetails
+// (nil ou vazio não preenche RegionDetails)
+func (e *RegionEnricher) SetProperties(keys []string) {
+	e.properties.Store(&keys)
+}
+
+// Enrich implementa Enricher
+func (e *RegionEnricher) Enrich(msg *models.WeatherMessage, weatherLog *models.WeatherLog) error {
+	regions := e.index.Load().Lookup(msg.Location.Latitude, msg.Location.Longitude)
+	if len(regions) == 0 {
+		return nil
+	}
+
+	var keys []string
+	if p := e.properties.Load(); p != nil {
+		keys = *p
+	}
+	for _, r := range regions {
+		weatherLog.Regions = append(weatherLog.Regions, r.Name)
+		if len(keys) > 0 {
+			weatherLog.RegionDetails = append(weatherLog.RegionDetails, regionRef(r, keys))
+		}
+	}
+
+	switch top := regions[0]; top.Mode {
+	case geo.RegionOverride:
+		weatherLog.Location = top.Name
+	case geo.RegionEnrich:
+		weatherLog.Location = top.Name + " - " + weatherLog.Location
+	}
+	return nil
+}
+
+// regionRef copia da região as propriedades pedidas que ela define
+func regionRef(r *geo.Region, keys []string) models.RegionRef {
+	ref := models.RegionRef{Name: r.Name}
+	for _, key := range keys {
+		if v, ok := r.Properties[key]; ok {
+			if ref.Properties == nil {
+				ref.Properties = make(map[string]interface{}, len(keys))
+			}
+			ref.Properties[key] = v
+		}
+	}
+	return ref
+}
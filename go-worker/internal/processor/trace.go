@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"time"
+
+	"go-worker/internal/client"
+	"go-worker/internal/models"
+)
+
+// Resultados possíveis de uma mensagem no modo explain
+const (
+	OutcomeAccepted = "accepted"
+	OutcomeRejected = "rejected"
+)
+
+// Trace registra cada decisão do pipeline para uma mensagem. É gerado pelo
+// modo explain (dry-run e endpoint administrativo), que nunca envia nem
+// confirma a mensagem.
+type Trace struct {
+	ReceivedAt time.Time `json:"receivedAt"`
+	// Type é o tipo da mensagem, que escolheu o handler
+	Type    string                 `json:"type,omitempty"`
+	Decoded *models.WeatherMessage `json:"decoded,omitempty"`
+	// DecodedAirQuality e AirQuality substituem Decoded e Payload nas
+	// mensagens de qualidade do ar, que não passam pelo contrato
+	DecodedAirQuality *models.AirQualityMessage `json:"decodedAirQuality,omitempty"`
+	AirQuality        *models.AirQualityLog     `json:"airQuality,omitempty"`
+	Steps             []TraceStep               `json:"steps"`
+	Payload           *models.WeatherLog        `json:"payload,omitempty"`
+	// Payloads lista todos os registros quando a mensagem traz previsões;
+	// Payload é o primeiro deles
+	Payloads []models.WeatherLog `json:"payloads,omitempty"`
+	// Records são os registros dos demais tipos registrados
+	Records []any `json:"records,omitempty"`
+	// Contract é a comparação do primeiro registro incompatível ou, se
+	// todos forem aceitos, do primeiro
+	Contract *client.ContractReport `json:"contract,omitempty"`
+	Outcome  string                 `json:"outcome"`
+	Stage    string                 `json:"stage,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// TraceStep é uma decisão tomada em uma etapa do pipeline
+type TraceStep struct {
+	Stage    string      `json:"stage"`
+	Name     string      `json:"name"`
+	Decision string      `json:"decision"`
+	Detail   interface{} `json:"detail,omitempty"`
+}
+
+// add registra um passo; um Trace nil (processamento normal) ignora a chamada
+func (t *Trace) add(stage, name, decision string, detail interface{}) {
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, TraceStep{Stage: stage, Name: name, Decision: decision, Detail: detail})
+}
+
+// Add registra um passo dos handlers registrados fora do pacote; como add,
+// ignora um Trace nil
+func (t *Trace) Add(stage, name, decision string, detail interface{}) {
+	t.add(stage, name, decision, detail)
+}
+
+// Explain executa o pipeline sem enviar a mensagem, registrando cada decisão e
+// comparando o payload final com o contrato da versão informada do backend
+func (p *Processor) Explain(messageBody []byte, contract client.Contract) *Trace {
+	return p.ExplainMessage(Message{Body: messageBody}, contract)
+}
+
+// ExplainMessage é como Explain, considerando os headers da mensagem
+func (p *Processor) ExplainMessage(msg Message, contract client.Contract) *Trace {
+	trace := &Trace{ReceivedAt: time.Now().UTC(), Steps: []TraceStep{}}
+
+	dispatched, err := p.dispatch(msg, trace)
+	if err != nil {
+		trace.reject(err)
+		return trace
+	}
+
+	// Registros meteorológicos são comparados ao contrato do backend; os
+	// demais tipos não passam por ele
+	trace.Outcome = OutcomeAccepted
+	var weatherLogs []models.WeatherLog
+	for _, record := range dispatched.Records {
+		switch r := record.(type) {
+		case models.WeatherLog:
+			weatherLogs = append(weatherLogs, r)
+		case models.AirQualityLog:
+			trace.AirQuality = &r
+		default:
+			trace.Records = append(trace.Records, r)
+		}
+	}
+	if len(weatherLogs) == 0 {
+		return trace
+	}
+	trace.Payload = &weatherLogs[0]
+	if len(weatherLogs) > 1 {
+		trace.Payloads = weatherLogs
+	}
+	for _, weatherLog := range weatherLogs {
+		report, err := contract.Check(weatherLog)
+		if err != nil {
+			trace.add(StageSend, "contract", "error", err.Error())
+			return trace
+		}
+		if trace.Contract == nil || (trace.Contract.Compatible && !report.Compatible) {
+			trace.Contract = &report
+		}
+	}
+	decision := "compatible"
+	if !trace.Contract.Compatible {
+		decision = "incompatible"
+	}
+	trace.add(StageSend, "contract", decision, trace.Contract.Version)
+	return trace
+}
+
+// reject registra a falha que encerrou o pipeline
+func (t *Trace) reject(err error) {
+	t.Outcome = OutcomeRejected
+	t.Error = err.Error()
+	if stageErr, ok := err.(*StageError); ok {
+		t.Stage = stageErr.Stage
+	}
+}
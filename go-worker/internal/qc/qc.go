@@ -0,0 +1,349 @@
+// Package qc implementa o controle de qualidade temporal por estação, nos
+// moldes das diretrizes da OMM para estações automáticas: teste de passo
+// (variação entre observações consecutivas), de pico (afastamento da mediana
+// recente) e de persistência (sensor travado). Os testes não rejeitam a
+// mensagem; produzem marcações e uma nota de qualidade.
+package qc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+// Testes aplicados a cada variável
+const (
+	TestStep        = "step"
+	TestSpike       = "spike"
+	TestPersistence = "persistence"
+)
+
+// Limits são os limites de uma variável, nas unidades do worker. Zero
+// desativa o teste correspondente.
+type Limits struct {
+	// Step é a variação máxima entre observações consecutivas
+	Step float64 `json:"step"`
+	// Spike é o afastamento máximo da mediana da janela de pico
+	Spike float64 `json:"spike"`
+	// Persistence é a variação mínima esperada na janela de persistência
+	Persistence float64 `json:"persistence"`
+	// Saturation isenta do teste de persistência as janelas inteiramente
+	// acima deste valor (umidade em nevoeiro); zero não isenta
+	Saturation float64 `json:"saturation,omitempty"`
+}
+
+// Config reúne as janelas e os limites por variável
+type Config struct {
+	// StepMaxGap é o intervalo máximo entre observações comparadas no passo
+	StepMaxGap time.Duration
+	// SpikeWindow é a janela da mediana do teste de pico
+	SpikeWindow time.Duration
+	// SpikeMinSamples é o mínimo de observações anteriores na janela de pico
+	SpikeMinSamples int
+	// PersistenceWindow é a janela do teste de persistência, que só roda
+	// quando as observações cobrem ao menos PersistenceMinSpan dela
+	PersistenceWindow  time.Duration
+	PersistenceMinSpan time.Duration
+	// PersistenceMinSamples é o mínimo de observações na janela de persistência
+	PersistenceMinSamples int
+	// MaxSamples limita as observações guardadas por estação
+	MaxSamples int
+	// Limits por variável (temperature, humidity, surface_pressure, pressure_msl, wind_speed)
+	Limits map[string]Limits
+}
+
+// DefaultConfig segue a ordem de grandeza dos limites da OMM para dados de
+// 10 a 60 minutos. Vento calmo por horas é normal, por isso o vento não passa
+// pelo teste de persistência.
+func DefaultConfig() Config {
+	return Config{
+		StepMaxGap:            time.Hour,
+		SpikeWindow:           time.Hour,
+		SpikeMinSamples:       3,
+		PersistenceWindow:     6 * time.Hour,
+		PersistenceMinSpan:    5 * time.Hour,
+		PersistenceMinSamples: 4,
+		MaxSamples:            512,
+		Limits: map[string]Limits{
+			"temperature":      {Step: 6, Spike: 6, Persistence: 0.1},
+			"humidity":         {Step: 30, Spike: 30, Persistence: 1, Saturation: 97},
+			"surface_pressure": {Step: 4, Spike: 4, Persistence: 0.1},
+			"pressure_msl":     {Step: 4, Spike: 4, Persistence: 0.1},
+			"wind_speed":       {Step: 40, Spike: 40},
+		},
+	}
+}
+
+// retention é por quanto tempo as observações de uma estação são guardadas
+func (c Config) retention() time.Duration {
+	return maxDuration(c.StepMaxGap, c.SpikeWindow, c.PersistenceWindow)
+}
+
+// variables lê as variáveis testadas da mensagem já normalizada
+var variables = []struct {
+	name  string
+	value func(w *models.WeatherMessage) *float64
+}{
+	{"temperature", func(w *models.WeatherMessage) *float64 { return &w.Current.Temperature }},
+	{"humidity", func(w *models.WeatherMessage) *float64 { return &w.Current.Humidity }},
+	{"surface_pressure", func(w *models.WeatherMessage) *float64 { return w.Current.SurfacePressure }},
+	{"pressure_msl", func(w *models.WeatherMessage) *float64 { return w.Current.PressureMSL }},
+	{"wind_speed", func(w *models.WeatherMessage) *float64 { return &w.Current.WindSpeed }},
+}
+
+// Sample é uma observação guardada na janela da estação
+type Sample struct {
+	Time   time.Time          `json:"t"`
+	Values map[string]float64 `json:"v"`
+}
+
+// Check é o resultado de um teste numa variável
+type Check struct {
+	Variable string  `json:"variable"`
+	Test     string  `json:"test"`
+	Value    float64 `json:"value"`
+	// Reference é o valor comparado: observação anterior, mediana ou variação da janela
+	Reference float64 `json:"reference"`
+	Limit     float64 `json:"limit"`
+	Passed    bool    `json:"passed"`
+}
+
+// Result reúne os testes executados para uma observação
+type Result struct {
+	Station string  `json:"station"`
+	Checks  []Check `json:"checks,omitempty"`
+	// Flags são os testes reprovados, no formato qc:<teste>:<variável>
+	Flags []string `json:"flags,omitempty"`
+	// Score é a fração dos testes executados que passaram; nil quando
+	// não houve histórico suficiente para nenhum teste
+	Score *float64 `json:"score,omitempty"`
+}
+
+// Checker guarda as janelas por estação e aplica os testes. É seguro para uso
+// concorrente.
+type Checker struct {
+	cfg Config
+
+	mu       sync.Mutex
+	stations map[string][]Sample
+}
+
+// NewChecker cria um Checker sem histórico
+func NewChecker(cfg Config) *Checker {
+	return &Checker{cfg: cfg, stations: make(map[string][]Sample)}
+}
+
+// Pending é a observação testada por Check, ainda fora do histórico da
+// estação. Commit a acrescenta à janela depois do envio do registro.
+type Pending struct {
+	station string
+	sample  Sample
+}
+
+// Check testa a observação contra o histórico da estação sem alterá-lo. Uma
+// observação com o mesmo horário de outra já guardada (reentrega) é testada
+// contra as demais.
+func (c *Checker) Check(msg *models.WeatherMessage, observed time.Time) (Result, Pending) {
+	key := msg.StationKey()
+	result := Result{Station: key}
+
+	current := Sample{Time: observed.UTC(), Values: make(map[string]float64, len(variables))}
+	for _, v := range variables {
+		if p := v.value(msg); p != nil {
+			current.Values[v.name] = *p
+		}
+	}
+
+	c.mu.Lock()
+	before, _ := split(c.stations[key], current.Time)
+	c.mu.Unlock()
+
+	for _, v := range variables {
+		value, ok := current.Values[v.name]
+		if !ok {
+			continue
+		}
+		limits := c.cfg.Limits[v.name]
+		result.Checks = append(result.Checks, c.tests(v.name, value, current.Time, limits, before)...)
+	}
+
+	passed := 0
+	for _, check := range result.Checks {
+		if check.Passed {
+			passed++
+			continue
+		}
+		result.Flags = append(result.Flags, fmt.Sprintf("qc:%s:%s", check.Test, check.Variable))
+	}
+	if len(result.Checks) > 0 {
+		score := math.Round(float64(passed)/float64(len(result.Checks))*100) / 100
+		result.Score = &score
+	}
+	return result, Pending{station: key, sample: current}
+}
+
+// Commit acrescenta à janela da estação a observação testada por Check,
+// substituindo a de mesmo horário. Registrar de novo a mesma observação não
+// altera o histórico.
+func (c *Checker) Commit(p Pending) {
+	if p.station == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	before, after := split(c.stations[p.station], p.sample.Time)
+	c.stations[p.station] = c.trim(append(append(before, p.sample), after...))
+}
+
+// Observe é Check seguido de Commit, para quem não depende de um envio
+func (c *Checker) Observe(msg *models.WeatherMessage, observed time.Time) Result {
+	result, pending := c.Check(msg, observed)
+	c.Commit(pending)
+	return result
+}
+
+// split separa o histórico ordenado em observações anteriores e posteriores a
+// t, descartando a de mesmo horário
+func split(history []Sample, t time.Time) (before, after []Sample) {
+	i := sort.Search(len(history), func(i int) bool { return !history[i].Time.Before(t) })
+	before = append([]Sample(nil), history[:i]...)
+	j := i
+	if j < len(history) && history[j].Time.Equal(t) {
+		j++
+	}
+	return before, append([]Sample(nil), history[j:]...)
+}
+
+// tests aplica os três testes a uma variável; testes sem histórico
+// suficiente não entram no resultado
+func (c *Checker) tests(name string, value float64, t time.Time, limits Limits, before []Sample) []Check {
+	var checks []Check
+
+	// Passo: observação anterior mais recente com a variável
+	if limits.Step > 0 {
+		for i := len(before) - 1; i >= 0; i-- {
+			prev, ok := before[i].Values[name]
+			if !ok {
+				continue
+			}
+			if t.Sub(before[i].Time) <= c.cfg.StepMaxGap {
+				checks = append(checks, Check{Variable: name, Test: TestStep, Value: value, Reference: prev,
+					Limit: limits.Step, Passed: math.Abs(value-prev) <= limits.Step})
+			}
+			break
+		}
+	}
+
+	// Pico: mediana das observações anteriores dentro da janela
+	if limits.Spike > 0 {
+		window := values(before, name, t.Add(-c.cfg.SpikeWindow))
+		if len(window) > 0 && len(window) >= c.cfg.SpikeMinSamples {
+			median := median(window)
+			checks = append(checks, Check{Variable: name, Test: TestSpike, Value: value, Reference: median,
+				Limit: limits.Spike, Passed: math.Abs(value-median) <= limits.Spike})
+		}
+	}
+
+	// Persistência: variação na janela, incluindo a observação atual
+	if limits.Persistence > 0 {
+		since := t.Add(-c.cfg.PersistenceWindow)
+		window := append(values(before, name, since), value)
+		oldest, ok := oldestSince(before, name, since)
+		if ok && len(window) >= c.cfg.PersistenceMinSamples && t.Sub(oldest) >= c.cfg.PersistenceMinSpan {
+			lo, hi := minMax(window)
+			saturated := limits.Saturation > 0 && lo >= limits.Saturation
+			if !saturated {
+				checks = append(checks, Check{Variable: name, Test: TestPersistence, Value: value, Reference: hi - lo,
+					Limit: limits.Persistence, Passed: hi-lo >= limits.Persistence})
+			}
+		}
+	}
+	return checks
+}
+
+// trim descarta as observações mais antigas que a retenção, contada a partir
+// da mais recente, e limita o total a MaxSamples
+func (c *Checker) trim(samples []Sample) []Sample {
+	if len(samples) == 0 {
+		return samples
+	}
+	cutoff := samples[len(samples)-1].Time.Add(-c.cfg.retention())
+	i := sort.Search(len(samples), func(i int) bool { return !samples[i].Time.Before(cutoff) })
+	if n := len(samples) - i; c.cfg.MaxSamples > 0 && n > c.cfg.MaxSamples {
+		i = len(samples) - c.cfg.MaxSamples
+	}
+	return samples[i:]
+}
+
+// Prune remove as estações sem observações dentro da retenção em relação a now
+func (c *Checker) Prune(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cutoff := now.Add(-c.cfg.retention())
+	removed := 0
+	for key, samples := range c.stations {
+		if len(samples) == 0 || samples[len(samples)-1].Time.Before(cutoff) {
+			delete(c.stations, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len retorna o número de estações com histórico
+func (c *Checker) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.stations)
+}
+
+func values(samples []Sample, name string, since time.Time) []float64 {
+	var out []float64
+	for _, s := range samples {
+		if v, ok := s.Values[name]; ok && !s.Time.Before(since) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func oldestSince(samples []Sample, name string, since time.Time) (time.Time, bool) {
+	for _, s := range samples {
+		if _, ok := s.Values[name]; ok && !s.Time.Before(since) {
+			return s.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func median(v []float64) float64 {
+	sorted := append([]float64(nil), v...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func minMax(v []float64) (lo, hi float64) {
+	lo, hi = v[0], v[0]
+	for _, x := range v[1:] {
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+	}
+	return lo, hi
+}
+
+func maxDuration(ds ...time.Duration) time.Duration {
+	var m time.Duration
+	for _, d := range ds {
+		if d > m {
+			m = d
+		}
+	}
+	return m
+}
@@ -0,0 +1,193 @@
+package qc
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+var start = time.Date(2025, 6, 15, 6, 0, 0, 0, time.UTC)
+
+type reading struct {
+	offset      time.Duration
+	temperature float64
+	humidity    float64
+}
+
+func observation(temperature, humidity float64) *models.WeatherMessage {
+	return &models.WeatherMessage{
+		Source:   "station",
+		Location: models.WeatherLocation{Latitude: -23.4962, Longitude: -46.62},
+		Current:  models.WeatherCurrent{Temperature: temperature, Humidity: humidity},
+	}
+}
+
+// series gera leituras a cada step com temperatura e umidade dadas por f
+func series(n int, step time.Duration, f func(i int) (float64, float64)) []reading {
+	out := make([]reading, n)
+	for i := range out {
+		t, h := f(i)
+		out[i] = reading{time.Duration(i) * step, t, h}
+	}
+	return out
+}
+
+func TestChecker_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   []reading
+		last      reading
+		wantFlags []string
+		wantScore *float64
+	}{
+		{
+			name:      "no history",
+			last:      reading{0, 25, 60},
+			wantScore: nil,
+		},
+		{
+			name:      "smooth warming passes",
+			history:   series(6, 10*time.Minute, func(i int) (float64, float64) { return 20 + 0.5*float64(i), 70 - float64(i) }),
+			last:      reading{time.Hour, 23.2, 63},
+			wantScore: models.Float64(1),
+		},
+		{
+			name:      "jump of 15 °C in ten minutes",
+			history:   series(6, 10*time.Minute, func(i int) (float64, float64) { return 20 + 0.2*float64(i), 60 + float64(i) }),
+			last:      reading{time.Hour, 36.2, 66},
+			wantFlags: []string{"qc:step:temperature", "qc:spike:temperature"},
+			wantScore: models.Float64(0.67),
+		},
+		{
+			name:      "step ignores observations older than the gap",
+			history:   []reading{{0, 10, 60}},
+			last:      reading{3 * time.Hour, 25, 60},
+			wantScore: nil,
+		},
+		{
+			name:      "stuck at 25.0 °C for six hours",
+			history:   series(36, 10*time.Minute, func(i int) (float64, float64) { return 25, 55 + float64(i%3) }),
+			last:      reading{6 * time.Hour, 25, 56},
+			wantFlags: []string{"qc:persistence:temperature"},
+			wantScore: models.Float64(0.88),
+		},
+		{
+			name:      "saturated humidity is exempt from persistence",
+			history:   series(36, 10*time.Minute, func(i int) (float64, float64) { return 12 + 0.05*float64(i), 100 }),
+			last:      reading{6 * time.Hour, 13.9, 100},
+			wantScore: models.Float64(1),
+		},
+		{
+			name:      "persistence needs the window covered",
+			history:   series(12, 10*time.Minute, func(i int) (float64, float64) { return 25, 55 + float64(i%3) }),
+			last:      reading{2 * time.Hour, 25, 56},
+			wantScore: models.Float64(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(DefaultConfig())
+			for _, r := range tt.history {
+				c.Observe(observation(r.temperature, r.humidity), start.Add(r.offset))
+			}
+			result := c.Observe(observation(tt.last.temperature, tt.last.humidity), start.Add(tt.last.offset))
+			if !reflect.DeepEqual(result.Flags, tt.wantFlags) {
+				t.Errorf("Flags = %v, want %v", result.Flags, tt.wantFlags)
+			}
+			if !reflect.DeepEqual(result.Score, tt.wantScore) {
+				t.Errorf("Score = %v, want %v (checks %+v)", deref(result.Score), deref(tt.wantScore), result.Checks)
+			}
+		})
+	}
+}
+
+func deref(p *float64) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func TestChecker_History(t *testing.T) {
+	t.Run("redelivery replaces the observation", func(t *testing.T) {
+		c := NewChecker(DefaultConfig())
+		c.Observe(observation(20, 60), start)
+		first := c.Observe(observation(35, 60), start.Add(10*time.Minute))
+		again := c.Observe(observation(35, 60), start.Add(10*time.Minute))
+		if !reflect.DeepEqual(first.Flags, again.Flags) || len(c.stations[observation(0, 0).StationKey()]) != 2 {
+			t.Errorf("redelivery flags = %v, first = %v, samples = %d", again.Flags, first.Flags, len(c.stations[observation(0, 0).StationKey()]))
+		}
+	})
+
+	t.Run("out of order observation compares with the previous one in time", func(t *testing.T) {
+		c := NewChecker(DefaultConfig())
+		c.Observe(observation(20, 60), start)
+		c.Observe(observation(30, 60), start.Add(20*time.Minute))
+		result := c.Observe(observation(20.5, 60), start.Add(10*time.Minute))
+		if len(result.Flags) != 0 || result.Checks[0].Reference != 20 {
+			t.Errorf("Observe() = %+v, want step against 20", result)
+		}
+	})
+
+	t.Run("check records only on commit", func(t *testing.T) {
+		c := NewChecker(DefaultConfig())
+		c.Observe(observation(20, 60), start)
+		_, pending := c.Check(observation(35, 60), start.Add(10*time.Minute))
+		if result, _ := c.Check(observation(20.4, 60), start.Add(20*time.Minute)); len(result.Flags) != 0 {
+			t.Errorf("Flags before Commit = %v, want none", result.Flags)
+		}
+		c.Commit(pending)
+		c.Commit(pending)
+		if result, _ := c.Check(observation(20.4, 60), start.Add(20*time.Minute)); !reflect.DeepEqual(result.Flags, []string{"qc:step:temperature"}) {
+			t.Errorf("Flags after Commit = %v, want step", result.Flags)
+		}
+		if n := len(c.stations[observation(0, 0).StationKey()]); n != 2 {
+			t.Errorf("samples = %d, want 2", n)
+		}
+	})
+
+	t.Run("stations are independent", func(t *testing.T) {
+		c := NewChecker(DefaultConfig())
+		c.Observe(observation(20, 60), start)
+		other := observation(35, 60)
+		other.Location.Latitude = -22.9
+		if result := c.Observe(other, start.Add(10*time.Minute)); result.Score != nil {
+			t.Errorf("Observe(other station) = %+v, want no history", result)
+		}
+	})
+
+	t.Run("prune drops idle stations", func(t *testing.T) {
+		c := NewChecker(DefaultConfig())
+		c.Observe(observation(20, 60), start)
+		if removed := c.Prune(start.Add(7 * time.Hour)); removed != 1 || c.Len() != 0 {
+			t.Errorf("Prune() = %d, Len() = %d; want 1, 0", removed, c.Len())
+		}
+	})
+}
+
+func TestChecker_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qc.json")
+
+	c := NewChecker(DefaultConfig())
+	c.Observe(observation(20, 60), start)
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	restored := NewChecker(DefaultConfig())
+	if err := restored.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	result := restored.Observe(observation(35, 60), start.Add(10*time.Minute))
+	if !reflect.DeepEqual(result.Flags, []string{"qc:step:temperature"}) {
+		t.Errorf("Flags after Load = %v, want step", result.Flags)
+	}
+
+	if err := NewChecker(DefaultConfig()).Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Load(missing) error = %v, want nil", err)
+	}
+}
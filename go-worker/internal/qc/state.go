@@ -0,0 +1,76 @@
+package qc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// stateVersion é a versão do formato do arquivo de estado
+const stateVersion = 1
+
+type state struct {
+	Version  int                 `json:"version"`
+	Stations map[string][]Sample `json:"stations"`
+}
+
+// Save grava as janelas das estações em path. O arquivo é escrito ao lado e
+// renomeado, para que uma falha no meio não corrompa o estado anterior.
+func (c *Checker) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.Marshal(state{Version: stateVersion, Stations: c.stations})
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+	return nil
+}
+
+// Load substitui as janelas pelas gravadas em path. Arquivo inexistente não é
+// erro: o worker começa sem histórico.
+func (c *Checker) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("estado do QC: %w", err)
+	}
+	if st.Version != stateVersion {
+		return fmt.Errorf("estado do QC: versão %d não suportada", st.Version)
+	}
+
+	stations := make(map[string][]Sample, len(st.Stations))
+	for key, samples := range st.Stations {
+		sort.Slice(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+		stations[key] = c.trim(samples)
+	}
+	c.mu.Lock()
+	c.stations = stations
+	c.mu.Unlock()
+	return nil
+}
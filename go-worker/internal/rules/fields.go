@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"math"
+
+	"go-worker/internal/models"
+)
+
+// field lê e corrige um campo numérico da mensagem já normalizada (°C, km/h,
+// hPa, m, mm); err é o sentinel usado nas violações do campo
+type field struct {
+	get func(w *models.WeatherMessage) (float64, bool)
+	set func(w *models.WeatherMessage, v float64)
+	err error
+}
+
+func value(ptr func(w *models.WeatherMessage) *float64, err error) field {
+	return field{
+		get: func(w *models.WeatherMessage) (float64, bool) { return *ptr(w), true },
+		set: func(w *models.WeatherMessage, v float64) { *ptr(w) = v },
+		err: err,
+	}
+}
+
+func optional(ptr func(w *models.WeatherMessage) **float64, err error) field {
+	return field{
+		get: func(w *models.WeatherMessage) (float64, bool) {
+			if p := *ptr(w); p != nil {
+				return *p, true
+			}
+			return 0, false
+		},
+		set: func(w *models.WeatherMessage, v float64) { *ptr(w) = models.Float64(v) },
+		err: err,
+	}
+}
+
+// fields são os campos aceitos em field, other e when
+var fields = map[string]field{
+	"location.latitude":  value(func(w *models.WeatherMessage) *float64 { return &w.Location.Latitude }, models.ErrInvalidLocation),
+	"location.longitude": value(func(w *models.WeatherMessage) *float64 { return &w.Location.Longitude }, models.ErrInvalidLocation),
+	"location.elevation": optional(func(w *models.WeatherMessage) **float64 { return &w.Location.Elevation }, nil),
+
+	"current.temperature":      value(func(w *models.WeatherMessage) *float64 { return &w.Current.Temperature }, models.ErrInvalidTemperature),
+	"current.humidity":         value(func(w *models.WeatherMessage) *float64 { return &w.Current.Humidity }, models.ErrInvalidHumidity),
+	"current.dew_point":        optional(func(w *models.WeatherMessage) **float64 { return &w.Current.DewPoint }, nil),
+	"current.wind_speed":       value(func(w *models.WeatherMessage) *float64 { return &w.Current.WindSpeed }, models.ErrInvalidWindSpeed),
+	"current.wind_gusts":       optional(func(w *models.WeatherMessage) **float64 { return &w.Current.WindGusts }, models.ErrInvalidWindSpeed),
+	"current.wind_direction":   optional(func(w *models.WeatherMessage) **float64 { return &w.Current.WindDirection }, models.ErrInvalidWindDirection),
+	"current.precipitation":    optional(func(w *models.WeatherMessage) **float64 { return &w.Current.Precipitation }, models.ErrInvalidPrecipitation),
+	"current.surface_pressure": optional(func(w *models.WeatherMessage) **float64 { return &w.Current.SurfacePressure }, models.ErrInvalidPressure),
+	"current.pressure_msl":     optional(func(w *models.WeatherMessage) **float64 { return &w.Current.PressureMSL }, models.ErrInvalidPressure),
+	"current.visibility":       optional(func(w *models.WeatherMessage) **float64 { return &w.Current.Visibility }, models.ErrInvalidVisibility),
+	"current.uv_index":         optional(func(w *models.WeatherMessage) **float64 { return &w.Current.UVIndex }, models.ErrInvalidUVIndex),
+	"current.cloud_cover":      optional(func(w *models.WeatherMessage) **float64 { return &w.Current.CloudCover }, models.ErrInvalidCloudCover),
+	"current.weather_code": {
+		get: func(w *models.WeatherMessage) (float64, bool) { return float64(w.Current.WeatherCode), true },
+		set: func(w *models.WeatherMessage, v float64) { w.Current.WeatherCode = int(math.Round(v)) },
+		err: models.ErrInvalidWeatherCode,
+	},
+}
@@ -0,0 +1,485 @@
+// Package rules implementa as regras de qualidade configuráveis: faixas por
+// campo e comparações entre campos, lidas de um arquivo JSON e sobrepostas por
+// região (REGIONS_FILE) ou zona climática. Cada regra rejeita a mensagem,
+// corrige o valor (clamp) ou apenas marca o log.
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-worker/internal/geo"
+	"go-worker/internal/models"
+)
+
+// Ações de uma regra
+const (
+	// ActionReject rejeita a mensagem com uma violação de validação (padrão)
+	ActionReject = "reject"
+	// ActionClamp traz o valor para o limite violado e marca o log
+	ActionClamp = "clamp"
+	// ActionFlag aceita a mensagem e marca o log com o nome da regra
+	ActionFlag = "flag"
+	// ActionOff desativa, numa sobreposição, a regra de mesmo nome
+	ActionOff = "off"
+)
+
+// ErrRuleViolation identifica as violações das regras configuradas; o
+// sentinel do campo (models.ErrInvalidTemperature, ...) também é reconhecido
+var ErrRuleViolation = errors.New("quality rule violated")
+
+// Spec é o arquivo de regras
+type Spec struct {
+	Zones     map[string]ZoneSpec `json:"zones,omitempty"`
+	Rules     []RuleSpec          `json:"rules"`
+	Overrides []OverrideSpec      `json:"overrides,omitempty"`
+}
+
+// ZoneSpec delimita uma zona climática por faixas de latitude, longitude e
+// altitude (m); faixas omitidas não restringem
+type ZoneSpec struct {
+	Latitude  *[2]float64 `json:"latitude,omitempty"`
+	Longitude *[2]float64 `json:"longitude,omitempty"`
+	Elevation *[2]float64 `json:"elevation,omitempty"`
+}
+
+// RuleSpec é uma regra. Cada regra usa exatamente um critério: min/max,
+// op com other (comparação com outro campo) ou in (faixas aceitas).
+type RuleSpec struct {
+	Name   string       `json:"name"`
+	Field  string       `json:"field,omitempty"`
+	Min    *float64     `json:"min,omitempty"`
+	Max    *float64     `json:"max,omitempty"`
+	Op     string       `json:"op,omitempty"`
+	Other  string       `json:"other,omitempty"`
+	In     [][2]float64 `json:"in,omitempty"`
+	When   *Condition   `json:"when,omitempty"`
+	Action string       `json:"action,omitempty"`
+}
+
+// Condition restringe a regra às mensagens em que field op value
+type Condition struct {
+	Field string  `json:"field"`
+	Op    string  `json:"op"`
+	Value float64 `json:"value"`
+}
+
+// OverrideSpec substitui ou desativa regras (pelo nome) e acrescenta regras
+// novas para as mensagens dentro de uma das regiões ou zonas listadas
+type OverrideSpec struct {
+	Name    string     `json:"name"`
+	Regions []string   `json:"regions,omitempty"`
+	Zones   []string   `json:"zones,omitempty"`
+	Rules   []RuleSpec `json:"rules"`
+}
+
+// Check é o resultado de uma regra para uma mensagem
+type Check struct {
+	Rule     string      `json:"rule"`
+	Field    string      `json:"field"`
+	Value    interface{} `json:"value"`
+	Action   string      `json:"action"`
+	Passed   bool        `json:"passed"`
+	Skipped  bool        `json:"skipped,omitempty"`
+	Clamped  *float64    `json:"clamped,omitempty"`
+	Override string      `json:"override,omitempty"`
+}
+
+// Result reúne as regras avaliadas, as marcas do log e as violações das
+// regras com ação reject
+type Result struct {
+	Checks     []Check
+	Flags      []string
+	Violations []models.Violation
+}
+
+type rule struct {
+	RuleSpec
+	field, other, when field
+	override           string
+}
+
+type zone struct {
+	name string
+	ZoneSpec
+}
+
+type override struct {
+	name    string
+	regions []string
+	zones   []zone
+	rules   []rule
+}
+
+// RuleSet é um conjunto de regras compilado. Um RuleSet nil não avalia nada.
+type RuleSet struct {
+	base      []rule
+	overrides []override
+	regions   *geo.RegionIndex
+}
+
+// Parse lê o JSON das regras; chaves desconhecidas são erro, para que um
+// campo com erro de digitação não desative uma regra em silêncio
+func Parse(data []byte) (Spec, error) {
+	var spec Spec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("regras: %w", err)
+	}
+	return spec, nil
+}
+
+// Load lê e compila o arquivo de regras. regions é o índice usado nas
+// sobreposições por região.
+func Load(path string, regions *geo.RegionIndex) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("regras: %w", err)
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(spec, regions)
+}
+
+// Compile valida as regras e as prepara para avaliação
+func Compile(spec Spec, regions *geo.RegionIndex) (*RuleSet, error) {
+	rs := &RuleSet{regions: regions}
+	var err error
+	if rs.base, err = compileRules(spec.Rules, "", false); err != nil {
+		return nil, err
+	}
+
+	for name, z := range spec.Zones {
+		for _, r := range []*[2]float64{z.Latitude, z.Longitude, z.Elevation} {
+			if r != nil && r[0] > r[1] {
+				return nil, fmt.Errorf("regras: zona %q: faixa %v invertida", name, *r)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, o := range spec.Overrides {
+		if o.Name == "" || seen[o.Name] {
+			return nil, fmt.Errorf("regras: sobreposição sem nome ou com nome repetido: %q", o.Name)
+		}
+		seen[o.Name] = true
+		if len(o.Regions) == 0 && len(o.Zones) == 0 {
+			return nil, fmt.Errorf("regras: sobreposição %q: informe regions ou zones", o.Name)
+		}
+		compiled := override{name: o.Name, regions: o.Regions}
+		for _, name := range o.Regions {
+			if !regions.Has(name) {
+				return nil, fmt.Errorf("regras: sobreposição %q: região %q não está em REGIONS_FILE", o.Name, name)
+			}
+		}
+		for _, name := range o.Zones {
+			z, ok := spec.Zones[name]
+			if !ok {
+				return nil, fmt.Errorf("regras: sobreposição %q: zona %q não definida", o.Name, name)
+			}
+			compiled.zones = append(compiled.zones, zone{name: name, ZoneSpec: z})
+		}
+		if compiled.rules, err = compileRules(o.Rules, o.Name, true); err != nil {
+			return nil, err
+		}
+		rs.overrides = append(rs.overrides, compiled)
+	}
+	return rs, nil
+}
+
+func compileRules(specs []RuleSpec, owner string, allowOff bool) ([]rule, error) {
+	prefix := "regras"
+	if owner != "" {
+		prefix = fmt.Sprintf("regras: sobreposição %q", owner)
+	}
+	seen := make(map[string]bool)
+	compiled := make([]rule, 0, len(specs))
+	for _, s := range specs {
+		if s.Name == "" || seen[s.Name] {
+			return nil, fmt.Errorf("%s: regra sem nome ou com nome repetido: %q", prefix, s.Name)
+		}
+		seen[s.Name] = true
+		r, err := compileRule(s, allowOff)
+		if err != nil {
+			return nil, fmt.Errorf("%s: regra %q: %w", prefix, s.Name, err)
+		}
+		r.override = owner
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+func compileRule(s RuleSpec, allowOff bool) (rule, error) {
+	if s.Action == "" {
+		s.Action = ActionReject
+	}
+	r := rule{RuleSpec: s}
+	switch s.Action {
+	case ActionReject, ActionClamp, ActionFlag:
+	case ActionOff:
+		if !allowOff {
+			return rule{}, fmt.Errorf("action off só vale em sobreposições")
+		}
+		return r, nil
+	default:
+		return rule{}, fmt.Errorf("action %q desconhecida (use reject, clamp, flag ou off)", s.Action)
+	}
+
+	var ok bool
+	if r.field, ok = fields[s.Field]; !ok {
+		return rule{}, fmt.Errorf("campo %q desconhecido", s.Field)
+	}
+
+	criteria := 0
+	if s.Min != nil || s.Max != nil {
+		criteria++
+		if s.Min != nil && s.Max != nil && *s.Min > *s.Max {
+			return rule{}, fmt.Errorf("min %g maior que max %g", *s.Min, *s.Max)
+		}
+	}
+	if s.Op != "" || s.Other != "" {
+		criteria++
+		if !validOp(s.Op) {
+			return rule{}, fmt.Errorf("op %q desconhecido (use <, <=, >, >=, == ou !=)", s.Op)
+		}
+		if r.other, ok = fields[s.Other]; !ok {
+			return rule{}, fmt.Errorf("campo %q desconhecido em other", s.Other)
+		}
+		if s.Action == ActionClamp && (s.Op == "<" || s.Op == ">" || s.Op == "!=") {
+			return rule{}, fmt.Errorf("clamp exige op <=, >= ou ==")
+		}
+	}
+	if len(s.In) > 0 {
+		criteria++
+		for _, interval := range s.In {
+			if interval[0] > interval[1] {
+				return rule{}, fmt.Errorf("faixa %v invertida em in", interval)
+			}
+		}
+		if s.Action == ActionClamp {
+			return rule{}, fmt.Errorf("clamp não se aplica a in")
+		}
+	}
+	if criteria != 1 {
+		return rule{}, fmt.Errorf("use exatamente um critério: min/max, op com other ou in")
+	}
+
+	if s.When != nil {
+		if r.when, ok = fields[s.When.Field]; !ok {
+			return rule{}, fmt.Errorf("campo %q desconhecido em when", s.When.Field)
+		}
+		if !validOp(s.When.Op) {
+			return rule{}, fmt.Errorf("op %q desconhecido em when", s.When.Op)
+		}
+	}
+	return r, nil
+}
+
+// Apply avalia as regras ativas para a mensagem, aplicando os clamps
+func (rs *RuleSet) Apply(msg *models.WeatherMessage) Result {
+	var result Result
+	if rs == nil {
+		return result
+	}
+	for _, r := range rs.active(msg) {
+		check := r.check(msg)
+		result.Checks = append(result.Checks, check)
+		if check.Passed {
+			continue
+		}
+		switch r.Action {
+		case ActionClamp:
+			result.Flags = append(result.Flags, r.Name+":clamped")
+		case ActionFlag:
+			result.Flags = append(result.Flags, r.Name)
+		default:
+			result.Violations = append(result.Violations, r.violation(check.Value))
+		}
+	}
+	return result
+}
+
+// active monta as regras da mensagem: as regras base com as sobreposições que
+// a contêm aplicadas na ordem do arquivo (a última vence)
+func (rs *RuleSet) active(msg *models.WeatherMessage) []rule {
+	var matched []override
+	lat, lon := msg.Location.Latitude, msg.Location.Longitude
+	var regionNames map[string]bool
+	elevation, elevationKnown, elevationResolved := 0.0, false, false
+	for _, o := range rs.overrides {
+		if len(o.regions) > 0 && regionNames == nil {
+			regionNames = make(map[string]bool)
+			for _, r := range rs.regions.Lookup(lat, lon) {
+				regionNames[r.Name] = true
+			}
+		}
+		in := false
+		for _, name := range o.regions {
+			in = in || regionNames[name]
+		}
+		for _, z := range o.zones {
+			if in {
+				break
+			}
+			if z.Elevation != nil && !elevationResolved {
+				elevation, _, elevationKnown = msg.ResolveElevation()
+				elevationResolved = true
+			}
+			in = z.contains(lat, lon, elevation, elevationKnown)
+		}
+		if in {
+			matched = append(matched, o)
+		}
+	}
+	if len(matched) == 0 {
+		return rs.base
+	}
+
+	active := append([]rule(nil), rs.base...)
+	for _, o := range matched {
+		for _, r := range o.rules {
+			i := indexOf(active, r.Name)
+			switch {
+			case i >= 0 && r.Action == ActionOff:
+				active = append(active[:i], active[i+1:]...)
+			case i >= 0:
+				active[i] = r
+			case r.Action != ActionOff:
+				active = append(active, r)
+			}
+		}
+	}
+	return active
+}
+
+func indexOf(rules []rule, name string) int {
+	for i, r := range rules {
+		if r.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (z zone) contains(lat, lon, elevation float64, elevationKnown bool) bool {
+	within := func(r *[2]float64, v float64) bool { return r == nil || (v >= r[0] && v <= r[1]) }
+	if z.Elevation != nil && !elevationKnown {
+		return false
+	}
+	return within(z.Latitude, lat) && within(z.Longitude, lon) && within(z.Elevation, elevation)
+}
+
+// check avalia a regra; campos ausentes (e when não atendido) não reprovam
+func (r rule) check(msg *models.WeatherMessage) Check {
+	c := Check{Rule: r.Name, Field: r.Field, Action: r.Action, Passed: true, Override: r.override}
+	if r.When != nil {
+		v, ok := r.when.get(msg)
+		if !ok || !compare(v, r.When.Op, r.When.Value) {
+			c.Skipped = true
+			return c
+		}
+	}
+	v, ok := r.field.get(msg)
+	if !ok {
+		c.Skipped = true
+		return c
+	}
+	c.Value = v
+
+	var limit float64
+	switch {
+	case r.Min != nil && v < *r.Min:
+		c.Passed, limit = false, *r.Min
+	case r.Max != nil && v > *r.Max:
+		c.Passed, limit = false, *r.Max
+	case r.Op != "":
+		other, ok := r.other.get(msg)
+		if !ok {
+			c.Skipped = true
+			return c
+		}
+		c.Passed, limit = compare(v, r.Op, other), other
+	case len(r.In) > 0:
+		c.Passed = false
+		for _, interval := range r.In {
+			if v >= interval[0] && v <= interval[1] {
+				c.Passed = true
+			}
+		}
+	}
+
+	if !c.Passed && r.Action == ActionClamp {
+		r.field.set(msg, limit)
+		c.Clamped = models.Float64(limit)
+	}
+	return c
+}
+
+// violation descreve a regra reprovada no formato das validações embutidas
+func (r rule) violation(value interface{}) models.Violation {
+	var msg string
+	switch {
+	case r.Min != nil && r.Max != nil:
+		msg = fmt.Sprintf("must be between %g and %g", *r.Min, *r.Max)
+	case r.Min != nil:
+		msg = fmt.Sprintf("must be at least %g", *r.Min)
+	case r.Max != nil:
+		msg = fmt.Sprintf("must be at most %g", *r.Max)
+	case r.Op != "":
+		msg = fmt.Sprintf("must be %s %s", r.Op, r.Other)
+	default:
+		ranges := make([]string, len(r.In))
+		for i, interval := range r.In {
+			ranges[i] = fmt.Sprintf("[%g,%g]", interval[0], interval[1])
+		}
+		msg = "must be in " + strings.Join(ranges, " or ")
+	}
+	if r.When != nil {
+		msg += fmt.Sprintf(" when %s %s %g", r.When.Field, r.When.Op, r.When.Value)
+	}
+
+	err := ErrRuleViolation
+	if r.field.err != nil {
+		err = errors.Join(ErrRuleViolation, r.field.err)
+	}
+	return models.Violation{
+		Field:   r.Field,
+		Value:   value,
+		Rule:    r.Name,
+		Message: r.Field + " " + msg,
+		Err:     err,
+	}
+}
+
+func validOp(op string) bool {
+	switch op {
+	case "<", "<=", ">", ">=", "==", "!=":
+		return true
+	}
+	return false
+}
+
+func compare(a float64, op string, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	}
+	return false
+}
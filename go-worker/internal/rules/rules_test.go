@@ -0,0 +1,225 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-worker/internal/geo"
+	"go-worker/internal/models"
+)
+
+const spec = `{
+  "zones": {
+    "serra": {"elevation": [900, 3000]},
+    "semiarido": {"latitude": [-17, -2], "longitude": [-45, -35]}
+  },
+  "rules": [
+    {"name": "temperatura", "field": "current.temperature", "min": -10, "max": 45},
+    {"name": "umidade", "field": "current.humidity", "max": 100, "action": "clamp"},
+    {"name": "orvalho", "field": "current.dew_point", "op": "<=", "other": "current.temperature", "action": "clamp"},
+    {"name": "chuva-sem-codigo", "field": "current.weather_code", "in": [[51, 67], [80, 82], [95, 99]],
+     "when": {"field": "current.precipitation", "op": ">", "value": 0}, "action": "flag"}
+  ],
+  "overrides": [
+    {"name": "serra", "zones": ["serra"], "rules": [
+      {"name": "temperatura", "field": "current.temperature", "min": -15, "max": 38},
+      {"name": "chuva-sem-codigo", "action": "off"}
+    ]},
+    {"name": "semiarido", "zones": ["semiarido"], "rules": [
+      {"name": "temperatura", "field": "current.temperature", "min": 5, "max": 48}
+    ]},
+    {"name": "fazenda", "regions": ["Fazenda Boa Vista"], "rules": [
+      {"name": "vento", "field": "current.wind_speed", "max": 80, "action": "flag"}
+    ]}
+  ]
+}`
+
+func testRegions(t *testing.T) *geo.RegionIndex {
+	t.Helper()
+	regions, err := geo.ParseRegions([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"name": "Fazenda Boa Vista"},
+		 "geometry": {"type": "Polygon", "coordinates": [[[-47.10, -22.95], [-47.00, -22.95], [-47.00, -22.85], [-47.10, -22.85], [-47.10, -22.95]]]}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseRegions() error = %v", err)
+	}
+	return geo.NewRegionIndex(regions)
+}
+
+func testRuleSet(t *testing.T) *RuleSet {
+	t.Helper()
+	s, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	rs, err := Compile(s, testRegions(t))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return rs
+}
+
+func message(lat, lon float64, elevation *float64, current models.WeatherCurrent) *models.WeatherMessage {
+	return &models.WeatherMessage{
+		Location: models.WeatherLocation{Latitude: lat, Longitude: lon, Elevation: elevation},
+		Current:  current,
+	}
+}
+
+func TestRuleSet_Apply(t *testing.T) {
+	rs := testRuleSet(t)
+
+	tests := []struct {
+		name           string
+		msg            *models.WeatherMessage
+		wantFlags      []string
+		wantViolations []string
+		check          func(t *testing.T, msg *models.WeatherMessage)
+	}{
+		{
+			name: "all rules pass",
+			msg:  message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 25, Humidity: 65, DewPoint: models.Float64(18)}),
+		},
+		{
+			name:           "reject outside range",
+			msg:            message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 46, Humidity: 40}),
+			wantViolations: []string{"temperatura"},
+		},
+		{
+			name:      "clamp humidity and dew point",
+			msg:       message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 20, Humidity: 100.4, DewPoint: models.Float64(20.3)}),
+			wantFlags: []string{"umidade:clamped", "orvalho:clamped"},
+			check: func(t *testing.T, msg *models.WeatherMessage) {
+				if msg.Current.Humidity != 100 || *msg.Current.DewPoint != 20 {
+					t.Errorf("humidity = %v, dew point = %v; want 100 and 20", msg.Current.Humidity, *msg.Current.DewPoint)
+				}
+			},
+		},
+		{
+			name:      "precipitation without rain code is flagged",
+			msg:       message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 22, Humidity: 90, Precipitation: models.Float64(1.2), WeatherCode: 3}),
+			wantFlags: []string{"chuva-sem-codigo"},
+		},
+		{
+			name: "precipitation with drizzle code passes",
+			msg:  message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 22, Humidity: 90, Precipitation: models.Float64(0.2), WeatherCode: 53}),
+		},
+		{
+			name: "dry reading skips the conditional rule",
+			msg:  message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 22, Humidity: 50, Precipitation: models.Float64(0), WeatherCode: 3}),
+		},
+		{
+			name:           "mountain zone tightens the range",
+			msg:            message(-22.75, -45.60, models.Float64(1600), models.WeatherCurrent{Temperature: 40, Humidity: 30}),
+			wantViolations: []string{"temperatura"},
+		},
+		{
+			name: "mountain zone turns the rule off",
+			msg:  message(-22.75, -45.60, models.Float64(1600), models.WeatherCurrent{Temperature: 10, Humidity: 95, Precipitation: models.Float64(2), WeatherCode: 3}),
+		},
+		{
+			name: "semiarid zone widens the range",
+			msg:  message(-9.40, -40.50, models.Float64(380), models.WeatherCurrent{Temperature: 46, Humidity: 20}),
+		},
+		{
+			name:      "region override adds a rule",
+			msg:       message(-22.90, -47.05, models.Float64(680), models.WeatherCurrent{Temperature: 25, Humidity: 60, WindSpeed: 95}),
+			wantFlags: []string{"vento"},
+		},
+		{
+			name: "region rule does not apply outside it",
+			msg:  message(-23.55, -46.63, models.Float64(760), models.WeatherCurrent{Temperature: 25, Humidity: 60, WindSpeed: 95}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rs.Apply(tt.msg)
+			if !reflect.DeepEqual(result.Flags, tt.wantFlags) {
+				t.Errorf("Flags = %v, want %v", result.Flags, tt.wantFlags)
+			}
+			var violations []string
+			for _, v := range result.Violations {
+				violations = append(violations, v.Rule)
+			}
+			if !reflect.DeepEqual(violations, tt.wantViolations) {
+				t.Errorf("Violations = %v, want %v", result.Violations, tt.wantViolations)
+			}
+			if tt.check != nil {
+				tt.check(t, tt.msg)
+			}
+		})
+	}
+}
+
+func TestRuleSet_Violation(t *testing.T) {
+	rs := testRuleSet(t)
+	result := rs.Apply(message(-22.75, -45.60, models.Float64(1600), models.WeatherCurrent{Temperature: 40, Humidity: 30}))
+	if len(result.Violations) != 1 {
+		t.Fatalf("Violations = %v, want 1", result.Violations)
+	}
+
+	v := result.Violations[0]
+	if v.Field != "current.temperature" || v.Value != 40.0 || v.Message != "current.temperature must be between -15 and 38" {
+		t.Errorf("violation = %+v", v)
+	}
+	for _, sentinel := range []error{ErrRuleViolation, models.ErrInvalidTemperature} {
+		if !errors.Is(v.Err, sentinel) {
+			t.Errorf("errors.Is(%v, %v) = false", v.Err, sentinel)
+		}
+	}
+	for _, c := range result.Checks {
+		if c.Rule == "temperatura" && c.Override != "serra" {
+			t.Errorf("check %+v, want override serra", c)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"unknown key", `{"rules": [{"name": "t", "field": "current.temperature", "maxx": 40}]}`, "unknown field"},
+		{"unknown field", `{"rules": [{"name": "t", "field": "current.temp", "max": 40}]}`, "desconhecido"},
+		{"unknown action", `{"rules": [{"name": "t", "field": "current.temperature", "max": 40, "action": "drop"}]}`, "action"},
+		{"duplicated name", `{"rules": [{"name": "t", "field": "current.temperature", "max": 40}, {"name": "t", "field": "current.humidity", "max": 100}]}`, "repetido"},
+		{"no criterion", `{"rules": [{"name": "t", "field": "current.temperature"}]}`, "critério"},
+		{"two criteria", `{"rules": [{"name": "t", "field": "current.temperature", "max": 40, "in": [[0, 10]]}]}`, "critério"},
+		{"inverted range", `{"rules": [{"name": "t", "field": "current.temperature", "min": 40, "max": 10}]}`, "maior"},
+		{"clamp strict op", `{"rules": [{"name": "t", "field": "current.dew_point", "op": "<", "other": "current.temperature", "action": "clamp"}]}`, "clamp"},
+		{"clamp set", `{"rules": [{"name": "t", "field": "current.weather_code", "in": [[0, 3]], "action": "clamp"}]}`, "clamp"},
+		{"off outside override", `{"rules": [{"name": "t", "action": "off"}]}`, "off"},
+		{"unknown zone", `{"rules": [], "overrides": [{"name": "o", "zones": ["litoral"], "rules": []}]}`, "litoral"},
+		{"unknown region", `{"rules": [], "overrides": [{"name": "o", "regions": ["Sítio"], "rules": []}]}`, "Sítio"},
+		{"override without target", `{"rules": [], "overrides": [{"name": "o", "rules": []}]}`, "regions ou zones"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse([]byte(tt.spec))
+			if err == nil {
+				_, err = Compile(s, testRegions(t))
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSet_Nil(t *testing.T) {
+	var rs *RuleSet
+	if result := rs.Apply(message(0, 0, nil, models.WeatherCurrent{Temperature: 99})); result.Checks != nil {
+		t.Errorf("Apply() = %+v, want empty", result)
+	}
+}
+
+func TestLoad_Example(t *testing.T) {
+	if _, err := Load("../../rules.example.json", nil); err != nil {
+		t.Errorf("Load(rules.example.json) error = %v", err)
+	}
+}
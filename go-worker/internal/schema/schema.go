@@ -0,0 +1,248 @@
+// Package schema versiona a mensagem canônica publicada pelo coletor Python.
+// A versão vem do campo schema_version ou do header x-schema-version; as
+// mensagens de versões anteriores passam, em cadeia, pelos upcasters até a
+// versão atual antes de serem decodificadas, e versões futuras são rejeitadas.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-worker/internal/models"
+)
+
+const (
+	// Field é o campo de primeiro nível com a versão do schema
+	Field = "schema_version"
+	// Legacy é a versão das mensagens sem schema_version, publicadas antes
+	// do versionamento
+	Legacy = 1
+	// Current é a versão decodificada pelo worker
+	Current = 2
+)
+
+var (
+	// ErrUnsupportedVersion indica uma versão posterior a Current
+	ErrUnsupportedVersion = errors.New("versão de schema não suportada")
+	// ErrInvalidVersion indica uma versão que não é um inteiro positivo ou
+	// que diverge entre o campo e o header
+	ErrInvalidVersion = errors.New("versão de schema inválida")
+	// ErrMissingField indica, no modo estrito, um campo obrigatório ausente
+	ErrMissingField = errors.New("campo obrigatório ausente")
+)
+
+// Upcaster converte um documento da versão From para a versão From+1
+type Upcaster struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// upcasters é a cadeia de conversões, uma para cada versão anterior a Current
+var upcasters = []Upcaster{
+	{From: 1, Description: "timestamp sem fuso (datetime.utcnow) passa a UTC explícito", Apply: upcastV1},
+}
+
+// upcastV1 torna explícito o UTC do timestamp da v1, que o coletor gerava com
+// datetime.utcnow().isoformat(), sem fuso. Valores inválidos ficam para a
+// validação.
+func upcastV1(doc map[string]any) error {
+	ts, ok := doc["timestamp"].(string)
+	if !ok || ts == "" {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+		return nil
+	}
+	if t, err := models.ParseTimestamp(ts); err == nil {
+		doc["timestamp"] = t.Format(time.RFC3339Nano)
+	}
+	return nil
+}
+
+// Options ajusta a decodificação da mensagem canônica
+type Options struct {
+	// Strict rejeita campos desconhecidos e campos obrigatórios ausentes, em
+	// vez de decodificá-los com o valor zero
+	Strict bool
+	// Repair corrige o documento já na versão atual, antes das verificações do
+	// modo estrito, e retorna as marcações dos reparos (opcional)
+	Repair func(doc map[string]any) []string
+}
+
+// Decode lê a mensagem canônica na versão declarada, aplica os upcasters até
+// Current e a converte em WeatherMessage. header é o valor do header
+// x-schema-version (vazio quando ausente).
+func Decode(body []byte, header string, opts Options) (models.WeatherMessage, error) {
+	doc, err := parse(body)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	version, err := declared(doc, header)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	if err := upcast(doc, version, Current, upcasters); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	// type é do envelope: escolhe o handler e não faz parte da mensagem
+	delete(doc, "type")
+	var repairs []string
+	if opts.Repair != nil {
+		repairs = opts.Repair(doc)
+	}
+	if opts.Strict {
+		if missing := missingFields(doc); len(missing) > 0 {
+			return models.WeatherMessage{}, fmt.Errorf("%w: %s", ErrMissingField, strings.Join(missing, ", "))
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return models.WeatherMessage{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if opts.Strict {
+		dec.DisallowUnknownFields()
+	}
+	var msg models.WeatherMessage
+	if err := dec.Decode(&msg); err != nil {
+		return models.WeatherMessage{}, err
+	}
+	msg.Repairs = repairs
+	return msg, nil
+}
+
+// parse lê o documento preservando os números como no payload
+func parse(body []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("dados após o objeto JSON")
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	return doc, nil
+}
+
+// declared resolve a versão da mensagem: header, campo ou, sem nenhum dos
+// dois, Legacy. Quando ambos estão presentes, precisam coincidir.
+func declared(doc map[string]any, header string) (int, error) {
+	version := 0
+	if header = strings.TrimSpace(header); header != "" {
+		v, err := strconv.Atoi(header)
+		if err != nil {
+			return 0, fmt.Errorf("%w: header %q", ErrInvalidVersion, header)
+		}
+		version = v
+	}
+	if raw, ok := doc[Field]; ok {
+		v, err := fieldVersion(raw)
+		if err != nil {
+			return 0, err
+		}
+		if version != 0 && v != version {
+			return 0, fmt.Errorf("%w: %s=%d diverge do header (%d)", ErrInvalidVersion, Field, v, version)
+		}
+		version = v
+	}
+
+	switch {
+	case version == 0 && header == "" && doc[Field] == nil:
+		return Legacy, nil
+	case version < 1:
+		return 0, fmt.Errorf("%w: %d", ErrInvalidVersion, version)
+	case version > Current:
+		return 0, fmt.Errorf("%w: %d (o worker suporta até %d)", ErrUnsupportedVersion, version, Current)
+	}
+	return version, nil
+}
+
+// fieldVersion aceita a versão como número inteiro ou texto
+func fieldVersion(raw any) (int, error) {
+	var text string
+	switch v := raw.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = v
+	default:
+		return 0, fmt.Errorf("%w: %s=%v", ErrInvalidVersion, Field, raw)
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s=%q", ErrInvalidVersion, Field, text)
+	}
+	return version, nil
+}
+
+// upcast aplica, em ordem, os upcasters de from até to
+func upcast(doc map[string]any, from, to int, chain []Upcaster) error {
+	for v := from; v < to; v++ {
+		u, ok := find(chain, v)
+		if !ok {
+			return fmt.Errorf("%w: sem upcaster da versão %d", ErrUnsupportedVersion, v)
+		}
+		if err := u.Apply(doc); err != nil {
+			return fmt.Errorf("upcast da versão %d: %w", v, err)
+		}
+	}
+	doc[Field] = to
+	return nil
+}
+
+func find(chain []Upcaster, from int) (Upcaster, bool) {
+	for _, u := range chain {
+		if u.From == from {
+			return u, true
+		}
+	}
+	return Upcaster{}, false
+}
+
+// missingFields lista os campos obrigatórios ausentes ou nulos: timestamp,
+// coordenadas e, salvo nas mensagens só de previsão, temperatura, umidade,
+// vento e código do tempo, que o log sempre envia
+func missingFields(doc map[string]any) []string {
+	var missing []string
+	require := func(obj map[string]any, prefix string, keys ...string) {
+		for _, k := range keys {
+			if obj[k] == nil {
+				missing = append(missing, prefix+k)
+			}
+		}
+	}
+
+	require(doc, "", "timestamp", "location")
+	if location, ok := doc["location"].(map[string]any); ok {
+		require(location, "location.", "latitude", "longitude")
+	}
+	forecastOnly := doc["current"] == nil && (doc["hourly"] != nil || doc["daily"] != nil)
+	if !forecastOnly {
+		current, _ := doc["current"].(map[string]any)
+		if current == nil {
+			missing = append(missing, "current")
+		} else {
+			require(current, "current.", "temperature", "humidity", "weather_code", "wind_speed")
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Upcasters lista a cadeia de conversões, para documentação e diagnóstico
+func Upcasters() []Upcaster {
+	return append([]Upcaster(nil), upcasters...)
+}
@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const observation = `"location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":25.3,"humidity":65,"wind_speed":12.5,"weather_code":2}`
+
+func TestDecode_Versions(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		header        string
+		wantTimestamp string
+		wantErr       error
+	}{
+		{"legacy without version", `{"timestamp":"2025-06-15T14:30:00.123456",` + observation + `}`, "", "2025-06-15T14:30:00.123456Z", nil},
+		{"legacy keeps explicit offset", `{"timestamp":"2025-06-15T11:30:00-03:00",` + observation + `}`, "", "2025-06-15T11:30:00-03:00", nil},
+		{"current field", `{"schema_version":2,"timestamp":"2025-06-15T14:30:00+00:00",` + observation + `}`, "", "2025-06-15T14:30:00+00:00", nil},
+		{"version as text", `{"schema_version":"1","timestamp":"2025-06-15T14:30:00",` + observation + `}`, "", "2025-06-15T14:30:00Z", nil},
+		{"header", `{"timestamp":"2025-06-15T14:30:00",` + observation + `}`, "2", "2025-06-15T14:30:00", nil},
+		{"header agrees with field", `{"schema_version":1,"timestamp":"2025-06-15T14:30:00",` + observation + `}`, "1", "2025-06-15T14:30:00Z", nil},
+		{"future version", `{"schema_version":3,` + observation + `}`, "", "", ErrUnsupportedVersion},
+		{"future version in header", `{` + observation + `}`, "9", "", ErrUnsupportedVersion},
+		{"zero version", `{"schema_version":0,` + observation + `}`, "", "", ErrInvalidVersion},
+		{"fractional version", `{"schema_version":1.5,` + observation + `}`, "", "", ErrInvalidVersion},
+		{"header disagrees with field", `{"schema_version":1,` + observation + `}`, "2", "", ErrInvalidVersion},
+		{"invalid header", `{` + observation + `}`, "v2", "", ErrInvalidVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Decode([]byte(tt.body), tt.header, Options{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if msg.SchemaVersion != Current {
+				t.Errorf("SchemaVersion = %d, want %d", msg.SchemaVersion, Current)
+			}
+			if msg.Timestamp != tt.wantTimestamp {
+				t.Errorf("Timestamp = %q, want %q", msg.Timestamp, tt.wantTimestamp)
+			}
+			if msg.Current.Temperature != 25.3 {
+				t.Errorf("Temperature = %v, want 25.3", msg.Current.Temperature)
+			}
+		})
+	}
+}
+
+func TestDecode_Strict(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"complete", `{"schema_version":2,"timestamp":"2025-06-15T14:30:00Z",` + observation + `}`, ""},
+		{"type field belongs to the envelope", `{"type":"observation","timestamp":"2025-06-15T14:30:00Z",` + observation + `}`, ""},
+		{"forecast only", `{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"hourly":{"time":[]}}`, ""},
+		{"unknown field", `{"timestamp":"2025-06-15T14:30:00Z","temp":25,` + observation + `}`, `unknown field "temp"`},
+		{"unknown nested field", `{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63,"lat":1},"current":{"temperature":25.3,"humidity":65,"wind_speed":12.5,"weather_code":2}}`, `unknown field "lat"`},
+		{"missing fields", `{"location":{"latitude":-23.55},"current":{"temperature":null}}`, "current.humidity, current.temperature, current.weather_code, current.wind_speed, location.longitude, timestamp"},
+		{"missing wind and weather code", `{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63},"current":{"temperature":25.3,"humidity":65}}`, "current.weather_code, current.wind_speed"},
+		{"missing current", `{"timestamp":"2025-06-15T14:30:00Z","location":{"latitude":-23.55,"longitude":-46.63}}`, "current"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.body), "", Options{Strict: true})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Fora do modo estrito os mesmos payloads chegam à validação com valores zero
+	msg, err := Decode([]byte(`{"temp":25,"location":{"latitude":-23.55}}`), "", Options{})
+	if err != nil || msg.Current.Temperature != 0 {
+		t.Errorf("Decode(non-strict) = %+v, %v", msg, err)
+	}
+}
+
+func TestUpcast_Chain(t *testing.T) {
+	var applied []int
+	step := func(from int) Upcaster {
+		return Upcaster{From: from, Apply: func(doc map[string]any) error {
+			applied = append(applied, from)
+			doc[fmt.Sprintf("v%d", from+1)] = true
+			return nil
+		}}
+	}
+	chain := []Upcaster{step(3), step(1), step(2)}
+
+	doc := map[string]any{}
+	if err := upcast(doc, 1, 4, chain); err != nil {
+		t.Fatalf("upcast() error = %v", err)
+	}
+	if !reflect.DeepEqual(applied, []int{1, 2, 3}) {
+		t.Errorf("applied = %v, want [1 2 3]", applied)
+	}
+	if doc[Field] != 4 || doc["v4"] != true {
+		t.Errorf("doc = %v", doc)
+	}
+
+	if err := upcast(map[string]any{}, 1, 5, chain); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("upcast(gap) error = %v, want %v", err, ErrUnsupportedVersion)
+	}
+
+	failing := []Upcaster{{From: 1, Apply: func(map[string]any) error { return errors.New("boom") }}}
+	if err := upcast(map[string]any{}, 1, 2, failing); err == nil || !strings.Contains(err.Error(), "versão 1") {
+		t.Errorf("upcast(failing) error = %v", err)
+	}
+
+	// A cadeia embutida cobre todas as versões anteriores à atual
+	for v := Legacy; v < Current; v++ {
+		if _, ok := find(Upcasters(), v); !ok {
+			t.Errorf("sem upcaster da versão %d", v)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package units
+
+import "fmt"
+
+// MolarVolume é o volume molar do ar a 25 °C e 1 atm (L/mol), a referência
+// da EPA para converter razões de mistura (ppb, ppm) em µg/m³
+const MolarVolume = 24.45
+
+// mixingRatios são as razões de mistura aceitas, em ppb
+var mixingRatios = map[string]float64{
+	"ppb": 1, "ppbv": 1,
+	"ppm": 1000, "ppmv": 1000,
+}
+
+// Concentration é uma concentração em massa em µg/m³
+type Concentration float64
+
+// NewConcentration cria uma concentração a partir de µg/m³, mg/m³, ng/m³ ou,
+// para gases, ppb e ppm; molarMass (g/mol) só é usada nas razões de mistura
+func NewConcentration(value float64, unit string, molarMass float64) (Concentration, error) {
+	if ppb, ok := mixingRatios[normalizeSymbol(unit)]; ok {
+		if molarMass <= 0 {
+			return 0, fmt.Errorf("%w: %q exige a massa molar do poluente", ErrUnknownUnit, unit)
+		}
+		return Concentration(value * ppb * molarMass / MolarVolume), nil
+	}
+	v, err := ToBase(DimConcentration, value, unit)
+	return Concentration(v), err
+}
+
+// MicrogramsPerCubicMeter retorna a concentração em µg/m³
+func (c Concentration) MicrogramsPerCubicMeter() float64 { return float64(c) }
+
+// PPB retorna a razão de mistura em ppb de um gás com a massa molar informada
+func (c Concentration) PPB(molarMass float64) float64 {
+	return float64(c) * MolarVolume / molarMass
+}
+
+// PPM retorna a razão de mistura em ppm de um gás com a massa molar informada
+func (c Concentration) PPM(molarMass float64) float64 {
+	return c.PPB(molarMass) / 1000
+}
@@ -0,0 +1,215 @@
+// Package units define grandezas com unidade (temperatura, velocidade, pressão,
+// comprimento, precipitação e concentração) e as conversões entre as unidades
+// usadas pelos provedores de tempo. Cada grandeza guarda o valor na unidade
+// base do worker: °C, km/h, hPa, m, mm e µg/m³.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownUnit indica uma unidade que não pertence à grandeza
+var ErrUnknownUnit = errors.New("unidade desconhecida")
+
+// Dimensões suportadas
+const (
+	DimTemperature   = "temperature"
+	DimSpeed         = "speed"
+	DimPressure      = "pressure"
+	DimLength        = "length"
+	DimPrecipitation = "precipitation"
+	DimConcentration = "concentration"
+)
+
+// scale converte uma unidade para a base: base = valor*factor + offset
+type scale struct {
+	factor, offset float64
+}
+
+// table reúne as unidades de uma dimensão, indexadas pelo símbolo normalizado
+type table struct {
+	dimension string
+	base      string
+	units     map[string]scale
+}
+
+var tables = map[string]table{
+	DimTemperature: {DimTemperature, "°C", map[string]scale{
+		"°c": {1, 0}, "c": {1, 0}, "celsius": {1, 0}, "degc": {1, 0},
+		"°f": {5.0 / 9, -32 * 5.0 / 9}, "f": {5.0 / 9, -32 * 5.0 / 9}, "fahrenheit": {5.0 / 9, -32 * 5.0 / 9}, "degf": {5.0 / 9, -32 * 5.0 / 9},
+		"k": {1, -273.15}, "kelvin": {1, -273.15},
+	}},
+	DimSpeed: {DimSpeed, "km/h", map[string]scale{
+		"km/h": {1, 0}, "kmh": {1, 0}, "kph": {1, 0},
+		"m/s": {3.6, 0},
+		"mph": {1.609344, 0}, "mi/h": {1.609344, 0},
+		"kn": {1.852, 0}, "kt": {1.852, 0}, "knots": {1.852, 0},
+		"ft/s": {1.09728, 0},
+	}},
+	DimPressure: {DimPressure, "hPa", map[string]scale{
+		"hpa": {1, 0}, "mbar": {1, 0}, "mb": {1, 0},
+		"pa": {0.01, 0}, "kpa": {10, 0},
+		"inhg": {33.8638866667, 0}, "mmhg": {1.33322387415, 0},
+		"psi": {68.9475729318, 0},
+	}},
+	DimLength: {DimLength, "m", map[string]scale{
+		"m": {1, 0}, "km": {1000, 0},
+		"ft": {0.3048, 0}, "feet": {0.3048, 0},
+		"mi": {1609.344, 0}, "nmi": {1852, 0},
+	}},
+	DimPrecipitation: {DimPrecipitation, "mm", map[string]scale{
+		"mm": {1, 0}, "cm": {10, 0},
+		"in": {25.4, 0}, "inch": {25.4, 0}, "inches": {25.4, 0},
+	}},
+	// Concentração em massa; ppb e ppm dependem do poluente (NewConcentration)
+	DimConcentration: {DimConcentration, "µg/m³", map[string]scale{
+		"µg/m3": {1, 0}, "ug/m3": {1, 0}, "mcg/m3": {1, 0},
+		"mg/m3": {1000, 0}, "ng/m3": {0.001, 0},
+	}},
+}
+
+// normalizeSymbol aceita variações de caixa, espaços, o sinal de grau "º", o
+// mu grego no lugar do sinal de micro (a Open-Meteo usa "μg/m³") e "³" ou "3"
+func normalizeSymbol(unit string) string {
+	s := strings.ToLower(strings.TrimSpace(unit))
+	s = strings.NewReplacer("º", "°", "μ", "µ", "³", "3").Replace(s)
+	return strings.ReplaceAll(s, " ", "")
+}
+
+func (t table) lookup(unit string) (scale, error) {
+	sc, ok := t.units[normalizeSymbol(unit)]
+	if !ok {
+		return scale{}, fmt.Errorf("%w: %q para %s", ErrUnknownUnit, unit, t.dimension)
+	}
+	return sc, nil
+}
+
+// ToBase converte um valor na unidade informada para a unidade base da dimensão
+func ToBase(dimension string, value float64, unit string) (float64, error) {
+	t, ok := tables[dimension]
+	if !ok {
+		return 0, fmt.Errorf("dimensão desconhecida: %q", dimension)
+	}
+	sc, err := t.lookup(unit)
+	if err != nil {
+		return 0, err
+	}
+	return value*sc.factor + sc.offset, nil
+}
+
+// FromBase converte um valor da unidade base da dimensão para a unidade informada
+func FromBase(dimension string, value float64, unit string) (float64, error) {
+	t, ok := tables[dimension]
+	if !ok {
+		return 0, fmt.Errorf("dimensão desconhecida: %q", dimension)
+	}
+	sc, err := t.lookup(unit)
+	if err != nil {
+		return 0, err
+	}
+	return (value - sc.offset) / sc.factor, nil
+}
+
+// IsBase indica se a unidade já é a base da dimensão (por exemplo "°C" ou "celsius")
+func IsBase(dimension, unit string) bool {
+	t, ok := tables[dimension]
+	if !ok {
+		return false
+	}
+	sc, err := t.lookup(unit)
+	return err == nil && sc == scale{1, 0}
+}
+
+// Base retorna o símbolo da unidade base da dimensão
+func Base(dimension string) string {
+	return tables[dimension].base
+}
+
+// Temperature é uma temperatura em °C
+type Temperature float64
+
+// NewTemperature cria uma temperatura a partir de um valor em °C, °F ou K
+func NewTemperature(value float64, unit string) (Temperature, error) {
+	v, err := ToBase(DimTemperature, value, unit)
+	return Temperature(v), err
+}
+
+// Celsius retorna a temperatura em °C
+func (t Temperature) Celsius() float64 { return float64(t) }
+
+// Fahrenheit retorna a temperatura em °F
+func (t Temperature) Fahrenheit() float64 { return float64(t)*9/5 + 32 }
+
+// Kelvin retorna a temperatura em K
+func (t Temperature) Kelvin() float64 { return float64(t) + 273.15 }
+
+// Speed é uma velocidade em km/h
+type Speed float64
+
+// NewSpeed cria uma velocidade a partir de um valor em km/h, m/s, mph ou nós
+func NewSpeed(value float64, unit string) (Speed, error) {
+	v, err := ToBase(DimSpeed, value, unit)
+	return Speed(v), err
+}
+
+// KilometersPerHour retorna a velocidade em km/h
+func (s Speed) KilometersPerHour() float64 { return float64(s) }
+
+// MetersPerSecond retorna a velocidade em m/s
+func (s Speed) MetersPerSecond() float64 { return float64(s) / 3.6 }
+
+// MilesPerHour retorna a velocidade em mph
+func (s Speed) MilesPerHour() float64 { return float64(s) / 1.609344 }
+
+// Knots retorna a velocidade em nós
+func (s Speed) Knots() float64 { return float64(s) / 1.852 }
+
+// Pressure é uma pressão em hPa
+type Pressure float64
+
+// NewPressure cria uma pressão a partir de um valor em hPa, Pa, kPa, inHg ou mmHg
+func NewPressure(value float64, unit string) (Pressure, error) {
+	v, err := ToBase(DimPressure, value, unit)
+	return Pressure(v), err
+}
+
+// Hectopascals retorna a pressão em hPa
+func (p Pressure) Hectopascals() float64 { return float64(p) }
+
+// InchesOfMercury retorna a pressão em inHg
+func (p Pressure) InchesOfMercury() float64 { return float64(p) / 33.8638866667 }
+
+// Length é um comprimento em metros
+type Length float64
+
+// NewLength cria um comprimento a partir de um valor em m, km, ft ou mi
+func NewLength(value float64, unit string) (Length, error) {
+	v, err := ToBase(DimLength, value, unit)
+	return Length(v), err
+}
+
+// Meters retorna o comprimento em m
+func (l Length) Meters() float64 { return float64(l) }
+
+// Kilometers retorna o comprimento em km
+func (l Length) Kilometers() float64 { return float64(l) / 1000 }
+
+// Feet retorna o comprimento em pés
+func (l Length) Feet() float64 { return float64(l) / 0.3048 }
+
+// Precipitation é uma lâmina de precipitação em mm
+type Precipitation float64
+
+// NewPrecipitation cria uma precipitação a partir de um valor em mm, cm ou polegadas
+func NewPrecipitation(value float64, unit string) (Precipitation, error) {
+	v, err := ToBase(DimPrecipitation, value, unit)
+	return Precipitation(v), err
+}
+
+// Millimeters retorna a precipitação em mm
+func (p Precipitation) Millimeters() float64 { return float64(p) }
+
+// Inches retorna a precipitação em polegadas
+func (p Precipitation) Inches() float64 { return float64(p) / 25.4 }
@@ -0,0 +1,118 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestToBase(t *testing.T) {
+	tests := []struct {
+		dimension string
+		value     float64
+		unit      string
+		want      float64
+	}{
+		{DimTemperature, 25, "°C", 25},
+		{DimTemperature, 212, "°F", 100},
+		{DimTemperature, -40, "fahrenheit", -40},
+		{DimTemperature, 300, "K", 26.85},
+		{DimTemperature, 50, "ºF", 10},
+		{DimSpeed, 10, "m/s", 36},
+		{DimSpeed, 10, "mph", 16.09344},
+		{DimSpeed, 10, "kn", 18.52},
+		{DimPressure, 29.92, "inHg", 1013.207},
+		{DimPressure, 101325, "Pa", 1013.25},
+		{DimPressure, 760, "mmHg", 1013.25},
+		{DimLength, 10, "mi", 16093.44},
+		{DimLength, 1000, "ft", 304.8},
+		{DimPrecipitation, 1, "inch", 25.4},
+	}
+	for _, tt := range tests {
+		got, err := ToBase(tt.dimension, tt.value, tt.unit)
+		if err != nil || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("ToBase(%s, %v, %q) = %v, %v; want %v", tt.dimension, tt.value, tt.unit, got, err, tt.want)
+		}
+		back, err := FromBase(tt.dimension, got, tt.unit)
+		if err != nil || math.Abs(back-tt.value) > 1e-9 {
+			t.Errorf("FromBase(%s, %v, %q) = %v, %v; want %v", tt.dimension, got, tt.unit, back, err, tt.value)
+		}
+	}
+}
+
+func TestToBase_UnknownUnit(t *testing.T) {
+	for _, tt := range []struct{ dimension, unit string }{
+		{DimTemperature, "rankine"},
+		{DimSpeed, "hPa"},
+		{DimPressure, "°C"},
+	} {
+		if _, err := ToBase(tt.dimension, 1, tt.unit); !errors.Is(err, ErrUnknownUnit) {
+			t.Errorf("ToBase(%s, %q) error = %v, want ErrUnknownUnit", tt.dimension, tt.unit, err)
+		}
+	}
+}
+
+func TestQuantities(t *testing.T) {
+	temp, _ := NewTemperature(77, "°F")
+	speed, _ := NewSpeed(5, "m/s")
+	pressure, _ := NewPressure(1013.25, "hPa")
+	length, _ := NewLength(1, "mi")
+	rain, _ := NewPrecipitation(0.5, "in")
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"celsius", temp.Celsius(), 25},
+		{"kelvin", temp.Kelvin(), 298.15},
+		{"fahrenheit", temp.Fahrenheit(), 77},
+		{"km/h", speed.KilometersPerHour(), 18},
+		{"knots", speed.Knots(), 9.719},
+		{"inHg", pressure.InchesOfMercury(), 29.921},
+		{"km", length.Kilometers(), 1.609},
+		{"mm", rain.Millimeters(), 12.7},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 0.001 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestIsBase(t *testing.T) {
+	if !IsBase(DimTemperature, "celsius") || !IsBase(DimPressure, "mbar") {
+		t.Error("IsBase() = false for base unit aliases")
+	}
+	if IsBase(DimSpeed, "m/s") {
+		t.Error("IsBase(m/s) = true, want false")
+	}
+}
+
+func TestNewConcentration(t *testing.T) {
+	tests := []struct {
+		value     float64
+		unit      string
+		molarMass float64
+		want      float64
+	}{
+		{12.5, "μg/m³", 0, 12.5},
+		{12.5, "ug/m3", 0, 12.5},
+		{0.4, "mg/m³", 0, 400},
+		{100, "ppb", 46.0055, 188.16},
+		{1, "ppm", 28.010, 1145.6},
+	}
+	for _, tt := range tests {
+		got, err := NewConcentration(tt.value, tt.unit, tt.molarMass)
+		if err != nil || math.Abs(got.MicrogramsPerCubicMeter()-tt.want) > 0.1 {
+			t.Errorf("NewConcentration(%v, %q) = %v, %v; want %v", tt.value, tt.unit, got, err, tt.want)
+		}
+	}
+
+	if _, err := NewConcentration(10, "ppb", 0); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("NewConcentration(ppb without molar mass) error = %v, want ErrUnknownUnit", err)
+	}
+	no2, _ := NewConcentration(100, "ppb", 46.0055)
+	if math.Abs(no2.PPB(46.0055)-100) > 1e-9 || math.Abs(no2.PPM(46.0055)-0.1) > 1e-9 {
+		t.Errorf("PPB() = %v, PPM() = %v; want 100 and 0.1", no2.PPB(46.0055), no2.PPM(46.0055))
+	}
+}
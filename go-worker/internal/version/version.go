@@ -0,0 +1,63 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Informações de build injetadas via ldflags:
+//
+//	go build -ldflags "-X go-worker/internal/version.Version=1.2.0 \
+//	  -X go-worker/internal/version.Commit=abc123 \
+//	  -X go-worker/internal/version.BuildDate=2025-06-15T14:30:00Z" ./cmd/worker
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info agrupa as informações de build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+// Get retorna as informações de build, completando o commit e a data com os
+// dados de VCS embutidos pelo toolchain quando não foram injetados
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// String formata as informações de build em uma linha
+func (i Info) String() string {
+	commit := i.Commit
+	if commit == "" {
+		commit = "unknown"
+	} else if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	return fmt.Sprintf("go-worker %s (commit %s, built %s, %s %s)",
+		i.Version, commit, i.BuildDate, i.GoVersion, i.Platform)
+}
@@ -6,7 +6,7 @@ import json
 import logging
 import os
 import time
-from datetime import datetime
+from datetime import datetime, timezone
 from typing import Dict, Any
 
 import httpx
@@ -24,6 +24,22 @@ logging.basicConfig(
 logger = logging.getLogger(__name__)
 
 
+# Versão do schema da mensagem; o worker converte as versões anteriores
+SCHEMA_VERSION = 2
+
+# Campos de current com unidade, no nome usado pela mensagem -> nome da Open-Meteo
+UNIT_FIELDS = {
+    'temperature': 'temperature_2m',
+    'dew_point': 'dew_point_2m',
+    'wind_speed': 'wind_speed_10m',
+    'wind_gusts': 'wind_gusts_10m',
+    'precipitation': 'precipitation',
+    'surface_pressure': 'surface_pressure',
+    'pressure_msl': 'pressure_msl',
+    'visibility': 'visibility',
+}
+
+
 class WeatherCollector:
     """Collects weather data from external API"""
 
@@ -41,7 +57,8 @@ class WeatherCollector:
                 params = {
                     'latitude': self.latitude,
                     'longitude': self.longitude,
-                    'current': 'temperature_2m,relative_humidity_2m,precipitation,weather_code,wind_speed_10m',
+                    'current': 'temperature_2m,relative_humidity_2m,dew_point_2m,precipitation,weather_code,wind_speed_10m,'
+                               'wind_direction_10m,wind_gusts_10m,surface_pressure,pressure_msl,cloud_cover,visibility,uv_index',
                     'timezone': 'auto'
                 }
 
@@ -52,11 +69,14 @@ class WeatherCollector:
 
                 # Normalize data
                 weather_data = {
-                    'timestamp': datetime.utcnow().isoformat(),
+                    'schema_version': SCHEMA_VERSION,
+                    'timestamp': datetime.now(timezone.utc).isoformat(),
+                    'source': 'open-meteo',
                     'location': {
                         'latitude': self.latitude,
                         'longitude': self.longitude,
-                        'timezone': data.get('timezone', 'UTC')
+                        'timezone': data.get('timezone', 'UTC'),
+                        'elevation': data.get('elevation')
                     },
                     'current': {
                         'temperature': data['current']['temperature_2m'],
@@ -64,7 +84,22 @@ class WeatherCollector:
                         'precipitation': data['current']['precipitation'],
                         'wind_speed': data['current']['wind_speed_10m'],
                         'weather_code': data['current']['weather_code'],
-                        'time': data['current']['time']
+                        'time': data['current']['time'],
+                        # Opcionais: ausentes (None) quando a API não informa
+                        'surface_pressure': data['current'].get('surface_pressure'),
+                        'pressure_msl': data['current'].get('pressure_msl'),
+                        'visibility': data['current'].get('visibility'),
+                        'uv_index': data['current'].get('uv_index'),
+                        'wind_direction': data['current'].get('wind_direction_10m'),
+                        'wind_gusts': data['current'].get('wind_gusts_10m'),
+                        'cloud_cover': data['current'].get('cloud_cover'),
+                        'dew_point': data['current'].get('dew_point_2m')
+                    },
+                    # Unidades informadas pela API; o worker converte para o padrão métrico
+                    'units': {
+                        field: data.get('current_units', {}).get(api_field)
+                        for field, api_field in UNIT_FIELDS.items()
+                        if data.get('current_units', {}).get(api_field)
                     }
                 }
 